@@ -14,6 +14,7 @@ import (
 	"html/template"
 
 	owm "github.com/briandowns/openweathermap"
+	"github.com/briandowns/openweathermap/icons"
 	//	"io/ioutil"
 
 	"net/http"
@@ -87,9 +88,6 @@ func hereHandler(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprint(w, http.StatusInternalServerError)
 		return
 	}
-	// We're doin' naughty things below... Ignoring icon file size and possible errors.
-	_, _ = owm.RetrieveIcon("static/img", wd.Weather[0].Icon+".png")
-
 	// Write out the template with the given data
 	t.Execute(w, wd)
 }
@@ -97,9 +95,7 @@ func hereHandler(w http.ResponseWriter, r *http.Request) {
 // Run the app
 func main() {
 	http.HandleFunc("/here", hereHandler)
-	// Make sure we can serve our icon files once retrieved
-	http.HandleFunc("/static/", func(w http.ResponseWriter, r *http.Request) {
-		http.ServeFile(w, r, r.URL.Path[1:])
-	})
+	// Serve the embedded icons so nothing is fetched from openweathermap.org
+	http.Handle("/static/img/", http.StripPrefix("/static/img/", icons.Handler()))
 	http.ListenAndServe(":8888", nil)
 }
@@ -0,0 +1,369 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	errNoLocations     = errors.New("at least one location is needed")
+	errInvalidInterval = errors.New("interval should be positive")
+)
+
+// AlertKey identifies an alert across polls.
+type AlertKey struct {
+	Sender string
+	Event  string
+	Start  int
+}
+
+// Key returns the key identifying the alert.
+func (a OneCallAlertData) Key() AlertKey {
+	return AlertKey{Sender: a.SenderName, Event: a.Event, Start: a.Start}
+}
+
+// sameAs reports whether nothing but the key would be reported as
+// changed.
+func (a OneCallAlertData) sameAs(b OneCallAlertData) bool {
+	return a.End == b.End && a.Description == b.Description && strings.Join(a.Tags, ",") == strings.Join(b.Tags, ",")
+}
+
+// AlertEventType is the kind of change of an alert.
+type AlertEventType int
+
+// Alert event types.
+const (
+	AlertNew AlertEventType = iota + 1
+	AlertUpdated
+	AlertExpired
+)
+
+// String satisfies the fmt.Stringer interface.
+func (t AlertEventType) String() string {
+	switch t {
+	case AlertNew:
+		return "new"
+	case AlertUpdated:
+		return "updated"
+	case AlertExpired:
+		return "expired"
+	}
+	return "unknown"
+}
+
+// AlertEvent is a change of an alert at a location.
+type AlertEvent struct {
+	Type     AlertEventType
+	Location Coordinates
+	Alert    OneCallAlertData
+	Previous *OneCallAlertData // alert before an update
+}
+
+// AlertStore persists the alerts seen at each location so a restarted
+// watcher doesn't notify them again.
+type AlertStore interface {
+	Load(location Coordinates) ([]OneCallAlertData, error)
+	Save(location Coordinates, alerts []OneCallAlertData) error
+}
+
+// locationKey returns the key of the location in the stores.
+func locationKey(location Coordinates) string {
+	return fmt.Sprintf("%.4f,%.4f", location.Latitude, location.Longitude)
+}
+
+// MemoryAlertStore keeps the alerts in memory.
+type MemoryAlertStore struct {
+	mu     sync.Mutex
+	alerts map[string][]OneCallAlertData
+}
+
+// NewMemoryAlertStore returns a new MemoryAlertStore pointer.
+func NewMemoryAlertStore() *MemoryAlertStore {
+	return &MemoryAlertStore{alerts: make(map[string][]OneCallAlertData)}
+}
+
+// Load returns the alerts seen at the location.
+func (s *MemoryAlertStore) Load(location Coordinates) ([]OneCallAlertData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.alerts[locationKey(location)], nil
+}
+
+// Save stores the alerts seen at the location.
+func (s *MemoryAlertStore) Save(location Coordinates, alerts []OneCallAlertData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.alerts[locationKey(location)] = alerts
+	return nil
+}
+
+// FileAlertStore keeps the alerts of every location in a JSON file.
+type FileAlertStore struct {
+	Path string
+	mu   sync.Mutex
+}
+
+// NewFileAlertStore returns a new FileAlertStore pointer storing the
+// alerts in the given file, creating its directory when needed.
+func NewFileAlertStore(path string) (*FileAlertStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	return &FileAlertStore{Path: path}, nil
+}
+
+// read returns the content of the file, which is empty when it doesn't
+// exist yet.
+func (s *FileAlertStore) read() (map[string][]OneCallAlertData, error) {
+	all := make(map[string][]OneCallAlertData)
+	b, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return all, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &all); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// Load returns the alerts seen at the location.
+func (s *FileAlertStore) Load(location Coordinates) ([]OneCallAlertData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	return all[locationKey(location)], nil
+}
+
+// Save stores the alerts seen at the location. The file is replaced
+// atomically.
+func (s *FileAlertStore) Save(location Coordinates, alerts []OneCallAlertData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all, err := s.read()
+	if err != nil {
+		return err
+	}
+	all[locationKey(location)] = alerts
+
+	b, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.Path, b)
+}
+
+// AlertWatcher polls the alerts of a set of locations and reports the
+// ones that are new, updated or expired.
+type AlertWatcher struct {
+	Locations []Coordinates
+	Interval  time.Duration
+	Store     AlertStore
+	Key       string
+	Lang      string
+
+	// OnError is called with the errors of the polls made by Run and
+	// Watch. They are dropped when nil.
+	OnError func(error)
+
+	now func() time.Time
+	*Settings
+}
+
+// NewAlertWatcher returns a new AlertWatcher pointer polling the given
+// locations on the interval. The alerts are kept in memory when store is
+// nil.
+func NewAlertWatcher(key, lang string, locations []Coordinates, interval time.Duration, store AlertStore, options ...Option) (*AlertWatcher, error) {
+	if len(locations) == 0 {
+		return nil, errNoLocations
+	}
+	if interval <= 0 {
+		return nil, errInvalidInterval
+	}
+
+	langChoice := strings.ToUpper(lang)
+	if !ValidLangCode(langChoice) {
+		return nil, errLangUnavailable
+	}
+
+	k, err := setKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if store == nil {
+		store = NewMemoryAlertStore()
+	}
+
+	w := &AlertWatcher{
+		Locations: locations,
+		Interval:  interval,
+		Store:     store,
+		Key:       k,
+		Lang:      langChoice,
+		now:       time.Now,
+		Settings:  NewSettings(),
+	}
+	if err := setOptions(w.Settings, options); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// fetch returns the current alerts of the location. Everything else the
+// one call API provides is excluded.
+func (w *AlertWatcher) fetch(location Coordinates) ([]OneCallAlertData, error) {
+	c := &OneCallData{
+		Unit:     DataUnits["C"],
+		Lang:     w.Lang,
+		Key:      w.Key,
+		Excludes: "current,minutely,hourly,daily",
+		Settings: w.Settings,
+	}
+	if err := c.OneCallByCoordinates(&location); err != nil {
+		return nil, err
+	}
+	return c.Alerts, nil
+}
+
+// diffAlerts compares the alerts seen before with the current ones and
+// returns the events along with the alerts still active.
+func diffAlerts(location Coordinates, seen, current []OneCallAlertData, now time.Time) ([]AlertEvent, []OneCallAlertData) {
+	previous := make(map[AlertKey]OneCallAlertData, len(seen))
+	for _, a := range seen {
+		previous[a.Key()] = a
+	}
+
+	var events []AlertEvent
+	var active []OneCallAlertData
+	kept := make(map[AlertKey]bool, len(current))
+	for _, a := range current {
+		key := a.Key()
+		if kept[key] {
+			continue
+		}
+		if a.End > 0 && int64(a.End) <= now.Unix() {
+			continue
+		}
+		kept[key] = true
+		active = append(active, a)
+
+		prev, ok := previous[key]
+		switch {
+		case !ok:
+			events = append(events, AlertEvent{Type: AlertNew, Location: location, Alert: a})
+		case !prev.sameAs(a):
+			p := prev
+			events = append(events, AlertEvent{Type: AlertUpdated, Location: location, Alert: a, Previous: &p})
+		}
+	}
+
+	for _, a := range seen {
+		if !kept[a.Key()] {
+			events = append(events, AlertEvent{Type: AlertExpired, Location: location, Alert: a})
+		}
+	}
+	return events, active
+}
+
+// Check polls every location once and returns the changes since the last
+// poll, as recorded in the store. The locations that fail are skipped and
+// the first error is returned along with the events of the others.
+func (w *AlertWatcher) Check() ([]AlertEvent, error) {
+	var events []AlertEvent
+	var firstErr error
+	setErr := func(location Coordinates, err error) {
+		if firstErr == nil {
+			firstErr = fmt.Errorf("alerts for %s: %w", locationKey(location), err)
+		}
+	}
+
+	for _, location := range w.Locations {
+		seen, err := w.Store.Load(location)
+		if err != nil {
+			setErr(location, err)
+			continue
+		}
+		current, err := w.fetch(location)
+		if err != nil {
+			setErr(location, err)
+			continue
+		}
+
+		e, active := diffAlerts(location, seen, current, w.now())
+		if err := w.Store.Save(location, active); err != nil {
+			setErr(location, err)
+			continue
+		}
+		events = append(events, e...)
+	}
+	return events, firstErr
+}
+
+// Run polls the locations right away and then on every interval, calling
+// fn with each event, until the context is done.
+func (w *AlertWatcher) Run(ctx context.Context, fn func(AlertEvent)) error {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		events, err := w.Check()
+		if err != nil && w.OnError != nil {
+			w.OnError(err)
+		}
+		for _, e := range events {
+			fn(e)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Watch runs the watcher in the background and returns a channel
+// receiving the events. The channel is closed once the context is done.
+func (w *AlertWatcher) Watch(ctx context.Context) <-chan AlertEvent {
+	ch := make(chan AlertEvent)
+	go func() {
+		defer close(ch)
+		w.Run(ctx, func(e AlertEvent) {
+			select {
+			case ch <- e:
+			case <-ctx.Done():
+			}
+		})
+	}()
+	return ch
+}
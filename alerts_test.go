@@ -0,0 +1,263 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// alertsServer serves the alerts set with set, or an error status when
+// it isn't zero.
+type alertsServer struct {
+	mu     sync.Mutex
+	alerts []OneCallAlertData
+	status int
+}
+
+func (s *alertsServer) set(status int, alerts ...OneCallAlertData) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+	s.alerts = alerts
+}
+
+func (s *alertsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.status != 0 {
+		w.WriteHeader(s.status)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"alerts": s.alerts})
+}
+
+var (
+	stormAlert = OneCallAlertData{SenderName: "DWD", Event: "Storm", Start: 1000, End: 5000, Description: "Gusts up to 90 km/h"}
+	floodAlert = OneCallAlertData{SenderName: "DWD", Event: "Flood", Start: 2000, End: 9000}
+)
+
+func eventTypes(events []AlertEvent) []AlertEventType {
+	types := make([]AlertEventType, len(events))
+	for i, e := range events {
+		types[i] = e.Type
+	}
+	return types
+}
+
+func sameTypes(a, b []AlertEventType) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// TestAlertWatcherCheck will verify that new, updated and expired alerts
+// are reported once
+func TestAlertWatcherCheck(t *testing.T) {
+	t.Parallel()
+
+	s := &alertsServer{}
+	srv, opt := newTestServer(t, s)
+	defer srv.Close()
+
+	w, err := NewAlertWatcher("key", "EN", []Coordinates{{Latitude: 52.52, Longitude: 13.40}}, time.Minute, nil, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.now = func() time.Time { return time.Unix(3000, 0) }
+
+	updated := stormAlert
+	updated.End = 6000
+
+	steps := []struct {
+		name   string
+		alerts []OneCallAlertData
+		want   []AlertEventType
+	}{
+		{"new", []OneCallAlertData{stormAlert, stormAlert}, []AlertEventType{AlertNew}},
+		{"unchanged", []OneCallAlertData{stormAlert}, []AlertEventType{}},
+		{"updated and new", []OneCallAlertData{updated, floodAlert}, []AlertEventType{AlertUpdated, AlertNew}},
+		{"expired", []OneCallAlertData{floodAlert}, []AlertEventType{AlertExpired}},
+	}
+	for _, step := range steps {
+		s.set(0, step.alerts...)
+		events, err := w.Check()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := eventTypes(events); !sameTypes(got, step.want) {
+			t.Errorf("%s: expected %v, got %v", step.name, step.want, got)
+		}
+	}
+
+	// alerts past their end are expired even when still returned
+	w.now = func() time.Time { return time.Unix(9000, 0) }
+	events, err := w.Check()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Type != AlertExpired || events[0].Alert.Event != "Flood" {
+		t.Errorf("expected the flood to expire, got %+v", events)
+	}
+}
+
+// TestAlertWatcherUpdate will verify that updates carry the previous alert
+func TestAlertWatcherUpdate(t *testing.T) {
+	t.Parallel()
+
+	location := Coordinates{Latitude: 1, Longitude: 2}
+	updated := stormAlert
+	updated.Description = "Gusts up to 110 km/h"
+
+	events, active := diffAlerts(location, []OneCallAlertData{stormAlert}, []OneCallAlertData{updated}, time.Unix(0, 0))
+	if len(events) != 1 || events[0].Type != AlertUpdated || events[0].Previous == nil || events[0].Previous.Description != stormAlert.Description {
+		t.Errorf("unexpected events %+v", events)
+	}
+	if events[0].Location != location || len(active) != 1 || active[0].Description != updated.Description {
+		t.Errorf("unexpected active alerts %+v", active)
+	}
+}
+
+// TestAlertWatcherError will verify that a failed poll doesn't expire the
+// alerts
+func TestAlertWatcherError(t *testing.T) {
+	t.Parallel()
+
+	s := &alertsServer{}
+	srv, opt := newTestServer(t, s)
+	defer srv.Close()
+
+	w, err := NewAlertWatcher("key", "EN", []Coordinates{{}}, time.Minute, nil, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.now = func() time.Time { return time.Unix(3000, 0) }
+
+	s.set(0, stormAlert)
+	if _, err := w.Check(); err != nil {
+		t.Fatal(err)
+	}
+
+	s.set(http.StatusInternalServerError)
+	events, err := w.Check()
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || len(events) != 0 {
+		t.Errorf("expected an API error and no events, got %v and %+v", err, events)
+	}
+
+	s.set(0, stormAlert)
+	if events, err := w.Check(); err != nil || len(events) != 0 {
+		t.Errorf("expected no events after the error, got %v and %+v", err, events)
+	}
+}
+
+// TestFileAlertStore will verify that a restarted watcher doesn't notify
+// the alerts seen before
+func TestFileAlertStore(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "alerts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := &alertsServer{}
+	srv, opt := newTestServer(t, s)
+	defer srv.Close()
+	s.set(0, stormAlert)
+
+	path := filepath.Join(dir, "state", "alerts.json")
+	for i, want := range []int{1, 0} {
+		store, err := NewFileAlertStore(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w, err := NewAlertWatcher("key", "EN", []Coordinates{{Latitude: 1}, {Latitude: 2}}, time.Minute, store, opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.now = func() time.Time { return time.Unix(3000, 0) }
+
+		events, err := w.Check()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(events) != 2*want {
+			t.Errorf("run %d: expected %d events, got %+v", i, 2*want, events)
+		}
+	}
+}
+
+// TestAlertWatcherWatch will verify that events are sent on the channel
+// until the context is done
+func TestAlertWatcherWatch(t *testing.T) {
+	t.Parallel()
+
+	s := &alertsServer{}
+	srv, opt := newTestServer(t, s)
+	defer srv.Close()
+	s.set(0, stormAlert)
+
+	w, err := NewAlertWatcher("key", "EN", []Coordinates{{}}, 10*time.Millisecond, nil, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.now = func() time.Time { return time.Unix(3000, 0) }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := w.Watch(ctx)
+
+	if e := <-ch; e.Type != AlertNew {
+		t.Errorf("expected a new alert, got %+v", e)
+	}
+	s.set(0)
+	if e := <-ch; e.Type != AlertExpired {
+		t.Errorf("expected an expired alert, got %+v", e)
+	}
+
+	cancel()
+	for range ch {
+	}
+}
+
+// TestNewAlertWatcher will verify that invalid settings are rejected
+func TestNewAlertWatcher(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewAlertWatcher("key", "EN", nil, time.Minute, nil); err != errNoLocations {
+		t.Errorf("expected %v, got %v", errNoLocations, err)
+	}
+	if _, err := NewAlertWatcher("key", "EN", []Coordinates{{}}, 0, nil); err != errInvalidInterval {
+		t.Errorf("expected %v, got %v", errInvalidInterval, err)
+	}
+	if _, err := NewAlertWatcher("key", "XX", []Coordinates{{}}, time.Minute, nil); err != errLangUnavailable {
+		t.Errorf("expected %v, got %v", errLangUnavailable, err)
+	}
+}
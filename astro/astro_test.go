@@ -0,0 +1,246 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package astro
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"testing"
+	"time"
+)
+
+func within(a, b time.Time, d time.Duration) bool {
+	diff := a.Sub(b)
+	return diff <= d && diff >= -d
+}
+
+func date(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// TestSolarEquations will verify the declination and the equation of
+// time against the NOAA values at the solstice and at the extremes of the
+// equation of time
+func TestSolarEquations(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		at          string
+		declination float64
+		eqOfTime    float64
+	}{
+		{"2023-06-21T12:00:00Z", 23.44, -1.76},
+		{"2023-02-11T12:00:00Z", -14.02, -14.23},
+		{"2023-11-03T12:00:00Z", -15.07, 16.49},
+	}
+	for _, tt := range tests {
+		s := solarAt(date(tt.at))
+		if math.Abs(s.declination-tt.declination) > 0.01 || math.Abs(s.eqOfTime-tt.eqOfTime) > 0.01 {
+			t.Errorf("%s: expected %v° and %v min, got %+v", tt.at, tt.declination, tt.eqOfTime, s)
+		}
+	}
+}
+
+// TestSunTimes will verify the times of the sun at the equator on the
+// equinox and at Greenwich in winter
+func TestSunTimes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		day      string
+		lat, lon float64
+		noon     string
+		rise     string
+		set      string
+		civil    string
+		length   time.Duration
+	}{
+		{"equator", "2024-03-20T00:00:00Z", 0, 0, "2024-03-20T12:07:17Z", "2024-03-20T06:04:02Z", "2024-03-20T18:10:33Z", "2024-03-20T05:43:22Z", 12*time.Hour + 6*time.Minute + 31*time.Second},
+		{"greenwich", "2000-01-01T00:00:00Z", 51.4769, -0.0005, "2000-01-01T12:03:18Z", "2000-01-01T08:05:37Z", "2000-01-01T16:01:10Z", "2000-01-01T07:25:40Z", 7*time.Hour + 55*time.Minute + 33*time.Second},
+	}
+	for _, tt := range tests {
+		s := SunTimes(date(tt.day), tt.lat, tt.lon)
+		if !within(s.SolarNoon, date(tt.noon), time.Second) {
+			t.Errorf("%s: expected noon at %s, got %v", tt.name, tt.noon, s.SolarNoon)
+		}
+		if !within(s.Sunrise.Rise, date(tt.rise), time.Second) || !within(s.Sunrise.Set, date(tt.set), time.Second) {
+			t.Errorf("%s: expected sun from %s to %s, got %+v", tt.name, tt.rise, tt.set, s.Sunrise)
+		}
+		if !within(s.Civil.Rise, date(tt.civil), time.Second) {
+			t.Errorf("%s: expected civil dawn at %s, got %v", tt.name, tt.civil, s.Civil.Rise)
+		}
+		if d := s.DayLength() - tt.length; d > time.Second || d < -time.Second {
+			t.Errorf("%s: expected day length %v, got %v", tt.name, tt.length, s.DayLength())
+		}
+		if !(s.Astronomical.Rise.Before(s.Nautical.Rise) && s.Nautical.Rise.Before(s.Civil.Rise) && s.Civil.Rise.Before(s.Sunrise.Rise)) {
+			t.Errorf("%s: expected dawns in order, got %+v", tt.name, s)
+		}
+	}
+}
+
+// TestSunTimesLocation will verify that times are computed for the
+// calendar day of the date's location
+func TestSunTimesLocation(t *testing.T) {
+	t.Parallel()
+
+	auckland := time.FixedZone("NZST", 12*60*60)
+	s := SunTimes(time.Date(2022, 6, 21, 23, 0, 0, 0, auckland), -36.85, 174.76)
+	if y, m, d := s.Sunrise.Rise.Date(); y != 2022 || m != 6 || d != 21 || s.Sunrise.Rise.Location() != auckland {
+		t.Errorf("expected sunrise on the 21st in Auckland, got %v", s.Sunrise.Rise)
+	}
+	if h := s.Sunrise.Rise.Hour(); h != 7 {
+		t.Errorf("expected sunrise after 7am, got %v", s.Sunrise.Rise)
+	}
+
+	samoa := time.FixedZone("WST", 13*60*60)
+	s = SunTimes(time.Date(2022, 1, 10, 0, 0, 0, 0, samoa), -13.83, -171.76)
+	if _, _, d := s.SolarNoon.Date(); d != 10 || s.SolarNoon.Hour() != 12 {
+		t.Errorf("expected solar noon after noon on the 10th, got %v", s.SolarNoon)
+	}
+}
+
+// TestPolarSun will verify polar day and night
+func TestPolarSun(t *testing.T) {
+	t.Parallel()
+
+	const lat, lon = 78.22, 15.65 // Longyearbyen
+
+	summer := SunTimes(date("2022-06-21T00:00:00Z"), lat, lon)
+	if summer.Sunrise.Occurs() || !summer.Sunrise.Up || summer.DayLength() != 24*time.Hour {
+		t.Errorf("expected polar day, got %+v", summer.Sunrise)
+	}
+
+	winter := SunTimes(date("2022-12-21T00:00:00Z"), lat, lon)
+	if winter.Sunrise.Occurs() || winter.Sunrise.Up || winter.DayLength() != 0 {
+		t.Errorf("expected polar night, got %+v", winter.Sunrise)
+	}
+	if winter.Civil.Occurs() || !winter.Astronomical.Occurs() {
+		t.Errorf("expected astronomical twilight only, got %+v", winter)
+	}
+}
+
+// TestSunPosition will verify the elevation and azimuth of the sun
+func TestSunPosition(t *testing.T) {
+	t.Parallel()
+
+	noon := SunPosition(date("2024-03-20T12:07:17Z"), 0, 0)
+	if noon.Elevation < 89.5 {
+		t.Errorf("expected the sun overhead, got %+v", noon)
+	}
+
+	// at solar noon the elevation is 90° less the distance to the
+	// declination, and the sun is due south
+	s := SunTimes(date("2023-06-21T00:00:00Z"), 40, -105)
+	p := SunPosition(s.SolarNoon, 40, -105)
+	if math.Abs(p.Elevation-(90-(40-23.44))) > 0.05 || math.Abs(p.Azimuth-180) > 0.5 {
+		t.Errorf("unexpected noon position %+v", p)
+	}
+
+	morning := SunPosition(date("2023-06-21T15:00:00Z"), 40, -105)
+	if morning.Azimuth < 60 || morning.Azimuth > 120 || morning.Elevation < 20 || morning.Elevation > 50 {
+		t.Errorf("expected the sun in the east in the morning, got %+v", morning)
+	}
+
+	// at sunrise the center of the sun is 0.833° below the horizon, which
+	// refraction partly lifts
+	rise := SunPosition(s.Sunrise.Rise, 40, -105)
+	if rise.Elevation < -0.833 || rise.Elevation > 0 {
+		t.Errorf("expected the sun just below the horizon, got %+v", rise)
+	}
+}
+
+// TestMoon will verify the phase of the moon at the principal phases
+// published by the USNO
+func TestMoon(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		at           string
+		phase        float64
+		illumination float64
+		name         string
+	}{
+		{"2023-05-19T15:53:00Z", 1, 0, NewMoon},
+		{"2023-05-27T15:22:00Z", 0.25, 0.5, FirstQuarter},
+		{"2023-06-04T03:42:00Z", 0.5, 1, FullMoon},
+		{"2023-06-10T19:31:00Z", 0.75, 0.5, LastQuarter},
+		{"2024-04-08T18:21:00Z", 0, 0, NewMoon},
+	}
+	for _, tt := range tests {
+		m := MoonAt(date(tt.at))
+		diff := math.Abs(m.Phase - tt.phase)
+		if diff > 0.5 {
+			diff = 1 - diff
+		}
+		if diff > 0.005 || math.Abs(m.Illumination-tt.illumination) > 0.01 || m.Name() != tt.name {
+			t.Errorf("%s: expected %v (%s), got %+v (%s)", tt.at, tt.phase, tt.name, m, m.Name())
+		}
+	}
+
+	if n := PhaseName(0.6); n != WaningGibbous {
+		t.Errorf("expected %s, got %s", WaningGibbous, n)
+	}
+	if n := PhaseName(0.1); n != WaxingCrescent {
+		t.Errorf("expected %s, got %s", WaxingCrescent, n)
+	}
+}
+
+// TestAPIFixtures will cross-check the computed values with the ones
+// returned by the API
+func TestAPIFixtures(t *testing.T) {
+	t.Parallel()
+
+	b, err := ioutil.ReadFile("testdata/api.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fixtures []struct {
+		Source         string   `json:"source"`
+		Lat            float64  `json:"lat"`
+		Lon            float64  `json:"lon"`
+		TimezoneOffset int      `json:"timezone_offset"`
+		Dt             int64    `json:"dt"`
+		Sunrise        int64    `json:"sunrise"`
+		Sunset         int64    `json:"sunset"`
+		MoonPhase      *float64 `json:"moon_phase"`
+	}
+	if err := json.Unmarshal(b, &fixtures); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range fixtures {
+		day := time.Unix(f.Dt, 0).In(time.FixedZone("", f.TimezoneOffset))
+		s := SunTimes(day, f.Lat, f.Lon)
+
+		// the API's times differ from NOAA's by up to a couple of minutes
+		if !within(s.Sunrise.Rise, time.Unix(f.Sunrise, 0), 2*time.Minute) {
+			t.Errorf("%s: expected sunrise at %v, got %v", f.Source, time.Unix(f.Sunrise, 0).UTC(), s.Sunrise.Rise.UTC())
+		}
+		if !within(s.Sunrise.Set, time.Unix(f.Sunset, 0), 2*time.Minute) {
+			t.Errorf("%s: expected sunset at %v, got %v", f.Source, time.Unix(f.Sunset, 0).UTC(), s.Sunrise.Set.UTC())
+		}
+		if f.MoonPhase != nil {
+			if m := MoonAt(day); math.Abs(m.Phase-*f.MoonPhase) > 0.01 {
+				t.Errorf("%s: expected moon phase %v, got %v", f.Source, *f.MoonPhase, m.Phase)
+			}
+		}
+	}
+}
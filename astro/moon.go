@@ -0,0 +1,90 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package astro
+
+import (
+	"math"
+	"time"
+)
+
+// Moon holds the phase of the moon at a given time.
+type Moon struct {
+	// Phase goes from 0 at new moon through 0.25 at first quarter, 0.5
+	// at full moon and 0.75 at last quarter back to 1, like the
+	// moon_phase of the API's daily forecast.
+	Phase float64
+
+	// Illumination is the illuminated fraction of the disk, from 0 to 1.
+	Illumination float64
+}
+
+// MoonAt returns the phase of the moon at the given time, computed from
+// the low precision terms of Meeus' Astronomical Algorithms chapter 48.
+// The phase doesn't depend on the place.
+func MoonAt(t time.Time) Moon {
+	jc := julianCentury(t)
+
+	d := 297.8501921 + 445267.1114034*jc  // mean elongation of the moon
+	m := 357.5291092 + 35999.0502909*jc   // mean anomaly of the sun
+	mp := 134.9633964 + 477198.8675055*jc // mean anomaly of the moon
+
+	// phase angle
+	i := 180 - d -
+		6.289*math.Sin(rad(mp)) +
+		2.100*math.Sin(rad(m)) -
+		1.274*math.Sin(rad(2*d-mp)) -
+		0.658*math.Sin(rad(2*d)) -
+		0.214*math.Sin(rad(2*mp)) -
+		0.110*math.Sin(rad(d))
+
+	elongation := math.Mod(180-i, 360)
+	if elongation < 0 {
+		elongation += 360
+	}
+	return Moon{
+		Phase:        elongation / 360,
+		Illumination: (1 + math.Cos(rad(i))) / 2,
+	}
+}
+
+// Phase names.
+const (
+	NewMoon        = "New Moon"
+	WaxingCrescent = "Waxing Crescent"
+	FirstQuarter   = "First Quarter"
+	WaxingGibbous  = "Waxing Gibbous"
+	FullMoon       = "Full Moon"
+	WaningGibbous  = "Waning Gibbous"
+	LastQuarter    = "Last Quarter"
+	WaningCrescent = "Waning Crescent"
+)
+
+// PhaseName returns the name of the phase, e.g. "Waxing Gibbous". The
+// principal phases are given about a day on each side.
+func PhaseName(phase float64) string {
+	const window = 1 / 29.530588853 // a day of the synodic month
+	names := []string{NewMoon, WaxingCrescent, FirstQuarter, WaxingGibbous, FullMoon, WaningGibbous, LastQuarter, WaningCrescent}
+
+	phase = phase - math.Floor(phase)
+	for i, p := range []float64{0, 0.25, 0.5, 0.75, 1} {
+		if math.Abs(phase-p) <= window {
+			return names[(2*i)%len(names)]
+		}
+	}
+	return names[int(phase*4)*2+1]
+}
+
+// Name returns the name of the phase.
+func (m Moon) Name() string { return PhaseName(m.Phase) }
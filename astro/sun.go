@@ -0,0 +1,248 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package astro computes the position of the sun, its rise and set times
+// with twilight, and the phase of the moon for any place and date. It
+// doesn't call the API so it works offline. The sun follows the NOAA
+// solar calculator, which is accurate to about a minute between
+// latitudes ±72°.
+package astro
+
+import (
+	"math"
+	"time"
+)
+
+// Zenith angles of the sun, in degrees, at each event.
+const (
+	zenithSunrise      = 90.833 // upper limb touching the horizon, with refraction
+	zenithCivil        = 96
+	zenithNautical     = 102
+	zenithAstronomical = 108
+)
+
+func rad(deg float64) float64 { return deg * math.Pi / 180 }
+func deg(rad float64) float64 { return rad * 180 / math.Pi }
+
+// julianCentury returns the Julian centuries since J2000.0 of t.
+func julianCentury(t time.Time) float64 {
+	jd := float64(t.UnixNano())/float64(24*time.Hour) + 2440587.5
+	return (jd - 2451545) / 36525
+}
+
+// solar holds the values of the NOAA equations that depend on the time
+// only.
+type solar struct {
+	declination float64 // degrees
+	eqOfTime    float64 // minutes
+}
+
+// solarAt computes the declination of the sun and the equation of time.
+func solarAt(t time.Time) solar {
+	jc := julianCentury(t)
+
+	l0 := math.Mod(280.46646+jc*(36000.76983+jc*0.0003032), 360)
+	m := 357.52911 + jc*(35999.05029-0.0001537*jc)
+	e := 0.016708634 - jc*(0.000042037+0.0000001267*jc)
+
+	c := math.Sin(rad(m))*(1.914602-jc*(0.004817+0.000014*jc)) +
+		math.Sin(rad(2*m))*(0.019993-0.000101*jc) +
+		math.Sin(rad(3*m))*0.000289
+	omega := 125.04 - 1934.136*jc
+	lambda := l0 + c - 0.00569 - 0.00478*math.Sin(rad(omega))
+
+	obliq := 23 + (26+(21.448-jc*(46.815+jc*(0.00059-jc*0.001813)))/60)/60
+	obliq += 0.00256 * math.Cos(rad(omega))
+
+	y := math.Pow(math.Tan(rad(obliq/2)), 2)
+	eq := y*math.Sin(2*rad(l0)) -
+		2*e*math.Sin(rad(m)) +
+		4*e*y*math.Sin(rad(m))*math.Cos(2*rad(l0)) -
+		0.5*y*y*math.Sin(4*rad(l0)) -
+		1.25*e*e*math.Sin(2*rad(m))
+
+	return solar{
+		declination: deg(math.Asin(math.Sin(rad(obliq)) * math.Sin(rad(lambda)))),
+		eqOfTime:    4 * deg(eq),
+	}
+}
+
+// Position is the position of the sun in the sky.
+type Position struct {
+	Elevation float64 // degrees above the horizon, corrected for refraction
+	Azimuth   float64 // degrees clockwise from north
+}
+
+// SunPosition returns the position of the sun at the given time and
+// place.
+func SunPosition(t time.Time, lat, lon float64) Position {
+	t = t.UTC()
+	s := solarAt(t)
+
+	minutes := float64(t.Hour()*60+t.Minute()) + float64(t.Second())/60 + float64(t.Nanosecond())/6e10
+	trueSolar := math.Mod(minutes+s.eqOfTime+4*lon, 1440)
+	hourAngle := trueSolar/4 - 180
+	if hourAngle < -180 {
+		hourAngle += 360
+	}
+
+	cosZenith := math.Sin(rad(lat))*math.Sin(rad(s.declination)) +
+		math.Cos(rad(lat))*math.Cos(rad(s.declination))*math.Cos(rad(hourAngle))
+	zenith := deg(math.Acos(clamp(cosZenith)))
+
+	var azimuth float64
+	if d := math.Cos(rad(lat)) * math.Sin(rad(zenith)); d != 0 {
+		a := deg(math.Acos(clamp((math.Sin(rad(lat))*math.Cos(rad(zenith)) - math.Sin(rad(s.declination))) / d)))
+		if hourAngle > 0 {
+			azimuth = math.Mod(a+180, 360)
+		} else {
+			azimuth = math.Mod(540-a, 360)
+		}
+	}
+
+	elevation := 90 - zenith
+	return Position{
+		Elevation: elevation + refraction(elevation),
+		Azimuth:   azimuth,
+	}
+}
+
+// refraction returns the NOAA approximation of the atmospheric
+// refraction, in degrees, at the given elevation.
+func refraction(elevation float64) float64 {
+	te := math.Tan(rad(elevation))
+	var r float64 // arc seconds
+	switch {
+	case elevation > 85:
+		return 0
+	case elevation > 5:
+		r = 58.1/te - 0.07/math.Pow(te, 3) + 0.000086/math.Pow(te, 5)
+	case elevation > -0.575:
+		r = 1735 + elevation*(-518.2+elevation*(103.4+elevation*(-12.79+elevation*0.711)))
+	default:
+		r = -20.772 / te
+	}
+	return r / 3600
+}
+
+// clamp keeps a cosine within [-1, 1] despite rounding errors.
+func clamp(v float64) float64 {
+	return math.Max(-1, math.Min(1, v))
+}
+
+// Event is a pair of morning and evening times at which the sun crosses
+// a given zenith angle. Rise and Set are zero when the sun doesn't cross
+// it that day, in which case Up tells whether it stays above.
+type Event struct {
+	Rise time.Time
+	Set  time.Time
+	Up   bool
+}
+
+// Occurs reports whether the sun crosses the zenith angle that day.
+func (e Event) Occurs() bool { return !e.Rise.IsZero() }
+
+// Sun holds the times of the sun for a day.
+type Sun struct {
+	SolarNoon    time.Time
+	Sunrise      Event // sunrise and sunset
+	Civil        Event // civil dawn and dusk
+	Nautical     Event // nautical dawn and dusk
+	Astronomical Event // astronomical dawn and dusk
+}
+
+// DayLength returns the time between sunrise and sunset, which is 24
+// hours during polar day and 0 during polar night.
+func (s Sun) DayLength() time.Duration {
+	if !s.Sunrise.Occurs() {
+		if s.Sunrise.Up {
+			return 24 * time.Hour
+		}
+		return 0
+	}
+	return s.Sunrise.Set.Sub(s.Sunrise.Rise)
+}
+
+// SunTimes returns the times of the sun at the given place for the
+// calendar day of date in its location. Times are in the same location.
+func SunTimes(date time.Time, lat, lon float64) Sun {
+	loc := date.Location()
+	y, m, d := date.Date()
+	midnight := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+
+	// mean solar noon nearest to the middle of the local day
+	_, offset := time.Date(y, m, d, 12, 0, 0, 0, loc).Zone()
+	mean := midnight.Add(minutesDuration(720 - 4*lon))
+	if diff := float64(offset)/3600 - lon/15; diff > 12 {
+		mean = mean.Add(-24 * time.Hour)
+	} else if diff < -12 {
+		mean = mean.Add(24 * time.Hour)
+	}
+
+	// corrected with the equation of time at noon
+	noon := mean
+	for i := 0; i < 2; i++ {
+		noon = mean.Add(minutesDuration(-solarAt(noon).eqOfTime))
+	}
+
+	return Sun{
+		SolarNoon:    noon.In(loc),
+		Sunrise:      event(noon, lat, lon, zenithSunrise, loc),
+		Civil:        event(noon, lat, lon, zenithCivil, loc),
+		Nautical:     event(noon, lat, lon, zenithNautical, loc),
+		Astronomical: event(noon, lat, lon, zenithAstronomical, loc),
+	}
+}
+
+// event computes the times the sun crosses the zenith angle before and
+// after solar noon.
+func event(noon time.Time, lat, lon, zenith float64, loc *time.Location) Event {
+	rise, up, ok := crossing(noon, lat, zenith, -1)
+	if !ok {
+		return Event{Up: up}
+	}
+	set, _, _ := crossing(noon, lat, zenith, 1)
+	return Event{Rise: rise.In(loc), Set: set.In(loc)}
+}
+
+// crossing returns the time the sun crosses the zenith angle on the
+// morning (sign -1) or evening (sign 1) side of noon. It's computed with
+// the sun at noon first, then again with the sun at the time found. The
+// bool is false when the sun doesn't cross it, up telling whether it's
+// above all day.
+func crossing(noon time.Time, lat, zenith, sign float64) (time.Time, bool, bool) {
+	t := noon
+	for i := 0; i < 3; i++ {
+		s := solarAt(t)
+		cosHA := math.Cos(rad(zenith))/(math.Cos(rad(lat))*math.Cos(rad(s.declination))) -
+			math.Tan(rad(lat))*math.Tan(rad(s.declination))
+		if cosHA > 1 {
+			return time.Time{}, false, false
+		}
+		if cosHA < -1 {
+			return time.Time{}, true, false
+		}
+		ha := deg(math.Acos(cosHA))
+
+		// noon moves with the equation of time during the day
+		n := noon.Add(minutesDuration(solarAt(noon).eqOfTime - s.eqOfTime))
+		t = n.Add(minutesDuration(sign * 4 * ha))
+	}
+	return t, false, true
+}
+
+// minutesDuration returns the minutes as a time.Duration.
+func minutesDuration(m float64) time.Duration {
+	return time.Duration(m * float64(time.Minute))
+}
@@ -0,0 +1,376 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cassette records the responses of the API once and replays them
+// in tests. A Recorder is an http.RoundTripper plugged in with
+// WithHttpClient:
+//
+//	r, err := cassette.New("testdata/berlin.json", cassette.Replay)
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer r.Close()
+//
+//	w, err := owm.NewCurrent("C", "EN", os.Getenv("OWM_API_KEY"), owm.WithHttpClient(r.Client()))
+//
+// Cassettes are JSON files of request and response pairs. The appid is
+// redacted before a request is stored, and requests are matched by their
+// method, path and normalized query so the key doesn't have to be known
+// when replaying.
+package cassette
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+var (
+	errInvalidMode   = errors.New("invalid cassette mode")
+	errNoInteraction = errors.New("no recorded interaction")
+	errClosed        = errors.New("cassette is closed")
+)
+
+// Mode is how a Recorder handles requests.
+type Mode int
+
+// Modes of a Recorder.
+const (
+	// Replay answers requests from the cassette and fails requests that
+	// weren't recorded.
+	Replay Mode = iota
+	// Record sends requests to the API and stores the responses, replacing
+	// the cassette when the Recorder is closed.
+	Record
+	// Passthrough sends requests to the API without touching the cassette.
+	Passthrough
+)
+
+// String returns the name of the mode.
+func (m Mode) String() string {
+	switch m {
+	case Replay:
+		return "replay"
+	case Record:
+		return "record"
+	case Passthrough:
+		return "passthrough"
+	}
+	return "Mode(" + strconv.Itoa(int(m)) + ")"
+}
+
+// ParseMode parses the name of a mode, e.g. from an environment variable.
+// An empty name is Replay.
+func ParseMode(name string) (Mode, error) {
+	switch strings.ToLower(name) {
+	case "", "replay":
+		return Replay, nil
+	case "record":
+		return Record, nil
+	case "passthrough":
+		return Passthrough, nil
+	}
+	return 0, fmt.Errorf("%q: %w", name, errInvalidMode)
+}
+
+// redacted replaces the value of the appid in stored requests.
+const redacted = "REDACTED"
+
+// Request is a recorded request.
+type Request struct {
+	Method string     `json:"method"`
+	Path   string     `json:"path"`
+	Query  url.Values `json:"query,omitempty"`
+}
+
+// Response is a recorded response. Bodies that aren't UTF-8, e.g. icons,
+// are stored in base64.
+type Response struct {
+	Status   int         `json:"status"`
+	Header   http.Header `json:"header,omitempty"`
+	Body     string      `json:"body"`
+	Encoding string      `json:"encoding,omitempty"`
+}
+
+// Interaction is a request and the response it got.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Cassette is the contents of a cassette file.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Recorder is an http.RoundTripper recording or replaying a cassette.
+type Recorder struct {
+	// Transport sends the requests in Record and Passthrough mode.
+	// http.DefaultTransport is used when nil.
+	Transport http.RoundTripper
+
+	path   string
+	mode   Mode
+	mu     sync.Mutex
+	tape   Cassette
+	used   []bool
+	closed bool
+}
+
+// New returns a new Recorder pointer for the cassette at path. The
+// cassette is loaded in Replay mode and must exist.
+func New(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{path: path, mode: mode}
+	switch mode {
+	case Replay:
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b, &r.tape); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		r.used = make([]bool, len(r.tape.Interactions))
+	case Record, Passthrough:
+	default:
+		return nil, errInvalidMode
+	}
+	return r, nil
+}
+
+// Mode returns the mode of the Recorder.
+func (r *Recorder) Mode() Mode { return r.mode }
+
+// Client returns a client sending its requests through the Recorder.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Interactions returns the interactions of the cassette.
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Interaction(nil), r.tape.Interactions...)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	closed := r.closed
+	r.mu.Unlock()
+	if closed {
+		return nil, errClosed
+	}
+
+	switch r.mode {
+	case Replay:
+		return r.replay(req)
+	case Record:
+		return r.record(req)
+	}
+	return r.transport().RoundTrip(req)
+}
+
+func (r *Recorder) transport() http.RoundTripper {
+	if r.Transport != nil {
+		return r.Transport
+	}
+	return http.DefaultTransport
+}
+
+// replay answers the request with the first unused matching interaction,
+// or the last matching one once they have all been used.
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	key := matchKey(req.Method, req.URL.Path, req.URL.Query())
+
+	r.mu.Lock()
+	found := -1
+	for i, in := range r.tape.Interactions {
+		if matchKey(in.Request.Method, in.Request.Path, in.Request.Query) != key {
+			continue
+		}
+		found = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if found >= 0 {
+		r.used[found] = true
+	}
+	r.mu.Unlock()
+
+	if found < 0 {
+		return nil, fmt.Errorf("%s %s: %w", req.Method, key, errNoInteraction)
+	}
+	return r.tape.Interactions[found].Response.http(req)
+}
+
+// record sends the request and stores the response.
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	response, err := r.transport().RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	in := Interaction{
+		Request: Request{
+			Method: req.Method,
+			Path:   req.URL.Path,
+			Query:  redact(req.URL.Query()),
+		},
+		Response: Response{
+			Status: response.StatusCode,
+			Header: response.Header.Clone(),
+			Body:   string(body),
+		},
+	}
+	if !utf8.Valid(body) {
+		in.Response.Body = base64.StdEncoding.EncodeToString(body)
+		in.Response.Encoding = "base64"
+	}
+
+	r.mu.Lock()
+	r.tape.Interactions = append(r.tape.Interactions, in)
+	r.mu.Unlock()
+
+	response.Body = ioutil.NopCloser(bytes.NewReader(body))
+	response.ContentLength = int64(len(body))
+	return response, nil
+}
+
+// Close writes the cassette when recording. Requests fail once the
+// Recorder is closed.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	if r.mode != Record {
+		return nil
+	}
+
+	b, err := json.MarshalIndent(r.tape, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.path, append(b, '\n'), 0644)
+}
+
+// http builds the response to the request.
+func (s Response) http(req *http.Request) (*http.Response, error) {
+	body := []byte(s.Body)
+	if s.Encoding == "base64" {
+		var err error
+		if body, err = base64.StdEncoding.DecodeString(s.Body); err != nil {
+			return nil, err
+		}
+	}
+
+	header := s.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", s.Status, http.StatusText(s.Status)),
+		StatusCode:    s.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// redact returns a copy of the query with the appid replaced.
+func redact(q url.Values) url.Values {
+	c := url.Values{}
+	for k, vs := range q {
+		c[k] = append([]string(nil), vs...)
+	}
+	if _, ok := c["appid"]; ok {
+		c.Set("appid", redacted)
+	}
+	return c
+}
+
+// matchKey is the path and normalized query requests are matched by. The
+// appid is left out, parameters are sorted and decimals lose their
+// trailing zeros so that e.g. lat=33.450000 matches lat=33.45.
+func matchKey(method, path string, q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		if k != "appid" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(strings.ToUpper(method))
+	b.WriteByte(' ')
+	b.WriteString(path)
+	for i, k := range keys {
+		if i == 0 {
+			b.WriteByte('?')
+		} else {
+			b.WriteByte('&')
+		}
+		vs := make([]string, len(q[k]))
+		for j, v := range q[k] {
+			vs[j] = normalize(v)
+		}
+		b.WriteString(url.QueryEscape(k))
+		b.WriteByte('=')
+		b.WriteString(url.QueryEscape(strings.Join(vs, ",")))
+	}
+	return b.String()
+}
+
+// normalize trims the trailing zeros of decimal values.
+func normalize(v string) string {
+	if !strings.Contains(v, ".") {
+		return v
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return v
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,298 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassette
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	owm "github.com/briandowns/openweathermap"
+	"github.com/briandowns/openweathermap/owmtest"
+)
+
+const testKey = "0123456789abcdef"
+
+var phoenix = &owm.Coordinates{Longitude: -112.07, Latitude: 33.45}
+
+// session calls the API through every constructor of the package and
+// returns what they decoded.
+func session(t *testing.T, client *http.Client) []interface{} {
+	t.Helper()
+	opt := owm.WithHttpClient(client)
+
+	c, err := owm.NewCurrent("C", "EN", testKey, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.CurrentByName("Berlin"); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := owm.NewCurrentGroup("C", "EN", testKey, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.CurrentByIDs(2950159, 2643743); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := owm.NewForecast("5", "C", "EN", testKey, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.DailyByID(2950159, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	o, err := owm.NewOneCall("C", "EN", testKey, []string{owm.ExcludeMinutely}, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.OneCallByCoordinates(phoenix); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := owm.NewPollution(testKey, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.PollutionByParams(&owm.PollutionParameters{Location: *phoenix, Datetime: "current"}); err != nil {
+		t.Fatal(err)
+	}
+
+	uv, err := owm.NewUV(testKey, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := uv.Current(phoenix); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := owm.NewHistorical("C", testKey, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.HistoryByName("Vancouver"); err != nil {
+		t.Fatal(err)
+	}
+
+	group := make([]owm.Main, len(g.List))
+	for i, w := range g.List {
+		group[i] = w.Main
+	}
+
+	return []interface{}{
+		c.Name, c.Main, group, f.ForecastWeatherJson, o.Current, o.Daily,
+		p.List, uv.Value, h.List,
+	}
+}
+
+// TestRecordReplay will verify that a recorded session is replayed without
+// the API and that the key isn't stored
+func TestRecordReplay(t *testing.T) {
+	t.Parallel()
+
+	srv := owmtest.NewServer()
+	path := filepath.Join(t.TempDir(), "session.json")
+
+	r, err := New(path, Record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Transport = srv.Client().Transport
+	recorded := session(t, r.Client())
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	calls := len(srv.Requests())
+	srv.Close()
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(b, []byte(testKey)) {
+		t.Error("expected the key to be redacted")
+	}
+	if !bytes.Contains(b, []byte(redacted)) {
+		t.Error("expected the appid to be stored redacted")
+	}
+
+	r, err = New(path, Replay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if n := len(r.Interactions()); n != calls {
+		t.Errorf("expected %d interactions, got %d", calls, n)
+	}
+
+	replayed := session(t, r.Client())
+	if !reflect.DeepEqual(recorded, replayed) {
+		t.Error("expected the replayed session to match the recorded one")
+	}
+}
+
+// TestReplayMatching will verify that requests are matched by their
+// normalized query and that unknown requests fail
+func TestReplayMatching(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "match.json")
+	tape := `{"interactions": [
+		{"request": {"method": "GET", "path": "/data/2.5/uvi", "query": {"appid": ["REDACTED"], "lat": ["33.45"], "lon": ["-112.07"]}},
+		 "response": {"status": 200, "body": "{\"value\": 1}"}},
+		{"request": {"method": "GET", "path": "/data/2.5/uvi", "query": {"lon": ["-112.07"], "lat": ["33.45"]}},
+		 "response": {"status": 200, "body": "{\"value\": 2}"}}
+	]}`
+	if err := ioutil.WriteFile(path, []byte(tape), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := New(path, Replay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	client := r.Client()
+
+	for _, want := range []string{`{"value": 1}`, `{"value": 2}`, `{"value": 2}`} {
+		response, err := client.Get("https://api.openweathermap.org/data/2.5/uvi?lon=-112.070000&lat=33.450000&appid=other")
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		if string(b) != want {
+			t.Errorf("expected %s, got %s", want, b)
+		}
+	}
+
+	_, err = client.Get("https://api.openweathermap.org/data/2.5/uvi?lat=1&lon=2")
+	if !errors.Is(err, errNoInteraction) {
+		t.Errorf("expected %v, got %v", errNoInteraction, err)
+	}
+}
+
+// TestBinaryBodies will verify that icons survive a recording
+func TestBinaryBodies(t *testing.T) {
+	t.Parallel()
+
+	srv := owmtest.NewServer()
+	defer srv.Close()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "icons.json")
+	for _, d := range []string{"recorded", "replayed"} {
+		if err := os.Mkdir(filepath.Join(dir, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r, err := New(path, Record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Transport = srv.Client().Transport
+	if _, err := owm.RetrieveIcon(filepath.Join(dir, "recorded"), "01d.png", owm.WithHttpClient(r.Client())); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err = New(path, Replay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if _, err := owm.RetrieveIcon(filepath.Join(dir, "replayed"), "01d.png", owm.WithHttpClient(r.Client())); err != nil {
+		t.Fatal(err)
+	}
+
+	a, _ := ioutil.ReadFile(filepath.Join(dir, "recorded", "01d.png"))
+	b, _ := ioutil.ReadFile(filepath.Join(dir, "replayed", "01d.png"))
+	if len(a) == 0 || !bytes.Equal(a, b) {
+		t.Error("expected the replayed icon to match the recorded one")
+	}
+}
+
+// TestPassthrough will verify that requests go to the API without a
+// cassette being written
+func TestPassthrough(t *testing.T) {
+	t.Parallel()
+
+	srv := owmtest.NewServer()
+	defer srv.Close()
+	path := filepath.Join(t.TempDir(), "passthrough.json")
+
+	r, err := New(path, Passthrough)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Transport = srv.Client().Transport
+
+	c, err := owm.NewCurrent("C", "EN", testKey, owm.WithHttpClient(r.Client()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.CurrentByID(2950159); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if srv.Count("/weather") != 1 {
+		t.Error("expected the request to reach the API")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("expected no cassette to be written")
+	}
+	if err := c.CurrentByID(2950159); !errors.Is(err, errClosed) {
+		t.Errorf("expected %v, got %v", errClosed, err)
+	}
+}
+
+// TestNew will verify the modes and that a missing cassette can't be
+// replayed
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	if _, err := New(filepath.Join(t.TempDir(), "missing.json"), Replay); err == nil {
+		t.Error("expected an error replaying a missing cassette")
+	}
+	if _, err := New("x.json", Mode(7)); err != errInvalidMode {
+		t.Errorf("expected %v, got %v", errInvalidMode, err)
+	}
+
+	for _, m := range []Mode{Replay, Record, Passthrough} {
+		p, err := ParseMode(m.String())
+		if err != nil || p != m {
+			t.Errorf("%s: got %s, %v", m, p, err)
+		}
+	}
+	if m, err := ParseMode(""); err != nil || m != Replay {
+		t.Errorf("expected replay by default, got %s, %v", m, err)
+	}
+	if _, err := ParseMode("rewind"); !errors.Is(err, errInvalidMode) {
+		t.Errorf("expected %v, got %v", errInvalidMode, err)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command owm-proxy serves the OpenWeatherMap API to several clients with
+// a single API key, caching the responses, see the proxy package.
+//
+//	owm-proxy [-addr :8080] -tokens tokens.yaml [-rate 60 -per 1m] [-ttl weather=5m,...]
+//	owm-proxy -open ...
+//
+// The API key is read from the -key flag or the OWM_API_KEY environment
+// variable. The tokens file maps client names to their tokens, as YAML or
+// JSON, e.g.
+//
+//	billing: 3f9a0c...
+//	dashboards: 81be44...
+//
+// It's required unless -open is given, which lets any client use the API
+// key and is logged as a warning.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	owm "github.com/briandowns/openweathermap"
+	"github.com/briandowns/openweathermap/proxy"
+	"gopkg.in/yaml.v3"
+)
+
+// settings are the settings of a run.
+type settings struct {
+	addr  string
+	proxy *proxy.Proxy
+}
+
+// parse parses the command line into the settings.
+func parse(args []string, getenv func(string) string, stderr io.Writer) (*settings, error) {
+	fs := flag.NewFlagSet("owm-proxy", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	addr := fs.String("addr", ":8080", "address to listen on")
+	key := fs.String("key", "", "API key, instead of OWM_API_KEY")
+	tokens := fs.String("tokens", "", "file of the client tokens by name, YAML or JSON")
+	open := fs.Bool("open", false, "serve any client without a token, instead of -tokens")
+	rate := fs.Int("rate", 60, "upstream requests per period, 0 for no limit")
+	per := fs.Duration("per", time.Minute, "period of the rate")
+	maxWait := fs.Duration("max-wait", proxy.DefaultMaxWait, "how long requests wait for the rate limit before a 429")
+	ttls := fs.String("ttl", "", "cache TTLs by endpoint, e.g. weather=5m,forecast/daily=1h,img=0")
+	maxEntries := fs.Int("max-entries", proxy.DefaultMaxEntries, "responses cached")
+	upstream := fs.String("upstream", proxy.DefaultUpstream, "URL of the API")
+	iconUpstream := fs.String("icon-upstream", proxy.DefaultIconUpstream, "URL of the icons")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments %q", fs.Args())
+	}
+
+	if *key == "" {
+		*key = getenv("OWM_API_KEY")
+	}
+	if *key == "" {
+		return nil, errors.New("no API key, set OWM_API_KEY or -key")
+	}
+	options := []proxy.Option{
+		proxy.WithUpstream(*upstream),
+		proxy.WithIconUpstream(*iconUpstream),
+		proxy.WithMaxWait(*maxWait),
+		proxy.WithMaxEntries(*maxEntries),
+	}
+	if *rate > 0 {
+		options = append(options, proxy.WithRateLimiter(owm.NewTokenBucket(*rate, *per)))
+	}
+	switch {
+	case *tokens != "" && *open:
+		return nil, errors.New("-tokens and -open can't be used together")
+	case *tokens != "":
+		t, err := loadTokens(*tokens)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, proxy.WithTokens(t))
+	case *open:
+		fmt.Fprintln(stderr, "owm-proxy: warning: running open, any client can use the API key")
+		options = append(options, proxy.WithOpenAccess())
+	default:
+		return nil, errors.New("no client tokens, set -tokens or run open with -open")
+	}
+	for _, kv := range strings.Split(*ttls, ",") {
+		if kv = strings.TrimSpace(kv); kv == "" {
+			continue
+		}
+		i := strings.Index(kv, "=")
+		if i < 1 {
+			return nil, fmt.Errorf("invalid TTL %q, should be endpoint=duration", kv)
+		}
+		d, err := time.ParseDuration(kv[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid TTL %q: %w", kv, err)
+		}
+		options = append(options, proxy.WithTTL(kv[:i], d))
+	}
+
+	p, err := proxy.New(*key, options...)
+	if err != nil {
+		return nil, err
+	}
+	return &settings{addr: *addr, proxy: p}, nil
+}
+
+// loadTokens reads the tokens file. JSON is read as YAML.
+func loadTokens(path string) (map[string]string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var tokens map[string]string
+	if err := yaml.Unmarshal(b, &tokens); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("%s: no tokens", path)
+	}
+	return tokens, nil
+}
+
+func main() {
+	s, err := parse(os.Args[1:], os.Getenv, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "owm-proxy: %v\n", err)
+		os.Exit(2)
+	}
+
+	srv := &http.Server{
+		Addr:              s.addr,
+		Handler:           s.proxy,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	log.Printf("owm-proxy listening on %s", s.addr)
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalln(err)
+	}
+	<-done // in flight requests
+}
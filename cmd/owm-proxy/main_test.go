@@ -0,0 +1,105 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/briandowns/openweathermap/owmtest"
+)
+
+// TestParse will verify that the command line is turned into a proxy
+// serving the API with the key, the tokens and the TTLs
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	srv := owmtest.NewServer()
+	defer srv.Close()
+	srv.RequireKey("upstream-key")
+
+	tokens := filepath.Join(t.TempDir(), "tokens.yaml")
+	if err := ioutil.WriteFile(tokens, []byte("billing: tok-b\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	env := func(name string) string {
+		if name == "OWM_API_KEY" {
+			return "upstream-key"
+		}
+		return ""
+	}
+
+	s, err := parse([]string{"-addr", ":9090", "-upstream", srv.URL, "-tokens", tokens, "-ttl", "weather=0"}, env, ioutil.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.addr != ":9090" {
+		t.Errorf("expected :9090, got %s", s.addr)
+	}
+	ps := httptest.NewServer(s.proxy)
+	defer ps.Close()
+
+	for i, tc := range []struct {
+		token string
+		want  int
+	}{
+		{"tok-b", http.StatusOK},
+		{"tok-b", http.StatusOK},
+		{"nope", http.StatusUnauthorized},
+	} {
+		resp, err := http.Get(ps.URL + "/data/2.5/weather?q=Berlin&appid=" + tc.token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.want {
+			t.Errorf("%d: expected %d, got %d", i, tc.want, resp.StatusCode)
+		}
+	}
+	if n := srv.Count("/weather"); n != 2 {
+		t.Errorf("expected weather not to be cached, got %d upstream requests", n)
+	}
+
+	for _, tc := range []struct {
+		args []string
+		env  func(string) string
+		want string
+	}{
+		{nil, func(string) string { return "" }, "no API key"},
+		{nil, env, "no client tokens"},
+		{[]string{"-open", "-tokens", tokens}, env, "can't be used together"},
+		{[]string{"-open", "-ttl", "weather"}, env, "invalid TTL"},
+		{[]string{"-open", "-ttl", "weather=soon"}, env, "invalid TTL"},
+		{[]string{"-tokens", filepath.Join(t.TempDir(), "missing.yaml")}, env, "no such file"},
+		{[]string{"extra"}, env, "unexpected arguments"},
+	} {
+		if _, err := parse(tc.args, tc.env, ioutil.Discard); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%v: expected %q, got %v", tc.args, tc.want, err)
+		}
+	}
+
+	var stderr strings.Builder
+	s, err = parse([]string{"-open"}, env, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.proxy.Open() || !strings.Contains(stderr.String(), "warning: running open") {
+		t.Errorf("expected an open proxy with a warning, got %q", stderr.String())
+	}
+}
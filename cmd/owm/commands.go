@@ -0,0 +1,640 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	owm "github.com/briandowns/openweathermap"
+	"github.com/briandowns/openweathermap/format"
+)
+
+const currentText = `{{.Name}}, {{.Sys.Country}} ({{.GeoPos.Latitude}}, {{.GeoPos.Longitude}}) at {{time .Dt .Timezone}}
+  Conditions:  {{desc .Weather}}
+  Temperature: {{temp .Main.Temp}}, feels like {{temp .Main.FeelsLike}}
+  Low / high:  {{temp .Main.TempMin}} / {{temp .Main.TempMax}}
+  Humidity:    {{.Main.Humidity}}%
+  Pressure:    {{.Main.Pressure}} hPa
+  Wind:        {{speed .Wind.Speed}} {{compass .Wind.Deg}}
+  Clouds:      {{.Clouds.All}}%
+`
+
+// current prints the current weather.
+func (a *app) current(args []string) error {
+	fs := a.flags("current", "[location]")
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+	l, err := a.parseLocation(fs.Args())
+	if err != nil {
+		return err
+	}
+	key, err := a.apiKey()
+	if err != nil {
+		return err
+	}
+
+	w, err := owm.NewCurrent(a.unit, a.lang, key, a.options()...)
+	if err != nil {
+		return err
+	}
+	switch {
+	case l.coords != nil:
+		err = w.CurrentByCoordinates(l.coords)
+	case l.id != 0:
+		err = w.CurrentByID(l.id)
+	default:
+		err = w.CurrentByName(l.name)
+	}
+	if err != nil {
+		return err
+	}
+	return a.render(w, currentText)
+}
+
+const forecast5Text = `{{.City.Name}}, {{.City.Country}}
+{{range .List}}  {{utc .Dt}}  {{temp .Main.Temp}}  {{speed .Wind.Speed}} {{compass .Wind.Deg}}  {{desc .Weather}}
+{{end}}`
+
+const forecast16Text = `{{.City.Name}}, {{.City.Country}}
+{{range .List}}  {{date .Dt 0}}  {{temp .Temp.Min}} / {{temp .Temp.Max}}  {{.Humidity}}%  {{desc .Weather}}
+{{end}}`
+
+const hourlyText = `{{.Timezone}}
+{{range .Hourly}}  {{time .Dt $.TimezoneOffset}}  {{temp .Temp}}  {{pct .Pop}} precipitation  {{desc .Weather}}
+{{end}}`
+
+// forecast prints the 5 day, 16 day or hourly forecast.
+func (a *app) forecast(args []string) error {
+	fs := a.flags("forecast", "[location]")
+	typ := fs.String("type", "5", "forecast: 5 (3 hourly), 16 (daily) or hourly")
+	days := fs.Int("days", 0, "days to forecast, 5, 7 and 2 by default and at most 5, 16 and 2")
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+
+	max := map[string]int{"5": 5, "16": 16, "hourly": 2}[*typ]
+	if max == 0 {
+		return usagef("invalid forecast type %q, should be 5, 16 or hourly", *typ)
+	}
+	if *days == 0 {
+		*days = map[string]int{"5": 5, "16": 7, "hourly": 2}[*typ]
+	}
+	if *days < 1 || *days > max {
+		return usagef("days should be between 1 and %d", max)
+	}
+
+	l, err := a.parseLocation(fs.Args())
+	if err != nil {
+		return err
+	}
+	key, err := a.apiKey()
+	if err != nil {
+		return err
+	}
+
+	if *typ == "hourly" {
+		c, err := a.coordinates(l)
+		if err != nil {
+			return err
+		}
+		o, err := owm.NewOneCall(a.unit, a.lang, key, []string{owm.ExcludeCurrent, owm.ExcludeMinutely, owm.ExcludeDaily, owm.ExcludeAlerts}, a.options()...)
+		if err != nil {
+			return err
+		}
+		if err := o.OneCallByCoordinates(c); err != nil {
+			return err
+		}
+		if n := *days * 24; len(o.Hourly) > n {
+			o.Hourly = o.Hourly[:n]
+		}
+		return a.render(o, hourlyText)
+	}
+
+	f, err := owm.NewForecast(*typ, a.unit, a.lang, key, a.options()...)
+	if err != nil {
+		return err
+	}
+	cnt := *days
+	if *typ == "5" {
+		cnt *= 8
+	}
+	switch {
+	case l.coords != nil:
+		err = f.DailyByCoordinates(l.coords, cnt)
+	case l.id != 0:
+		err = f.DailyByID(l.id, cnt)
+	default:
+		err = f.DailyByName(l.name, cnt)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *typ == "5" {
+		return a.render(f.ForecastWeatherJson, forecast5Text)
+	}
+	return a.render(f.ForecastWeatherJson, forecast16Text)
+}
+
+const onecallText = `{{.Timezone}} ({{.Latitude}}, {{.Longitude}})
+{{with .Current}}{{if .Dt}}Now, {{time .Dt $.TimezoneOffset}}
+  {{desc .Weather}}, {{temp .Temp}}, feels like {{temp .FeelsLike}}
+  Humidity {{.Humidity}}%, wind {{speed .WindSpeed}} {{compass .WindDeg}}, UV index {{.UVI}}
+{{end}}{{end}}{{if .Hourly}}Hourly
+{{range $i, $h := .Hourly}}{{if lt $i 12}}  {{hour .Dt $.TimezoneOffset}}  {{temp .Temp}}  {{pct .Pop}}  {{desc .Weather}}
+{{end}}{{end}}{{end}}{{if .Daily}}Daily
+{{range .Daily}}  {{date .Dt $.TimezoneOffset}}  {{temp .Temp.Min}} / {{temp .Temp.Max}}  {{pct .Pop}}  {{desc .Weather}}
+{{end}}{{end}}{{range .Alerts}}Alert: {{.Event}} until {{time .End $.TimezoneOffset}}
+{{end}}`
+
+// onecall prints the current weather, the forecasts and the alerts.
+func (a *app) onecall(args []string) error {
+	fs := a.flags("onecall", "[location]")
+	exclude := fs.String("exclude", "", "comma separated parts to leave out: current, minutely, hourly, daily, alerts")
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+
+	var excludes []string
+	if *exclude != "" {
+		excludes = strings.Split(*exclude, ",")
+		if _, err := owm.ValidExcludes(excludes); err != nil {
+			return usagef("invalid -exclude %q", *exclude)
+		}
+	}
+
+	o, err := a.oneCall(fs.Args(), excludes)
+	if err != nil {
+		return err
+	}
+	return a.render(o, onecallText)
+}
+
+// alertsView is the output of the alerts command.
+type alertsView struct {
+	Timezone       string                 `json:"timezone"`
+	TimezoneOffset int                    `json:"timezone_offset"`
+	Alerts         []owm.OneCallAlertData `json:"alerts"`
+}
+
+// Records tabulates the alerts.
+func (v alertsView) Records() (*format.Records, error) {
+	return format.Tabulate(v.Alerts)
+}
+
+const alertsText = `{{range .Alerts}}{{.Event}} by {{.SenderName}}
+  {{time .Start $.TimezoneOffset}} until {{time .End $.TimezoneOffset}}
+  {{.Description}}
+{{else}}No active alerts in {{.Timezone}}
+{{end}}`
+
+// alerts prints the active weather alerts.
+func (a *app) alerts(args []string) error {
+	fs := a.flags("alerts", "[location]")
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+
+	o, err := a.oneCall(fs.Args(), []string{owm.ExcludeCurrent, owm.ExcludeMinutely, owm.ExcludeHourly, owm.ExcludeDaily})
+	if err != nil {
+		return err
+	}
+	alerts := o.Alerts
+	if alerts == nil {
+		alerts = []owm.OneCallAlertData{}
+	}
+	return a.render(alertsView{o.Timezone, o.TimezoneOffset, alerts}, alertsText)
+}
+
+// oneCall gets the one call data of the location given by the arguments.
+func (a *app) oneCall(args, excludes []string) (*owm.OneCallData, error) {
+	l, err := a.parseLocation(args)
+	if err != nil {
+		return nil, err
+	}
+	key, err := a.apiKey()
+	if err != nil {
+		return nil, err
+	}
+	c, err := a.coordinates(l)
+	if err != nil {
+		return nil, err
+	}
+
+	o, err := owm.NewOneCall(a.unit, a.lang, key, excludes, a.options()...)
+	if err != nil {
+		return nil, err
+	}
+	if err := o.OneCallByCoordinates(c); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+const pollutionText = `Air pollution at {{.Location.Latitude}}, {{.Location.Longitude}}
+{{range .List}}  {{utc .Dt}}
+  Air quality: {{.Main.Aqi}} ({{aqi .Main.Aqi}})
+  CO {{.Components.Co}}, NO {{.Components.No}}, NO2 {{.Components.No2}}, O3 {{.Components.O3}}, SO2 {{.Components.So2}} μg/m³
+  PM2.5 {{.Components.Pm25}}, PM10 {{.Components.Pm10}}, NH3 {{.Components.Nh3}} μg/m³
+{{end}}`
+
+// pollution prints the current air pollution.
+func (a *app) pollution(args []string) error {
+	fs := a.flags("pollution", "[location]")
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+	c, err := a.locate(fs.Args())
+	if err != nil {
+		return err
+	}
+	key, _ := a.apiKey()
+
+	p, err := owm.NewPollution(key, a.options()...)
+	if err != nil {
+		return err
+	}
+	if err := p.PollutionByParams(&owm.PollutionParameters{Location: *c, Datetime: "current"}); err != nil {
+		return err
+	}
+	if p.Location == (owm.Coordinates{}) {
+		p.Location = *c
+	}
+	return a.render(p, pollutionText)
+}
+
+// uvView is the output of the uv command.
+type uvView struct {
+	Coordinates owm.Coordinates `json:"coord"`
+	Dt          int64           `json:"dt"`
+	Value       float64         `json:"value"`
+	Risk        string          `json:"risk,omitempty"`
+	Protection  string          `json:"protection,omitempty"`
+}
+
+const uvText = `UV index at {{.Coordinates.Latitude}}, {{.Coordinates.Longitude}}: {{.Value}}{{if .Risk}} ({{.Risk}}){{end}}
+{{if .Protection}}  {{.Protection}}
+{{end}}`
+
+// uv prints the current UV index.
+func (a *app) uv(args []string) error {
+	fs := a.flags("uv", "[location]")
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+	c, err := a.locate(fs.Args())
+	if err != nil {
+		return err
+	}
+	key, _ := a.apiKey()
+
+	u, err := owm.NewUV(key, a.options()...)
+	if err != nil {
+		return err
+	}
+	if err := u.Current(c); err != nil {
+		return err
+	}
+
+	v := uvView{Coordinates: *c, Dt: u.DT, Value: u.Value}
+	if info, err := u.UVInformation(); err == nil && len(info) > 0 {
+		v.Risk, v.Protection = info[0].Risk, info[0].RecommendedProtection
+	}
+	return a.render(v, uvText)
+}
+
+// locate returns the coordinates of the location given by the arguments.
+func (a *app) locate(args []string) (*owm.Coordinates, error) {
+	l, err := a.parseLocation(args)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := a.apiKey(); err != nil {
+		return nil, err
+	}
+	return a.coordinates(l)
+}
+
+const historyText = `{{range .List}}{{utc .Dt}}  {{temp .Main.Temp}}  {{.Main.Humidity}}%  {{speed .Wind.Speed}} {{compass .Wind.Deg}}  {{desc .Weather}}
+{{else}}No history
+{{end}}`
+
+// history prints the historical weather.
+func (a *app) history(args []string) error {
+	fs := a.flags("history", "[location]")
+	start := fs.String("start", "", "start of the range, RFC 3339, YYYY-MM-DD or unix time")
+	end := fs.String("end", "", "end of the range, RFC 3339, YYYY-MM-DD or unix time")
+	count := fs.Int("count", 0, "number of hours to return instead of -end")
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+
+	var p owm.HistoricalParameters
+	var err error
+	if p.Start, err = parseTime(*start); err != nil {
+		return usagef("invalid -start: %v", err)
+	}
+	if p.End, err = parseTime(*end); err != nil {
+		return usagef("invalid -end: %v", err)
+	}
+	p.Cnt = *count
+	switch {
+	case p.Cnt < 0:
+		return usagef("-count can't be negative")
+	case p.End != 0 && p.End < p.Start:
+		return usagef("-end is before -start")
+	case (p.End != 0 || p.Cnt != 0) && p.Start == 0:
+		return usagef("-end and -count need -start")
+	}
+
+	l, err := a.parseLocation(fs.Args())
+	if err != nil {
+		return err
+	}
+	key, err := a.apiKey()
+	if err != nil {
+		return err
+	}
+
+	h, err := owm.NewHistorical(a.unit, key, a.options()...)
+	if err != nil {
+		return err
+	}
+	switch {
+	case p.Start == 0 && l.name != "":
+		err = h.HistoryByName(l.name)
+	case p.Start == 0 && l.id != 0:
+		err = h.HistoryByID(l.id)
+	default:
+		if p.Start == 0 {
+			p.Start, p.End = time.Now().Add(-24*time.Hour).Unix(), time.Now().Unix()
+		}
+		var c *owm.Coordinates
+		if c, err = a.coordinates(l); err == nil {
+			err = h.HistoryByCoord(c, &p)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return a.render(h, historyText)
+}
+
+// parseTime parses an RFC 3339 time, a YYYY-MM-DD date in UTC or unix time.
+// It returns 0 for an empty string.
+func parseTime(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Unix(), nil
+		}
+	}
+	return 0, fmt.Errorf("%q is not a time", s)
+}
+
+const geocodeText = `{{range .}}{{.Name}}{{if .State}}, {{.State}}{{end}}, {{.Country}}  {{.Latitude}}, {{.Longitude}}
+{{end}}`
+
+// geocode prints the places matching a name or zip code, or the places
+// near coordinates.
+func (a *app) geocode(args []string) error {
+	fs := a.flags("geocode", "<name | zip,country | lat,lon>")
+	limit := fs.Int("limit", 5, "maximum number of places, 1 to 5")
+	zip := fs.Bool("zip", false, "look up a zip code, e.g. 19125,US")
+	reverse := fs.Bool("reverse", false, "look up the places near coordinates")
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+
+	q := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	switch {
+	case q == "":
+		return usagef("nothing to look up")
+	case *zip && *reverse:
+		return usagef("-zip and -reverse can't be used together")
+	case *limit < 1 || *limit > 5:
+		return usagef("-limit should be between 1 and 5")
+	}
+	key, err := a.apiKey()
+	if err != nil {
+		return err
+	}
+	g, err := owm.NewGeocoding(key, a.options()...)
+	if err != nil {
+		return err
+	}
+
+	var places []owm.GeoLocation
+	switch {
+	case *zip:
+		parts := strings.SplitN(q, ",", 2)
+		if len(parts) != 2 {
+			return usagef("%q should be zip,country", q)
+		}
+		var p *owm.GeoLocation
+		if p, err = g.Zip(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])); err == nil {
+			places = []owm.GeoLocation{*p}
+		}
+	case *reverse:
+		c, ok := parseCoordinates(q)
+		if !ok {
+			return usagef("%q should be lat,lon", q)
+		}
+		places, err = g.Reverse(c, *limit)
+	default:
+		places, err = g.Direct(q, *limit)
+	}
+	if err != nil {
+		return err
+	}
+	if len(places) == 0 {
+		return fmt.Errorf("%s: %w", q, errNotFound)
+	}
+	return a.render(places, geocodeText)
+}
+
+// iconFile is a downloaded icon.
+type iconFile struct {
+	Code  string `json:"code"`
+	Path  string `json:"path"`
+	Bytes int64  `json:"bytes"`
+}
+
+const iconListText = `{{range .}}{{printf "%-20s" .Condition}} {{.Day}} {{.Night}}
+{{end}}`
+
+const iconFilesText = `{{range .}}{{.Path}}{{if not .Bytes}} (exists){{end}}
+{{end}}`
+
+// icons downloads the icons of the codes, or lists the icons without any.
+func (a *app) icons(args []string) error {
+	fs := a.flags("icons", "[code ...]")
+	size := fs.String("size", "1x", "icon size: 1x, 2x or 4x")
+	dir := fs.String("dir", ".", "directory to download to")
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return a.render(owm.IconList, iconListText)
+	}
+
+	sizes := map[string]owm.IconSize{"1x": owm.IconSize1x, "2x": owm.IconSize2x, "4x": owm.IconSize4x}
+	s, ok := sizes[*size]
+	if !ok {
+		return usagef("invalid -size %q, should be 1x, 2x or 4x", *size)
+	}
+
+	icons, err := owm.NewIcons(a.options()...)
+	if err != nil {
+		return err
+	}
+	files := make([]iconFile, 0, fs.NArg())
+	for _, code := range fs.Args() {
+		n, err := icons.Retrieve(*dir, code, s)
+		if err != nil {
+			return fmt.Errorf("%s: %w", code, err)
+		}
+		files = append(files, iconFile{code, filepath.Join(*dir, owm.IconFileName(code, s)), n})
+	}
+	return a.render(files, iconFilesText)
+}
+
+const sentText = `{{range .}}Sent the measurement of station {{.StationID}} at {{utc .Dt}}
+{{end}}`
+
+const aggregatedText = `{{range .}}{{utc .Date}}  {{printf "%.1f" .Temp.Average}}°C ({{printf "%.1f" .Temp.Min}} to {{printf "%.1f" .Temp.Max}})  {{printf "%.0f" .Humidity.Average}}%  {{printf "%.0f" .Pressure.Average}} hPa
+{{else}}No measurements
+{{end}}`
+
+// stations sends a measurement of a station, or gets the aggregated ones.
+func (a *app) stations(args []string) error {
+	if len(args) == 0 || (args[0] != "send" && args[0] != "get") {
+		return usagef("usage: owm stations send|get -station ID [flags]")
+	}
+	if args[0] == "send" {
+		return a.stationsSend(args[1:])
+	}
+	return a.stationsGet(args[1:])
+}
+
+// stationsSend sends a measurement, metric like the measurements API.
+func (a *app) stationsSend(args []string) error {
+	fs := a.flags("stations send", "")
+	station := fs.String("station", "", "station ID")
+	dt := fs.String("dt", "", "time of the measurement, now by default")
+	m := owm.Measurement{}
+	fields := map[string]**float64{
+		"temp":       &m.Temperature,
+		"humidity":   &m.Humidity,
+		"pressure":   &m.Pressure,
+		"wind-speed": &m.WindSpeed,
+		"wind-deg":   &m.WindDeg,
+		"rain-1h":    &m.Rain1h,
+	}
+	values := map[string]*float64{}
+	for name := range fields {
+		values[name] = fs.Float64(name, 0, name+", metric, left out when not given")
+	}
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+	if *station == "" {
+		return usagef("-station is required")
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		if field, ok := fields[f.Name]; ok {
+			*field = owm.Float64(*values[f.Name])
+		}
+	})
+	m.StationID = *station
+	var err error
+	if m.Dt, err = parseTime(*dt); err != nil {
+		return usagef("invalid -dt: %v", err)
+	}
+	if m.Dt == 0 {
+		m.Dt = time.Now().Unix()
+	}
+	if err := m.Validate(); err != nil {
+		return usagef("%v", err)
+	}
+
+	key, err := a.apiKey()
+	if err != nil {
+		return err
+	}
+	ms, err := owm.NewMeasurements(key, a.options()...)
+	if err != nil {
+		return err
+	}
+	sent := []owm.Measurement{m}
+	if err := ms.Send(sent); err != nil {
+		return err
+	}
+	return a.render(sent, sentText)
+}
+
+// stationsGet prints the aggregated measurements of a station.
+func (a *app) stationsGet(args []string) error {
+	fs := a.flags("stations get", "")
+	station := fs.String("station", "", "station ID")
+	typ := fs.String("type", owm.AggregateHour, "aggregation: m, h or d")
+	limit := fs.Int("limit", 10, "maximum number of measurements")
+	from := fs.String("from", "", "start of the range, RFC 3339, YYYY-MM-DD or unix time")
+	to := fs.String("to", "", "end of the range, RFC 3339, YYYY-MM-DD or unix time")
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+	switch {
+	case *station == "":
+		return usagef("-station is required")
+	case *typ != owm.AggregateMinute && *typ != owm.AggregateHour && *typ != owm.AggregateDay:
+		return usagef("invalid -type %q, should be m, h or d", *typ)
+	}
+
+	p := &owm.MeasurementParameters{StationID: *station, Type: *typ, Limit: *limit}
+	var err error
+	if p.From, err = parseTime(*from); err != nil {
+		return usagef("invalid -from: %v", err)
+	}
+	if p.To, err = parseTime(*to); err != nil {
+		return usagef("invalid -to: %v", err)
+	}
+
+	key, err := a.apiKey()
+	if err != nil {
+		return err
+	}
+	ms, err := owm.NewMeasurements(key, a.options()...)
+	if err != nil {
+		return err
+	}
+	aggregated, err := ms.Aggregated(p)
+	if err != nil {
+		return err
+	}
+	return a.render(aggregated, aggregatedText)
+}
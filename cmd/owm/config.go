@@ -0,0 +1,145 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	owm "github.com/briandowns/openweathermap"
+	"gopkg.in/yaml.v3"
+)
+
+// config is the config file, the library's Config along with the defaults
+// of the command, e.g.
+//
+//	api_key: 0123456789abcdef
+//	unit: F
+//	lang: EN
+//	location: Philadelphia,US
+//	output: json
+type config struct {
+	owm.Config `yaml:",inline"`
+	Location   string `json:"location,omitempty" yaml:"location,omitempty"`
+	Output     string `json:"output,omitempty" yaml:"output,omitempty"`
+}
+
+// defaultConfigPath returns the path of the config file used when none is
+// given, e.g. ~/.config/owm/config.yaml.
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "owm", "config.yaml")
+}
+
+// loadConfig reads the config file, as JSON when its extension is .json
+// and as YAML otherwise. Unknown fields are rejected to catch typos.
+func loadConfig(path string) (config, error) {
+	var c config
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return c, err
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		d := json.NewDecoder(bytes.NewReader(b))
+		d.DisallowUnknownFields()
+		err = d.Decode(&c)
+	} else {
+		d := yaml.NewDecoder(bytes.NewReader(b))
+		d.KnownFields(true)
+		if err = d.Decode(&c); err != nil && len(bytes.TrimSpace(b)) == 0 {
+			err = nil
+		}
+	}
+	if err != nil {
+		return c, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+// settle loads the config file the first time and fills in the settings
+// not given as flags, checking them.
+func (a *app) settle() error {
+	if !a.loaded {
+		path, explicit := a.configPath, a.configPath != ""
+		if !explicit {
+			path = a.getenv("OWM_CONFIG")
+			explicit = path != ""
+		}
+		if !explicit {
+			path = defaultConfigPath()
+		}
+		if path != "" {
+			c, err := loadConfig(path)
+			switch {
+			case err == nil:
+				a.config = c
+			case explicit || !os.IsNotExist(err):
+				return err
+			}
+		}
+		a.loaded = true
+	}
+
+	a.unit = strings.ToUpper(first(a.unit, a.config.Unit, "C"))
+	if !owm.ValidDataUnit(a.unit) {
+		return usagef("invalid unit %q, should be C, F or K", a.unit)
+	}
+	a.lang = strings.ToUpper(first(a.lang, a.config.Lang, "EN"))
+	if !owm.ValidLangCode(a.lang) {
+		return usagef("invalid language %q", a.lang)
+	}
+	a.output = normalizeOutput(first(a.output, a.config.Output, "text"))
+	if !validOutput(a.output) {
+		return usagef("invalid output %q, should be one of %s", a.output, strings.Join(outputFormats, ", "))
+	}
+	return nil
+}
+
+// apiKey returns the API key from the flag, the environment or the config
+// file.
+func (a *app) apiKey() (string, error) {
+	key := first(a.key, a.getenv("OWM_API_KEY"), a.config.APIKey)
+	if key == "" {
+		return "", usagef("no API key, set OWM_API_KEY, -key or api_key in the config file")
+	}
+	return key, nil
+}
+
+// options returns the options of the library's constructors.
+func (a *app) options() []owm.Option {
+	if a.client == nil {
+		return nil
+	}
+	return []owm.Option{owm.WithHttpClient(a.client)}
+}
+
+// first returns the first non empty value.
+func first(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,306 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	owm "github.com/briandowns/openweathermap"
+)
+
+// ANSI escape sequences of the dashboard.
+const (
+	enterScreen = "\x1b[?1049h\x1b[?25l" // alternate screen, hidden cursor
+	leaveScreen = "\x1b[?25h\x1b[?1049l"
+	clearScreen = "\x1b[H\x1b[2J"
+
+	bold   = "1"
+	dim    = "2"
+	red    = "1;31"
+	yellow = "33"
+	cyan   = "36"
+)
+
+// sparks are the levels of the sparklines, lowest first.
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// dashboardView is what the dashboard shows.
+type dashboardView struct {
+	title    string
+	data     *owm.OneCallData
+	updated  time.Time
+	err      error         // of the last refresh, shown until the next one
+	interval time.Duration // 0 when shown once
+	width    int
+	color    bool
+}
+
+// dashboard shows the current weather, the next 48 hours, the next 7 days
+// and the alerts of a location, full screen and refreshed on an interval.
+func (a *app) dashboard(args []string) error {
+	fs := a.flags("dashboard", "[location]")
+	interval := fs.Duration("interval", 10*time.Minute, "refresh interval")
+	once := fs.Bool("once", false, "print the dashboard once instead of full screen")
+	width := fs.Int("width", 0, "width of the dashboard, $COLUMNS or 80 by default")
+	noColor := fs.Bool("no-color", false, "leave out the colors, also set by $NO_COLOR")
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+	if *interval < time.Minute && !*once {
+		return usagef("-interval should be at least 1m")
+	}
+	if *width == 0 {
+		*width, _ = strconv.Atoi(a.getenv("COLUMNS"))
+	}
+	if *width < 40 {
+		*width = 80
+	}
+
+	l, err := a.parseLocation(fs.Args())
+	if err != nil {
+		return err
+	}
+	key, err := a.apiKey()
+	if err != nil {
+		return err
+	}
+	c, err := a.coordinates(l)
+	if err != nil {
+		return err
+	}
+	fetch := func() (*owm.OneCallData, error) {
+		o, err := owm.NewOneCall(a.unit, a.lang, key, []string{owm.ExcludeMinutely}, a.options()...)
+		if err != nil {
+			return nil, err
+		}
+		return o, o.OneCallByCoordinates(c)
+	}
+
+	o, err := fetch()
+	if err != nil {
+		return err
+	}
+	v := &dashboardView{
+		title:   l.String(),
+		data:    o,
+		updated: time.Now(),
+		width:   *width,
+		color:   !*noColor && a.getenv("NO_COLOR") == "",
+	}
+	if *once {
+		a.drawDashboard(a.stdout, v)
+		return nil
+	}
+
+	v.interval = *interval
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return a.watchDashboard(ctx, v, fetch)
+}
+
+// watchDashboard shows the dashboard full screen and refreshes it until
+// the context is done. Failed refreshes keep the data and show the error.
+func (a *app) watchDashboard(ctx context.Context, v *dashboardView, fetch func() (*owm.OneCallData, error)) error {
+	fmt.Fprint(a.stdout, enterScreen)
+	defer fmt.Fprint(a.stdout, leaveScreen)
+
+	t := time.NewTicker(v.interval)
+	defer t.Stop()
+	for {
+		fmt.Fprint(a.stdout, clearScreen)
+		a.drawDashboard(a.stdout, v)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+		}
+		if o, err := fetch(); err != nil {
+			v.err = err
+		} else {
+			v.data, v.updated, v.err = o, time.Now(), nil
+		}
+	}
+}
+
+// drawDashboard writes the dashboard.
+func (a *app) drawDashboard(w io.Writer, v *dashboardView) {
+	temp, speed := symbols(a.unit)
+	o := v.data
+	at := func(unix int) time.Time {
+		return time.Unix(int64(unix), 0).In(time.FixedZone("", o.TimezoneOffset))
+	}
+	line := func(style, format string, args ...interface{}) {
+		s := fmt.Sprintf(format, args...)
+		if r := []rune(s); len(r) > v.width {
+			s = string(r[:v.width-1]) + "…"
+		}
+		if v.color && style != "" && s != "" {
+			s = "\x1b[" + style + "m" + s + "\x1b[0m"
+		}
+		fmt.Fprintln(w, s)
+	}
+
+	cur := o.Current
+	line(bold, " %s  %s · %s · %s", conditionGlyph(cur.Weather), v.title, o.Timezone, at(cur.Dt).Format("Mon Jan 2 15:04"))
+	line("", "    %s, %.1f%s, feels like %.1f%s", describe(cur.Weather), cur.Temp, temp, cur.FeelsLike, temp)
+	line(dim, "    Humidity %d%%  Wind %.1f %s %s  UV %.1f  Pressure %.0f hPa",
+		cur.Humidity, cur.WindSpeed, speed, owm.Wind{Deg: cur.WindDeg}.Cardinal16(), cur.UVI, cur.Pressure)
+
+	hourly := o.Hourly
+	if n := v.width - 24; len(hourly) > n {
+		hourly = hourly[:n]
+	}
+	if len(hourly) > 0 {
+		temps := make([]float64, len(hourly))
+		precip := make([]float64, len(hourly))
+		for i, h := range hourly {
+			temps[i] = h.Temp
+			precip[i] = h.Rain.OneH + h.Snow.OneH
+		}
+		lo, hi := bounds(temps)
+		_, wet := bounds(precip)
+
+		line("", "")
+		line(cyan, " Next %d hours", len(hourly))
+		line(yellow, " Temp  %s  %.1f%s – %.1f%s", sparkline(temps, lo, hi, false), lo, temp, hi, temp)
+		line(cyan, " Rain  %s  %.1f mm max", sparkline(precip, 0, wet, true), wet)
+
+		axis := []rune(strings.Repeat(" ", len(hourly)))
+		for i := 0; i < len(hourly); i++ {
+			label := at(hourly[i].Dt).Format("15:04")
+			if at(hourly[i].Dt).Hour()%12 == 0 && i+len(label) <= len(axis) {
+				copy(axis[i:], []rune(label))
+				i += len(label)
+			}
+		}
+		line(dim, "       %s", strings.TrimRight(string(axis), " "))
+	}
+
+	if len(o.Daily) > 0 {
+		line("", "")
+		line(cyan, " Next 7 days")
+		for i, d := range o.Daily {
+			if i == 7 {
+				break
+			}
+			line("", " %s  %s  %5.1f%s / %5.1f%s  %3.0f%%  %s", at(d.Dt).Format("Mon Jan 2"), conditionGlyph(d.Weather),
+				d.Temp.Min, temp, d.Temp.Max, temp, d.Pop*100, describe(d.Weather))
+		}
+	}
+
+	if len(o.Alerts) > 0 {
+		line("", "")
+		line(cyan, " Alerts")
+		for _, al := range o.Alerts {
+			line(red, " ⚠ %s (%s) until %s", al.Event, al.SenderName, at(al.End).Format("Mon Jan 2 15:04"))
+			line("", "   %s", strings.Join(strings.Fields(al.Description), " "))
+		}
+	}
+
+	if v.interval > 0 {
+		line("", "")
+		if v.err != nil {
+			line(red, " Refresh failed: %v", v.err)
+		}
+		line(dim, " Updated %s · refreshing every %s · Ctrl-C to quit", v.updated.Format("15:04:05"), v.interval)
+	}
+}
+
+// conditionGlyph returns the glyph of the first weather condition.
+func conditionGlyph(weather []owm.Weather) string {
+	if len(weather) == 0 {
+		return " "
+	}
+	c, ok := owm.LookupCondition(weather[0].ID)
+	if !ok {
+		return "?"
+	}
+	return glyph(c, weather[0].IsNight())
+}
+
+// glyph returns the Unicode glyph of the condition.
+func glyph(c owm.ConditionData, night bool) string {
+	switch c.Group() {
+	case owm.GroupThunderstorm:
+		return "⛈"
+	case owm.GroupDrizzle, owm.GroupRain:
+		return "☂"
+	case owm.GroupSnow:
+		return "❄"
+	case owm.GroupAtmosphere:
+		if c.IsSevere() {
+			return "⚠"
+		}
+		return "≡"
+	case owm.GroupClear:
+		if night {
+			return "☾"
+		}
+		return "☀"
+	case owm.GroupClouds:
+		if c.ID == 801 && !night {
+			return "⛅"
+		}
+		return "☁"
+	case owm.GroupExtreme:
+		return "⚠"
+	case owm.GroupAdditional:
+		return "≈"
+	}
+	return "?"
+}
+
+// bounds returns the lowest and highest values.
+func bounds(values []float64) (float64, float64) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	return lo, hi
+}
+
+// sparkline draws the values between lo and hi with a rune each. Zeros
+// are blank when blankZero is set, e.g. for hours without precipitation.
+func sparkline(values []float64, lo, hi float64, blankZero bool) string {
+	var b strings.Builder
+	for _, v := range values {
+		switch {
+		case blankZero && v <= 0:
+			b.WriteRune(' ')
+		case hi <= lo:
+			b.WriteRune(sparks[0])
+		default:
+			i := int(math.Round((v - lo) / (hi - lo) * float64(len(sparks)-1)))
+			if i < 0 {
+				i = 0
+			}
+			if i >= len(sparks) {
+				i = len(sparks) - 1
+			}
+			b.WriteRune(sparks[i])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,163 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	owm "github.com/briandowns/openweathermap"
+	"github.com/briandowns/openweathermap/owmtest"
+)
+
+// TestDashboardOnce will verify the sections of the dashboard
+func TestDashboardOnce(t *testing.T) {
+	t.Parallel()
+
+	srv := owmtest.NewServer()
+	defer srv.Close()
+
+	code, out, errOut := owmRun(t, srv, map[string]string{"NO_COLOR": "1"}, "dashboard", "-once", "Phoenix")
+	if code != exitOK {
+		t.Fatalf("expected %d, got %d: %s", exitOK, code, errOut)
+	}
+	for _, want := range []string{
+		" ☾  Phoenix · America/Phoenix · Mon May 13 05:00\n",
+		" Next 48 hours\n Temp  ▁▂▃",
+		" Next 7 days\n Mon May 13  ☀ ",
+		" Sun May 19  ☀ ",
+		" Alerts\n ⚠ Excessive Heat Warning (NWS Phoenix) until Mon May 13 20:00\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Mon May 20") || strings.Contains(out, "\x1b[") || strings.Contains(out, "Ctrl-C") {
+		t.Errorf("unexpected dashboard\n%s", out)
+	}
+
+	_, out, _ = owmRun(t, srv, nil, "dashboard", "-once", "-width", "60", "Vancouver")
+	if !strings.Contains(out, "\x1b[33m Temp  ") || strings.Contains(out, "Alerts") {
+		t.Errorf("expected colors and no alerts, got\n%s", out)
+	}
+	for _, l := range strings.Split(out, "\n") {
+		if !strings.HasPrefix(l, "\x1b[") && len([]rune(l)) > 60 {
+			t.Errorf("line wider than 60: %q", l)
+		}
+	}
+
+	if code, _, _ := owmRun(t, srv, nil, "dashboard", "-interval", "10s", "Phoenix"); code != exitUsage {
+		t.Errorf("expected %d for a short interval, got %d", exitUsage, code)
+	}
+	if code, _, _ := owmRun(t, srv, nil, "dashboard", "nowhere_"); code != exitNotFound {
+		t.Errorf("expected %d for an unknown place, got %d", exitNotFound, code)
+	}
+}
+
+// TestDashboardWatch will verify that the dashboard is refreshed full
+// screen and keeps its data when a refresh fails
+func TestDashboardWatch(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var out bytes.Buffer
+	a := &app{stdout: &out, unit: "C"}
+	v := &dashboardView{title: "Berlin", data: &owm.OneCallData{Timezone: "Europe/Berlin"}, interval: time.Millisecond, width: 80}
+	fetches := 0
+	fetch := func() (*owm.OneCallData, error) {
+		fetches++
+		if fetches == 2 {
+			cancel()
+			return nil, errors.New("connection refused")
+		}
+		return &owm.OneCallData{Timezone: "Europe/Berlin"}, nil
+	}
+
+	if err := a.watchDashboard(ctx, v, fetch); err != nil {
+		t.Fatal(err)
+	}
+	s := out.String()
+	if !strings.HasPrefix(s, enterScreen) || !strings.HasSuffix(s, leaveScreen) {
+		t.Errorf("expected the alternate screen, got %q", s)
+	}
+	if n := strings.Count(s, clearScreen); n != 3 {
+		t.Errorf("expected 3 frames, got %d", n)
+	}
+	frames := strings.Split(s, clearScreen)
+	if last := frames[len(frames)-1]; !strings.Contains(last, "Refresh failed: connection refused") || !strings.Contains(last, "Berlin · Europe/Berlin") {
+		t.Errorf("expected the error with the previous data, got %q", last)
+	}
+}
+
+// TestGlyph will verify the glyphs of the conditions
+func TestGlyph(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		id    int
+		night bool
+		want  string
+	}{
+		{200, false, "⛈"},
+		{300, false, "☂"},
+		{501, true, "☂"},
+		{601, false, "❄"},
+		{741, false, "≡"},
+		{781, false, "⚠"},
+		{800, false, "☀"},
+		{800, true, "☾"},
+		{801, false, "⛅"},
+		{801, true, "☁"},
+		{804, false, "☁"},
+	} {
+		c, ok := owm.LookupCondition(tc.id)
+		if !ok {
+			t.Fatalf("unknown condition %d", tc.id)
+		}
+		if got := glyph(c, tc.night); got != tc.want {
+			t.Errorf("%d: expected %s, got %s", tc.id, tc.want, got)
+		}
+	}
+	if got := conditionGlyph([]owm.Weather{{ID: 1}}); got != "?" {
+		t.Errorf("expected ? for an unknown condition, got %s", got)
+	}
+}
+
+// TestSparkline will verify that values are scaled between the bounds
+func TestSparkline(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		values    []float64
+		blankZero bool
+		want      string
+	}{
+		{[]float64{0, 1, 2}, false, "▁▅█"},
+		{[]float64{0, 1, 2}, true, " ▅█"},
+		{[]float64{3, 3}, false, "▁▁"},
+		{nil, false, ""},
+	} {
+		lo, hi := bounds(tc.values)
+		if got := sparkline(tc.values, lo, hi, tc.blankZero); got != tc.want {
+			t.Errorf("%v: expected %q, got %q", tc.values, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	owm "github.com/briandowns/openweathermap"
+)
+
+var errNotFound = errors.New("location not found")
+
+// location is a location given on the command line: a name, a city ID or
+// coordinates.
+type location struct {
+	name   string
+	id     int
+	coords *owm.Coordinates
+}
+
+// String returns the location as it was given.
+func (l location) String() string {
+	switch {
+	case l.coords != nil:
+		return fmt.Sprintf("%g,%g", l.coords.Latitude, l.coords.Longitude)
+	case l.id != 0:
+		return strconv.Itoa(l.id)
+	}
+	return l.name
+}
+
+// parseCoordinates parses "lat,lon".
+func parseCoordinates(s string) (*owm.Coordinates, bool) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return nil, false
+	}
+	lat, err1 := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	lon, err2 := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err1 != nil || err2 != nil || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return nil, false
+	}
+	return &owm.Coordinates{Latitude: lat, Longitude: lon}, true
+}
+
+// parseLocation parses the location given by the arguments, which are
+// joined so that names with spaces don't need quoting, or the one of the
+// config file.
+func (a *app) parseLocation(args []string) (location, error) {
+	s := strings.TrimSpace(strings.Join(args, " "))
+	if s == "" {
+		s = a.config.Location
+	}
+	if s == "" {
+		return location{}, usagef("no location given")
+	}
+
+	if c, ok := parseCoordinates(s); ok {
+		return location{coords: c}, nil
+	}
+	if id, err := strconv.Atoi(s); err == nil && id > 0 {
+		return location{id: id}, nil
+	}
+	return location{name: s}, nil
+}
+
+// coordinates returns the coordinates of the location, looking names up
+// with the geocoding API and city IDs with the current weather.
+func (a *app) coordinates(l location) (*owm.Coordinates, error) {
+	if l.coords != nil {
+		return l.coords, nil
+	}
+	key, err := a.apiKey()
+	if err != nil {
+		return nil, err
+	}
+
+	if l.id != 0 {
+		w, err := owm.NewCurrent(a.unit, a.lang, key, a.options()...)
+		if err != nil {
+			return nil, err
+		}
+		if err := w.CurrentByID(l.id); err != nil {
+			return nil, err
+		}
+		return &w.GeoPos, nil
+	}
+
+	g, err := owm.NewGeocoding(key, a.options()...)
+	if err != nil {
+		return nil, err
+	}
+	places, err := g.Direct(l.name, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(places) == 0 {
+		return nil, fmt.Errorf("%s: %w", l.name, errNotFound)
+	}
+	return places[0].Coordinates(), nil
+}
@@ -0,0 +1,223 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command owm queries the OpenWeatherMap API from the command line.
+//
+//	owm [global flags] <command> [flags] [location]
+//
+// Locations are names, e.g. "Berlin,DE", city IDs or "lat,lon"
+// coordinates, and default to the location of the config file. The API
+// key is read from the -key flag, the OWM_API_KEY environment variable or
+// the config file, in that order. Results are printed as text, or with
+// -o (or --output) as a table, JSON, NDJSON, CSV, YAML or through a user
+// template, e.g. -o 'template={{.Name}}: {{.Main.Temp}}'.
+//
+// The exit code is 0 on success, 1 for other errors, 2 for invalid usage,
+// 3 for an invalid API key, 4 when the location isn't found, 5 when
+// rate limited and 6 for server errors.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	owm "github.com/briandowns/openweathermap"
+)
+
+// Exit codes of the command.
+const (
+	exitOK = iota
+	exitError
+	exitUsage
+	exitInvalidKey
+	exitNotFound
+	exitRateLimited
+	exitServerError
+)
+
+// usageError is an error in the command line. Quiet ones were already
+// reported by the flag package.
+type usageError struct {
+	msg   string
+	quiet bool
+}
+
+func (e *usageError) Error() string { return e.msg }
+
+// usagef returns a usage error with the formatted message.
+func usagef(format string, args ...interface{}) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// exitCode returns the exit code of the error.
+func exitCode(err error) int {
+	var usage *usageError
+	var apiErr *owm.APIError
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.As(err, &usage):
+		return exitUsage
+	case owm.IsInvalidKey(err):
+		return exitInvalidKey
+	case errors.As(err, &apiErr):
+		switch {
+		case apiErr.StatusCode == http.StatusUnauthorized:
+			return exitInvalidKey
+		case apiErr.StatusCode == http.StatusNotFound:
+			return exitNotFound
+		case apiErr.StatusCode == http.StatusTooManyRequests:
+			return exitRateLimited
+		case apiErr.StatusCode >= 500:
+			return exitServerError
+		case apiErr.StatusCode == http.StatusBadRequest:
+			return exitUsage
+		}
+	case errors.Is(err, errNotFound):
+		return exitNotFound
+	}
+	return exitError
+}
+
+// command is a subcommand of owm.
+type command struct {
+	name  string
+	usage string
+	run   func(a *app, args []string) error
+}
+
+var commands = []command{
+	{"current", "current weather of a location", (*app).current},
+	{"forecast", "5 day, 16 day or hourly forecast of a location", (*app).forecast},
+	{"onecall", "current weather, forecasts and alerts of a location", (*app).onecall},
+	{"alerts", "active weather alerts of a location", (*app).alerts},
+	{"pollution", "air pollution of a location", (*app).pollution},
+	{"uv", "UV index of a location", (*app).uv},
+	{"history", "historical weather of a location", (*app).history},
+	{"geocode", "coordinates of places by name or zip code, or places by coordinates", (*app).geocode},
+	{"icons", "download condition icons or list them", (*app).icons},
+	{"stations", "send or get station measurements", (*app).stations},
+	{"dashboard", "full screen dashboard of a location, refreshed on an interval", (*app).dashboard},
+}
+
+// app holds the settings of a run.
+type app struct {
+	stdout io.Writer
+	stderr io.Writer
+	client *http.Client // nil for the default client
+	getenv func(string) string
+
+	configPath string
+	key        string
+	unit       string
+	lang       string
+	output     string
+
+	config config
+	loaded bool
+}
+
+// globalFlags registers the global flags on the flag set so they're also
+// accepted after the command.
+func (a *app) globalFlags(fs *flag.FlagSet) {
+	fs.StringVar(&a.configPath, "config", a.configPath, "config file, YAML or JSON")
+	fs.StringVar(&a.key, "key", a.key, "API key, instead of OWM_API_KEY")
+	fs.StringVar(&a.unit, "u", a.unit, "units: C, F or K")
+	fs.StringVar(&a.lang, "l", a.lang, "language code, e.g. EN")
+	fs.StringVar(&a.output, "o", a.output, "output format: "+strings.Join(outputFormats, ", "))
+	fs.StringVar(&a.output, "output", a.output, "same as -o")
+}
+
+// usage prints the usage of owm.
+func (a *app) usage(fs *flag.FlagSet) {
+	fmt.Fprintf(a.stderr, "Usage: owm [global flags] <command> [flags] [location]\n\nCommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(a.stderr, "  %-10s %s\n", c.name, c.usage)
+	}
+	fmt.Fprintf(a.stderr, "\nGlobal flags:\n")
+	fs.PrintDefaults()
+}
+
+// run runs owm with the arguments and returns the exit code.
+func (a *app) run(args []string) int {
+	if a.getenv == nil {
+		a.getenv = os.Getenv
+	}
+
+	fs := flag.NewFlagSet("owm", flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	a.globalFlags(fs)
+	fs.Usage = func() { a.usage(fs) }
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	name := fs.Arg(0)
+	for _, c := range commands {
+		if c.name != name {
+			continue
+		}
+		err := c.run(a, fs.Args()[1:])
+		var usage *usageError
+		if err != nil && !errors.Is(err, flag.ErrHelp) && !(errors.As(err, &usage) && usage.quiet) {
+			fmt.Fprintf(a.stderr, "owm %s: %v\n", name, err)
+		}
+		return exitCode(err)
+	}
+
+	fmt.Fprintf(a.stderr, "owm: unknown command %q\n", name)
+	fs.Usage()
+	return exitUsage
+}
+
+// flags returns the flag set of the command, including the global flags.
+func (a *app) flags(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(a.stderr, "Usage: owm %s [flags] %s\n\nFlags:\n", name, args)
+		fs.PrintDefaults()
+	}
+	a.globalFlags(fs)
+	return fs
+}
+
+// parse parses the flags of the command and checks the global ones again
+// since they may have been given after the command.
+func (a *app) parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return &usageError{msg: err.Error(), quiet: true}
+	}
+	return a.settle()
+}
+
+func main() {
+	a := &app{stdout: os.Stdout, stderr: os.Stderr}
+	os.Exit(a.run(os.Args[1:]))
+}
@@ -0,0 +1,451 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/briandowns/openweathermap/owmtest"
+)
+
+const testKey = "s3cret"
+
+// owmRun runs owm against the fake server with the environment, which
+// defaults to the test key and an empty config file, and returns the exit
+// code and the output.
+func owmRun(t *testing.T, srv *owmtest.Server, env map[string]string, args ...string) (int, string, string) {
+	t.Helper()
+
+	empty := filepath.Join(t.TempDir(), "config.yaml")
+	if err := ioutil.WriteFile(empty, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	defaults := map[string]string{"OWM_API_KEY": testKey, "OWM_CONFIG": empty}
+
+	var stdout, stderr bytes.Buffer
+	a := &app{
+		stdout: &stdout,
+		stderr: &stderr,
+		client: srv.Client(),
+		getenv: func(name string) string {
+			if v, ok := env[name]; ok {
+				return v
+			}
+			return defaults[name]
+		},
+	}
+	code := a.run(args)
+	return code, stdout.String(), stderr.String()
+}
+
+// TestCurrent will verify that the current weather is printed by name, ID
+// and coordinates
+func TestCurrent(t *testing.T) {
+	t.Parallel()
+
+	srv := owmtest.NewServer()
+	defer srv.Close()
+	srv.RequireKey(testKey)
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"current", "Philadelphia,US"}, "Philadelphia, US"},
+		{[]string{"current", "San", "Diego"}, "San Diego, US"},
+		{[]string{"current", "4560349"}, "Philadelphia, US"},
+		{[]string{"current", "33.45,-112.07"}, "Phoenix, US"},
+		{[]string{"-u", "F", "current", "Helena"}, "°F"},
+		{[]string{"current", "-u", "K", "Helena"}, "279.1K"},
+	} {
+		code, out, errOut := owmRun(t, srv, nil, tc.args...)
+		if code != exitOK || !strings.Contains(out, tc.want) {
+			t.Errorf("%v: expected %q, got %d %q %q", tc.args, tc.want, code, out, errOut)
+		}
+	}
+}
+
+// TestJSONOutput will verify that the JSON output decodes and doesn't
+// contain the API key
+func TestJSONOutput(t *testing.T) {
+	t.Parallel()
+
+	srv := owmtest.NewServer()
+	defer srv.Close()
+
+	code, out, errOut := owmRun(t, srv, nil, "-o", "json", "current", "Berlin")
+	if code != exitOK {
+		t.Fatalf("expected %d, got %d: %s", exitOK, code, errOut)
+	}
+	var w struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal([]byte(out), &w); err != nil || w.Name != "Berlin" {
+		t.Errorf("unexpected output %q: %v", out, err)
+	}
+	if strings.Contains(out, testKey) {
+		t.Errorf("the output contains the key: %s", out)
+	}
+}
+
+// TestExitCodes will verify that errors map to the documented exit codes
+func TestExitCodes(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name  string
+		fault owmtest.Fault
+		env   map[string]string
+		args  []string
+		want  int
+	}{
+		{"help", owmtest.Fault{}, nil, []string{"-h"}, exitOK},
+		{"command help", owmtest.Fault{}, nil, []string{"current", "-h"}, exitOK},
+		{"no command", owmtest.Fault{}, nil, nil, exitUsage},
+		{"unknown command", owmtest.Fault{}, nil, []string{"nope"}, exitUsage},
+		{"unknown flag", owmtest.Fault{}, nil, []string{"current", "-nope", "Berlin"}, exitUsage},
+		{"no location", owmtest.Fault{}, nil, []string{"current"}, exitUsage},
+		{"no key", owmtest.Fault{}, map[string]string{"OWM_API_KEY": ""}, []string{"current", "Berlin"}, exitUsage},
+		{"invalid unit", owmtest.Fault{}, nil, []string{"-u", "X", "current", "Berlin"}, exitUsage},
+		{"invalid output", owmtest.Fault{}, nil, []string{"-o", "xml", "current", "Berlin"}, exitUsage},
+		{"unknown city", owmtest.Fault{}, nil, []string{"current", "1"}, exitNotFound},
+		{"unknown place", owmtest.Fault{}, nil, []string{"onecall", "nowhere_"}, exitNotFound},
+		{"invalid key", owmtest.Unauthorized(), nil, []string{"current", "Berlin"}, exitInvalidKey},
+		{"rate limited", owmtest.RateLimited(), nil, []string{"current", "Berlin"}, exitRateLimited},
+		{"server error", owmtest.ServerError(503), nil, []string{"current", "Berlin"}, exitServerError},
+		{"malformed", owmtest.Malformed(), nil, []string{"current", "Berlin"}, exitError},
+	} {
+		srv := owmtest.NewServer()
+		if tc.fault != (owmtest.Fault{}) {
+			srv.Inject("/weather", tc.fault)
+		}
+		code, _, errOut := owmRun(t, srv, tc.env, tc.args...)
+		srv.Close()
+		if code != tc.want {
+			t.Errorf("%s: expected %d, got %d: %s", tc.name, tc.want, code, errOut)
+		}
+	}
+}
+
+// TestForecast will verify the 5 day, 16 day and hourly forecasts
+func TestForecast(t *testing.T) {
+	t.Parallel()
+
+	srv := owmtest.NewServer()
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		args  []string
+		lines int
+	}{
+		{[]string{"forecast", "-days", "1", "Berlin"}, 1 + 8},
+		{[]string{"forecast", "Berlin"}, 1 + 40},
+		{[]string{"forecast", "-type", "16", "-days", "3", "Berlin"}, 1 + 3},
+		{[]string{"forecast", "-type", "hourly", "-days", "1", "Berlin"}, 1 + 24},
+	} {
+		code, out, errOut := owmRun(t, srv, nil, tc.args...)
+		if code != exitOK {
+			t.Errorf("%v: expected %d, got %d: %s", tc.args, exitOK, code, errOut)
+			continue
+		}
+		if n := strings.Count(out, "\n"); n != tc.lines {
+			t.Errorf("%v: expected %d lines, got %d:\n%s", tc.args, tc.lines, n, out)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"forecast", "-type", "7", "Berlin"},
+		{"forecast", "-days", "6", "Berlin"},
+		{"forecast", "-type", "hourly", "-days", "3", "Berlin"},
+	} {
+		if code, _, _ := owmRun(t, srv, nil, args...); code != exitUsage {
+			t.Errorf("%v: expected %d, got %d", args, exitUsage, code)
+		}
+	}
+}
+
+// TestOneCallAndAlerts will verify the one call and alerts commands
+func TestOneCallAndAlerts(t *testing.T) {
+	t.Parallel()
+
+	srv := owmtest.NewServer()
+	defer srv.Close()
+
+	code, out, errOut := owmRun(t, srv, nil, "onecall", "Phoenix")
+	if code != exitOK || !strings.Contains(out, "America/Phoenix") || !strings.Contains(out, "Daily") || !strings.Contains(out, "Alert: Excessive Heat Warning") {
+		t.Errorf("unexpected one call output %d %q %q", code, out, errOut)
+	}
+
+	code, out, _ = owmRun(t, srv, nil, "onecall", "-exclude", "hourly,daily", "Phoenix")
+	if code != exitOK || strings.Contains(out, "Daily") || strings.Contains(out, "Hourly") {
+		t.Errorf("expected no hourly nor daily forecast, got %d %q", code, out)
+	}
+	if code, _, _ := owmRun(t, srv, nil, "onecall", "-exclude", "weekly", "Phoenix"); code != exitUsage {
+		t.Errorf("expected %d for an invalid exclude, got %d", exitUsage, code)
+	}
+
+	code, out, _ = owmRun(t, srv, nil, "alerts", "Phoenix")
+	if code != exitOK || !strings.HasPrefix(out, "Excessive Heat Warning by NWS Phoenix") {
+		t.Errorf("expected a heat warning, got %d %q", code, out)
+	}
+	code, out, _ = owmRun(t, srv, nil, "alerts", "Berlin")
+	if code != exitOK || out != "No active alerts in Europe/Berlin\n" {
+		t.Errorf("expected no alerts, got %d %q", code, out)
+	}
+	code, out, _ = owmRun(t, srv, nil, "-o", "json", "alerts", "Berlin")
+	if code != exitOK || !strings.Contains(out, `"alerts": []`) {
+		t.Errorf("expected an empty list of alerts, got %d %q", code, out)
+	}
+}
+
+// TestPollutionAndUV will verify the pollution and uv commands
+func TestPollutionAndUV(t *testing.T) {
+	t.Parallel()
+
+	srv := owmtest.NewServer()
+	defer srv.Close()
+
+	code, out, errOut := owmRun(t, srv, nil, "pollution", "Boston")
+	if code != exitOK || !strings.Contains(out, "Air quality: 1 (good)") {
+		t.Errorf("unexpected pollution output %d %q %q", code, out, errOut)
+	}
+
+	code, out, errOut = owmRun(t, srv, nil, "uv", "25.2582,55.3047")
+	if code != exitOK || !strings.Contains(out, "11.3 (Extreme)") {
+		t.Errorf("unexpected uv output %d %q %q", code, out, errOut)
+	}
+}
+
+// TestHistory will verify the history command with and without a range
+func TestHistory(t *testing.T) {
+	t.Parallel()
+
+	srv := owmtest.NewServer()
+	defer srv.Close()
+
+	code, out, errOut := owmRun(t, srv, nil, "history", "Berlin")
+	if code != exitOK || out == "" {
+		t.Errorf("unexpected history output %d %q %q", code, out, errOut)
+	}
+
+	end := owmtest.DefaultTime.Truncate(24 * time.Hour)
+	start := end.Add(-3 * time.Hour)
+	code, out, errOut = owmRun(t, srv, nil, "history", "-start", start.Format(time.RFC3339), "-end", end.Format(time.RFC3339), "Berlin")
+	if code != exitOK || strings.Count(out, "\n") != 4 {
+		t.Errorf("expected 4 hours, got %d %q %q", code, out, errOut)
+	}
+
+	for _, args := range [][]string{
+		{"history", "-start", "yesterday", "Berlin"},
+		{"history", "-start", "2022-01-02", "-end", "2022-01-01", "Berlin"},
+		{"history", "-end", "2022-01-01", "Berlin"},
+	} {
+		if code, _, _ := owmRun(t, srv, nil, args...); code != exitUsage {
+			t.Errorf("%v: expected %d, got %d", args, exitUsage, code)
+		}
+	}
+}
+
+// TestGeocode will verify the geocode command by name, zip code and
+// coordinates
+func TestGeocode(t *testing.T) {
+	t.Parallel()
+
+	srv := owmtest.NewServer()
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"geocode", "Philadelphia,PA,US"}, "Philadelphia, Pennsylvania, US  39.9523, -75.1638\n"},
+		{[]string{"geocode", "-zip", "02134,US"}, "Boston, US  42.3584, -71.0598\n"},
+		{[]string{"geocode", "-reverse", "-limit", "1", "52.5,13.4"}, "Berlin, DE  52.5244, 13.4105\n"},
+	} {
+		code, out, errOut := owmRun(t, srv, nil, tc.args...)
+		if code != exitOK || out != tc.want {
+			t.Errorf("%v: expected %q, got %d %q %q", tc.args, tc.want, code, out, errOut)
+		}
+	}
+
+	for _, tc := range []struct {
+		args []string
+		want int
+	}{
+		{[]string{"geocode", "nowhere_"}, exitNotFound},
+		{[]string{"geocode", "-zip", "00000,US"}, exitNotFound},
+		{[]string{"geocode", "-limit", "6", "Berlin"}, exitUsage},
+		{[]string{"geocode", "-reverse", "Berlin"}, exitUsage},
+		{[]string{"geocode"}, exitUsage},
+	} {
+		if code, _, _ := owmRun(t, srv, nil, tc.args...); code != tc.want {
+			t.Errorf("%v: expected %d, got %d", tc.args, tc.want, code)
+		}
+	}
+}
+
+// TestIcons will verify that icons are listed and downloaded
+func TestIcons(t *testing.T) {
+	t.Parallel()
+
+	srv := owmtest.NewServer()
+	defer srv.Close()
+
+	code, out, _ := owmRun(t, srv, map[string]string{"OWM_API_KEY": ""}, "icons")
+	if code != exitOK || !strings.Contains(out, "clear sky") {
+		t.Errorf("expected the icon list, got %d %q", code, out)
+	}
+
+	dir := t.TempDir()
+	code, out, errOut := owmRun(t, srv, nil, "icons", "-dir", dir, "-size", "2x", "01d", "10n")
+	if code != exitOK {
+		t.Fatalf("expected %d, got %d: %s", exitOK, code, errOut)
+	}
+	for _, name := range []string{"01d@2x.png", "10n@2x.png"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Error(err)
+		}
+		if !strings.Contains(out, name) {
+			t.Errorf("expected %s in %q", name, out)
+		}
+	}
+
+	if code, _, _ := owmRun(t, srv, nil, "icons", "-size", "3x", "01d"); code != exitUsage {
+		t.Errorf("expected %d for an invalid size, got %d", exitUsage, code)
+	}
+}
+
+// TestStations will verify that measurements are sent and aggregated
+// ones are retrieved
+func TestStations(t *testing.T) {
+	t.Parallel()
+
+	srv := owmtest.NewServer()
+	defer srv.Close()
+
+	code, out, errOut := owmRun(t, srv, nil, "stations", "send", "-station", "abc", "-temp", "21.5", "-dt", "1650000000")
+	if code != exitOK || !strings.Contains(out, "station abc") {
+		t.Errorf("unexpected send output %d %q %q", code, out, errOut)
+	}
+
+	code, out, errOut = owmRun(t, srv, nil, "stations", "get", "-station", "abc", "-type", "d", "-limit", "3")
+	if code != exitOK || strings.Count(out, "\n") != 3 {
+		t.Errorf("expected 3 measurements, got %d %q %q", code, out, errOut)
+	}
+
+	for _, args := range [][]string{
+		{"stations"},
+		{"stations", "send", "-temp", "20"},
+		{"stations", "send", "-station", "abc", "-humidity", "120"},
+		{"stations", "get", "-station", "abc", "-type", "w"},
+	} {
+		if code, _, _ := owmRun(t, srv, nil, args...); code != exitUsage {
+			t.Errorf("%v: expected %d, got %d", args, exitUsage, code)
+		}
+	}
+}
+
+// TestConfig will verify that YAML and JSON config files are loaded and
+// that flags and the environment take precedence
+func TestConfig(t *testing.T) {
+	t.Parallel()
+
+	srv := owmtest.NewServer()
+	defer srv.Close()
+	srv.RequireKey(testKey)
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.yaml": "api_key: " + testKey + "\nunit: F\nlocation: Helena\n",
+		"config.json": `{"api_key": "` + testKey + `", "output": "json", "location": "Moscow"}`,
+		"typo.yaml":   "api_kee: " + testKey + "\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	noKey := map[string]string{"OWM_API_KEY": ""}
+
+	code, out, errOut := owmRun(t, srv, noKey, "-config", filepath.Join(dir, "config.yaml"), "current")
+	if code != exitOK || !strings.Contains(out, "Helena, US") || !strings.Contains(out, "°F") {
+		t.Errorf("unexpected YAML config output %d %q %q", code, out, errOut)
+	}
+
+	code, out, errOut = owmRun(t, srv, noKey, "-config", filepath.Join(dir, "config.yaml"), "current", "-u", "C", "Boston")
+	if code != exitOK || !strings.Contains(out, "Boston, US") || !strings.Contains(out, "°C") {
+		t.Errorf("expected the flags to take precedence, got %d %q %q", code, out, errOut)
+	}
+
+	code, out, errOut = owmRun(t, srv, map[string]string{"OWM_API_KEY": "", "OWM_CONFIG": filepath.Join(dir, "config.json")}, "current")
+	if code != exitOK || !strings.Contains(out, `"name": "Moscow"`) {
+		t.Errorf("unexpected JSON config output %d %q %q", code, out, errOut)
+	}
+
+	code, _, _ = owmRun(t, srv, map[string]string{"OWM_API_KEY": "wrong"}, "-config", filepath.Join(dir, "config.yaml"), "current")
+	if code != exitInvalidKey {
+		t.Errorf("expected the environment to take precedence, got %d", code)
+	}
+
+	for _, name := range []string{"typo.yaml", "missing.yaml"} {
+		if code, _, _ := owmRun(t, srv, nil, "-config", filepath.Join(dir, name), "current", "Berlin"); code != exitError {
+			t.Errorf("%s: expected %d, got %d", name, exitError, code)
+		}
+	}
+}
+
+// TestOutputFormats will verify that results are written in each output
+// format
+func TestOutputFormats(t *testing.T) {
+	t.Parallel()
+
+	srv := owmtest.NewServer()
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-o", "table", "current", "Berlin"}, "NAME    COUNTRY  LAT"},
+		{[]string{"--output", "csv", "current", "Berlin"}, "name,country,lat,lon,time,"},
+		{[]string{"-o", "CSV", "forecast", "-type", "16", "-days", "1", "Berlin"}, "time,temp_day,temp_min,"},
+		{[]string{"-o", "ndjson", "onecall", "-exclude", "hourly,daily", "Berlin"}, `{"part":"current","time":"2024-05-13T12:00:00Z",`},
+		{[]string{"-o", "yaml", "current", "Berlin"}, "coord:\n  lon: 13.4105\n"},
+		{[]string{"-o", "json", "forecast", "-days", "1", "Berlin"}, `"dt_txt": "2024-05-13 15:00:00"`},
+		{[]string{"-o", "table", "alerts", "Phoenix"}, "SENDER_NAME  EVENT"},
+		{[]string{"-o", "template={{.Name}} {{temp .Main.Temp}}", "current", "Berlin"}, "Berlin 16.4°C\n"},
+	} {
+		code, out, errOut := owmRun(t, srv, nil, tc.args...)
+		if code != exitOK || !strings.Contains(out, tc.want) {
+			t.Errorf("%v: expected %q, got %d %q %q", tc.args, tc.want, code, out, errOut)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"-o", "template", "current", "Berlin"},
+		{"-o", "template={{.Name", "current", "Berlin"},
+	} {
+		if code, _, _ := owmRun(t, srv, nil, args...); code != exitUsage {
+			t.Errorf("%v: expected %d, got %d", args, exitUsage, code)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	owm "github.com/briandowns/openweathermap"
+	"github.com/briandowns/openweathermap/format"
+)
+
+// outputFormats are the formats of the -o flag. Text goes through the
+// template of the command, the others through the format package.
+var outputFormats = []string{"text", "table", "json", "ndjson", "csv", "yaml", "template=TEXT"}
+
+// templatePrefix starts the -o flag of a user template.
+const templatePrefix = "template="
+
+// normalizeOutput lower cases the -o flag, except for a user template.
+func normalizeOutput(output string) string {
+	if strings.HasPrefix(output, templatePrefix) {
+		return output
+	}
+	return strings.ToLower(output)
+}
+
+// validOutput makes sure the -o flag is one of outputFormats.
+func validOutput(output string) bool {
+	if output == "text" || strings.HasPrefix(output, templatePrefix) {
+		return true
+	}
+	f, err := format.ParseFormat(output)
+	return err == nil && f != format.Template
+}
+
+// aqiLevels are the names of the air quality indexes 1 to 5.
+var aqiLevels = []string{"good", "fair", "moderate", "poor", "very poor"}
+
+// symbols returns the temperature and speed symbols of the unit.
+func symbols(unit string) (string, string) {
+	switch unit {
+	case "F":
+		return "°F", "mph"
+	case "K":
+		return "K", "m/s"
+	}
+	return "°C", "m/s"
+}
+
+// describe joins the descriptions of the weather conditions.
+func describe(weather []owm.Weather) string {
+	d := make([]string, len(weather))
+	for i, w := range weather {
+		d[i] = w.Description
+	}
+	return strings.Join(d, ", ")
+}
+
+// funcs returns the functions of the text templates.
+func (a *app) funcs() template.FuncMap {
+	temp, speed := symbols(a.unit)
+	at := func(unix interface{}, offset int) time.Time {
+		var sec int64
+		switch v := unix.(type) {
+		case int:
+			sec = int64(v)
+		case int64:
+			sec = v
+		}
+		return time.Unix(sec, 0).In(time.FixedZone("", offset))
+	}
+	return template.FuncMap{
+		"temp":  func(v float64) string { return fmt.Sprintf("%.1f%s", v, temp) },
+		"speed": func(v float64) string { return fmt.Sprintf("%.1f %s", v, speed) },
+		"time":  func(unix interface{}, offset int) string { return at(unix, offset).Format("Mon Jan 2 15:04") },
+		"date":  func(unix interface{}, offset int) string { return at(unix, offset).Format("Mon Jan 2") },
+		"hour":  func(unix interface{}, offset int) string { return at(unix, offset).Format("15:04") },
+		"utc":   func(unix interface{}) string { return at(unix, 0).Format("Mon Jan 2 15:04 UTC") },
+		"compass": func(deg float64) string {
+			return owm.Wind{Deg: deg}.Cardinal16()
+		},
+		"desc": describe,
+		"pct":  func(v float64) string { return fmt.Sprintf("%.0f%%", v*100) },
+		"aqi": func(v float64) string {
+			if i := int(v); i >= 1 && i <= len(aqiLevels) {
+				return aqiLevels[i-1]
+			}
+			return "unknown"
+		},
+	}
+}
+
+// render writes the result in the format of the -o flag, the text one
+// going through the template of the command.
+func (a *app) render(v interface{}, text string) error {
+	f := &format.Formatter{Format: format.Template}
+	switch {
+	case a.output == "text":
+	case strings.HasPrefix(a.output, templatePrefix):
+		text = strings.TrimPrefix(a.output, templatePrefix)
+		if !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+	default:
+		f.Format, _ = format.ParseFormat(a.output)
+	}
+
+	if f.Format == format.Template {
+		t, err := template.New("output").Funcs(a.funcs()).Parse(text)
+		if err != nil {
+			return usagef("invalid template: %v", err)
+		}
+		f.Template = t
+	}
+	return f.Write(a.stdout, v)
+}
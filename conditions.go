@@ -16,9 +16,7 @@ package openweathermap
 
 import (
 	"fmt"
-	"io"
-	"net/http"
-	"os"
+	"path/filepath"
 )
 
 // IconData holds the relevant info for linking icons to conditions.
@@ -36,34 +34,145 @@ type ConditionData struct {
 	Icon2   string
 }
 
-// RetrieveIcon will get the specified icon from the API.
-func RetrieveIcon(destination, iconFile string) (int64, error) {
-	fullFilePath := fmt.Sprintf("%s/%s", destination, iconFile)
+// ConditionGroup is the category a weather condition code belongs to.
+type ConditionGroup int
 
-	// Check to see if we've already gotten that icon file.  If so, use it
-	// rather than getting it again.
-	if _, err := os.Stat(fullFilePath); err != nil {
-		response, err := http.Get(fmt.Sprintf(iconURL, iconFile))
-		if err != nil {
-			return 0, err
-		}
-		defer response.Body.Close()
+// Condition groups, following the hundreds of the condition codes.
+const (
+	GroupUnknown ConditionGroup = iota
+	GroupThunderstorm
+	GroupDrizzle
+	GroupRain
+	GroupSnow
+	GroupAtmosphere
+	GroupClear
+	GroupClouds
+	GroupExtreme
+	GroupAdditional
+)
 
-		// Create the icon file
-		out, err := os.Create(fullFilePath)
-		if err != nil {
-			return 0, err
-		}
-		defer out.Close()
+var conditionGroupNames = map[ConditionGroup]string{
+	GroupUnknown:      "Unknown",
+	GroupThunderstorm: "Thunderstorm",
+	GroupDrizzle:      "Drizzle",
+	GroupRain:         "Rain",
+	GroupSnow:         "Snow",
+	GroupAtmosphere:   "Atmosphere",
+	GroupClear:        "Clear",
+	GroupClouds:       "Clouds",
+	GroupExtreme:      "Extreme",
+	GroupAdditional:   "Additional",
+}
 
-		// Fill the empty file with the actual content
-		n, err := io.Copy(out, response.Body)
-		if err != nil {
-			return 0, err
+// String returns the name of the group.
+func (g ConditionGroup) String() string {
+	if n, ok := conditionGroupNames[g]; ok {
+		return n
+	}
+	return conditionGroupNames[GroupUnknown]
+}
+
+// conditionEntry is what the registry holds for each condition code.
+type conditionEntry struct {
+	data   *ConditionData
+	group  ConditionGroup
+	severe bool
+}
+
+// conditionRegistry indexes every known condition by its ID.
+var conditionRegistry = map[int]conditionEntry{}
+
+// severeConditions lists the condition codes considered hazardous.
+var severeConditions = []int{
+	202, 212, 221, // heavy and ragged thunderstorms
+	503, 504, 511, 522, // very heavy, extreme and freezing rain
+	602, 622, // heavy snow
+	762, 771, 781, // volcanic ash, squalls, tornado
+	900, 901, 902, 903, 904, 905, 906,
+	958, 959, 960, 961, 962, // gale and above
+}
+
+func init() {
+	groups := []struct {
+		group      ConditionGroup
+		conditions []*ConditionData
+	}{
+		{GroupThunderstorm, ThunderstormConditions},
+		{GroupDrizzle, DrizzleConditions},
+		{GroupRain, RainConditions},
+		{GroupSnow, SnowConditions},
+		{GroupAtmosphere, AtmosphereConditions},
+		{GroupClouds, CloudConditions},
+		{GroupExtreme, ExtremeConditions},
+		{GroupAdditional, AdditionalConditions},
+	}
+	for _, g := range groups {
+		for _, c := range g.conditions {
+			group := g.group
+			if c.ID == 800 {
+				group = GroupClear
+			}
+			conditionRegistry[c.ID] = conditionEntry{data: c, group: group}
 		}
-		return n, nil
 	}
-	return 0, nil
+	for _, id := range severeConditions {
+		e := conditionRegistry[id]
+		e.severe = true
+		conditionRegistry[id] = e
+	}
+}
+
+// LookupCondition returns the condition for the given ID. The bool is
+// false when the ID isn't a known condition code.
+func LookupCondition(id int) (ConditionData, bool) {
+	e, ok := conditionRegistry[id]
+	if !ok {
+		return ConditionData{}, false
+	}
+	return *e.data, true
+}
+
+// ConditionGroupOf returns the group of the given condition ID.
+func ConditionGroupOf(id int) ConditionGroup {
+	return conditionRegistry[id].group
+}
+
+// IsPrecipitation reports whether the condition ID describes falling
+// precipitation.
+func IsPrecipitation(id int) bool {
+	switch ConditionGroupOf(id) {
+	case GroupThunderstorm:
+		// 210 to 221 are thunderstorms without rain or drizzle
+		return id < 210 || id > 221
+	case GroupDrizzle, GroupRain, GroupSnow:
+		return true
+	}
+	return id == 906 // hail
+}
+
+// IsSevere reports whether the condition ID describes hazardous weather.
+func IsSevere(id int) bool {
+	return conditionRegistry[id].severe
+}
+
+// Group returns the group of the condition.
+func (c ConditionData) Group() ConditionGroup { return ConditionGroupOf(c.ID) }
+
+// IsPrecipitation reports whether the condition describes falling
+// precipitation.
+func (c ConditionData) IsPrecipitation() bool { return IsPrecipitation(c.ID) }
+
+// IsSevere reports whether the condition describes hazardous weather.
+func (c ConditionData) IsSevere() bool { return IsSevere(c.ID) }
+
+// RetrieveIcon will get the specified icon from the API. The icon is only
+// downloaded when it isn't already in the destination directory.
+func RetrieveIcon(destination, iconFile string, options ...Option) (int64, error) {
+	i, err := NewIcons(options...)
+	if err != nil {
+		return 0, err
+	}
+	return i.retrieve(filepath.Join(destination, iconFile), fmt.Sprintf(iconURL, iconFile))
 }
 
 // IconList is a slice of IconData pointers
@@ -151,10 +260,10 @@ var AtmosphereConditions = []*ConditionData{
 // CloudConditions is a slice of ConditionData pointers
 var CloudConditions = []*ConditionData{
 	{ID: 800, Meaning: "clear sky", Icon1: "01d.png", Icon2: "01n.png"},
-	{ID: 801, Meaning: "few clouds", Icon1: "02d.png", Icon2: " 02n.png"},
-	{ID: 802, Meaning: "scattered clouds", Icon1: "03d.png", Icon2: "03d.png"},
-	{ID: 803, Meaning: "broken clouds", Icon1: "04d.png", Icon2: "03d.png"},
-	{ID: 804, Meaning: "overcast clouds", Icon1: "04d.png", Icon2: "04d.png"},
+	{ID: 801, Meaning: "few clouds", Icon1: "02d.png", Icon2: "02n.png"},
+	{ID: 802, Meaning: "scattered clouds", Icon1: "03d.png", Icon2: "03n.png"},
+	{ID: 803, Meaning: "broken clouds", Icon1: "04d.png", Icon2: "04n.png"},
+	{ID: 804, Meaning: "overcast clouds", Icon1: "04d.png", Icon2: "04n.png"},
 }
 
 // ExtremeConditions is a slice of ConditionData pointers
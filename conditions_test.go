@@ -15,6 +15,7 @@
 package openweathermap
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"testing"
@@ -22,27 +23,125 @@ import (
 
 // TestRetrieveIcon will test the retrieval of icons from the API.
 func TestRetrieveIcon(t *testing.T) {
-	tmpDir := "/tmp"
-	iconFiles := []string{"01d.png", "n7m.png"}
+	t.Parallel()
 
-	for _, iconFile := range iconFiles {
-		size, err := RetrieveIcon(tmpDir, iconFile)
-		if err != nil {
-			t.Error(err)
-		}
+	srv, opt := newFakeServer()
+	defer srv.Close()
 
-		f, err := os.Stat(fmt.Sprintf("%s/%s", tmpDir, iconFile))
-		if err != nil {
-			t.Error(err)
-		}
+	tmpDir := t.TempDir()
 
-		if f.Size() != size {
-			t.Error("Size of downloaded file does not match actual size of file")
+	size, err := RetrieveIcon(tmpDir, "01d.png", opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Stat(fmt.Sprintf("%s/%s", tmpDir, "01d.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f.Size() != size {
+		t.Error("Size of downloaded file does not match actual size of file")
+	}
+
+	if _, err := RetrieveIcon(tmpDir, "n7m.png", opt); err == nil {
+		t.Error("expected an error retrieving an unknown icon")
+	}
+	if _, err := os.Stat(fmt.Sprintf("%s/%s", tmpDir, "n7m.png")); !os.IsNotExist(err) {
+		t.Error("expected no file for an unknown icon")
+	}
+}
+
+// TestLookupCondition will verify that every condition code can be found
+// in the registry
+func TestLookupCondition(t *testing.T) {
+	t.Parallel()
+
+	all := [][]*ConditionData{
+		ThunderstormConditions, DrizzleConditions, RainConditions,
+		SnowConditions, AtmosphereConditions, CloudConditions,
+		ExtremeConditions, AdditionalConditions,
+	}
+	for _, conditions := range all {
+		for _, c := range conditions {
+			got, ok := LookupCondition(c.ID)
+			if !ok {
+				t.Errorf("condition %d not found", c.ID)
+			}
+			if got.Meaning != c.Meaning {
+				t.Errorf("condition %d: expected %q, got %q", c.ID, c.Meaning, got.Meaning)
+			}
 		}
+	}
+
+	c, ok := LookupCondition(615)
+	if !ok || c.Meaning != "light rain and snow" {
+		t.Errorf("unexpected condition %+v", c)
+	}
 
-		err = os.Remove(fmt.Sprintf("%s/%s", tmpDir, iconFile))
-		if err != nil {
-			t.Error(err)
+	if _, ok := LookupCondition(999); ok {
+		t.Error("found unknown condition")
+	}
+}
+
+// TestConditionGroup will verify the classification of condition codes
+func TestConditionGroup(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		id            int
+		group         ConditionGroup
+		precipitation bool
+		severe        bool
+	}{
+		{200, GroupThunderstorm, true, false},
+		{212, GroupThunderstorm, false, true},
+		{301, GroupDrizzle, true, false},
+		{504, GroupRain, true, true},
+		{615, GroupSnow, true, false},
+		{741, GroupAtmosphere, false, false},
+		{781, GroupAtmosphere, false, true},
+		{800, GroupClear, false, false},
+		{804, GroupClouds, false, false},
+		{906, GroupExtreme, true, true},
+		{955, GroupAdditional, false, false},
+		{960, GroupAdditional, false, true},
+		{1, GroupUnknown, false, false},
+	}
+
+	for _, tt := range tests {
+		if g := ConditionGroupOf(tt.id); g != tt.group {
+			t.Errorf("%d: expected group %s, got %s", tt.id, tt.group, g)
+		}
+		if p := IsPrecipitation(tt.id); p != tt.precipitation {
+			t.Errorf("%d: expected precipitation %v, got %v", tt.id, tt.precipitation, p)
 		}
+		if s := IsSevere(tt.id); s != tt.severe {
+			t.Errorf("%d: expected severe %v, got %v", tt.id, tt.severe, s)
+		}
+	}
+
+	if GroupSnow.String() != "Snow" || ConditionGroup(42).String() != "Unknown" {
+		t.Error("unexpected group names")
+	}
+}
+
+// TestWeatherCondition will verify that the weather of a result can be
+// looked up
+func TestWeatherCondition(t *testing.T) {
+	t.Parallel()
+
+	var o OneCallData
+	err := json.Unmarshal([]byte(`{"current":{"weather":[{"id":503}]},"daily":[{"weather":[{"id":800}]}]}`), &o)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, ok := o.Current.Weather[0].Condition()
+	if !ok || c.Meaning != "very heavy rain" || !c.IsPrecipitation() || !c.IsSevere() {
+		t.Errorf("unexpected condition %+v", c)
+	}
+	if g := o.Daily[0].Weather[0].Group(); g != GroupClear {
+		t.Errorf("expected %s, got %s", GroupClear, g)
 	}
 }
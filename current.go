@@ -17,7 +17,7 @@ package openweathermap
 import (
 	"encoding/json"
 	"fmt"
-	"net/http"
+	"io"
 	"net/url"
 	"strings"
 )
@@ -25,24 +25,25 @@ import (
 // CurrentWeatherData struct contains an aggregate view of the structs
 // defined above for JSON to be unmarshaled into.
 type CurrentWeatherData struct {
-	GeoPos     Coordinates `json:"coord"`
-	Sys        Sys         `json:"sys"`
-	Base       string      `json:"base"`
-	Weather    []Weather   `json:"weather"`
-	Main       Main        `json:"main"`
-	Visibility int         `json:"visibility"`
-	Wind       Wind        `json:"wind"`
-	Clouds     Clouds      `json:"clouds"`
-	Rain       Rain        `json:"rain"`
-	Snow       Snow        `json:"snow"`
-	Dt         int         `json:"dt"`
-	ID         int         `json:"id"`
-	Name       string      `json:"name"`
-	Cod        int         `json:"cod"`
-	Timezone   int         `json:"timezone"`
-	Unit       string
-	Lang       string
-	Key        string
+	GeoPos       Coordinates `json:"coord"`
+	Sys          Sys         `json:"sys"`
+	Base         string      `json:"base"`
+	Weather      []Weather   `json:"weather"`
+	Main         Main        `json:"main"`
+	Visibility   float64     `json:"visibility"`
+	Wind         Wind        `json:"wind"`
+	Clouds       Clouds      `json:"clouds"`
+	Rain         Rain        `json:"rain"`
+	Snow         Snow        `json:"snow"`
+	Dt           int         `json:"dt"`
+	ID           int         `json:"id"`
+	Name         string      `json:"name"`
+	Cod          int         `json:"cod"`
+	Timezone     int         `json:"timezone"`
+	Unit         string
+	AppliedUnits *UnitPreferences `json:"-"` // units the values were converted to, if any
+	Lang         string
+	Key          string `json:"-"`
 	*Settings
 }
 
@@ -78,64 +79,65 @@ func NewCurrent(unit, lang, key string, options ...Option) (*CurrentWeatherData,
 	return c, nil
 }
 
+// decode replaces the result with the response, converted into the
+// preferred units, so nothing of a previous result is kept.
+func (w *CurrentWeatherData) decode(r io.Reader) error {
+	var data CurrentWeatherData
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return err
+	}
+	w.applyUnitPreferences(&data)
+	data.Unit, data.Lang, data.Key, data.Settings = w.Unit, w.Lang, w.Key, w.Settings
+	*w = data
+	return nil
+}
+
 // CurrentByName will provide the current weather with the provided
 // location name.
 func (w *CurrentWeatherData) CurrentByName(location string) error {
-	response, err := w.client.Get(fmt.Sprintf(fmt.Sprintf(baseURL, "appid=%s&q=%s&units=%s&lang=%s"), w.Key, url.QueryEscape(location), w.Unit, w.Lang))
+	response, err := w.client.Get(fmt.Sprintf(fmt.Sprintf(baseURL, "appid=%s&q=%s&units=%s&lang=%s"), w.Key, url.QueryEscape(location), w.requestUnit(w.Unit), w.Lang))
 	if err != nil {
 		return err
 	}
 	defer response.Body.Close()
 
-	if response.StatusCode == http.StatusUnauthorized {
-		return errInvalidKey
-	}
-
-	if err := json.NewDecoder(response.Body).Decode(&w); err != nil {
+	if err := checkResponse(response); err != nil {
 		return err
 	}
 
-	return nil
+	return w.decode(response.Body)
 }
 
 // CurrentByCoordinates will provide the current weather with the
 // provided location coordinates.
 func (w *CurrentWeatherData) CurrentByCoordinates(location *Coordinates) error {
-	response, err := w.client.Get(fmt.Sprintf(fmt.Sprintf(baseURL, "appid=%s&lat=%f&lon=%f&units=%s&lang=%s"), w.Key, location.Latitude, location.Longitude, w.Unit, w.Lang))
+	response, err := w.client.Get(fmt.Sprintf(fmt.Sprintf(baseURL, "appid=%s&lat=%f&lon=%f&units=%s&lang=%s"), w.Key, location.Latitude, location.Longitude, w.requestUnit(w.Unit), w.Lang))
 	if err != nil {
 		return err
 	}
 	defer response.Body.Close()
 
-	if response.StatusCode == http.StatusUnauthorized {
-		return errInvalidKey
-	}
-
-	if err = json.NewDecoder(response.Body).Decode(&w); err != nil {
+	if err := checkResponse(response); err != nil {
 		return err
 	}
 
-	return nil
+	return w.decode(response.Body)
 }
 
 // CurrentByID will provide the current weather with the
 // provided location ID.
 func (w *CurrentWeatherData) CurrentByID(id int) error {
-	response, err := w.client.Get(fmt.Sprintf(fmt.Sprintf(baseURL, "appid=%s&id=%d&units=%s&lang=%s"), w.Key, id, w.Unit, w.Lang))
+	response, err := w.client.Get(fmt.Sprintf(fmt.Sprintf(baseURL, "appid=%s&id=%d&units=%s&lang=%s"), w.Key, id, w.requestUnit(w.Unit), w.Lang))
 	if err != nil {
 		return err
 	}
 	defer response.Body.Close()
 
-	if response.StatusCode == http.StatusUnauthorized {
-		return errInvalidKey
-	}
-
-	if err = json.NewDecoder(response.Body).Decode(&w); err != nil {
+	if err := checkResponse(response); err != nil {
 		return err
 	}
 
-	return nil
+	return w.decode(response.Body)
 }
 
 // CurrentByZip will provide the current weather for the
@@ -143,29 +145,33 @@ func (w *CurrentWeatherData) CurrentByID(id int) error {
 //
 // Deprecated: Use CurrentByZipcode instead.
 func (w *CurrentWeatherData) CurrentByZip(zip int, countryCode string) error {
-	response, err := w.client.Get(fmt.Sprintf(fmt.Sprintf(baseURL, "appid=%s&zip=%05d,%s&units=%s&lang=%s"), w.Key, zip, countryCode, w.Unit, w.Lang))
+	response, err := w.client.Get(fmt.Sprintf(fmt.Sprintf(baseURL, "appid=%s&zip=%05d,%s&units=%s&lang=%s"), w.Key, zip, countryCode, w.requestUnit(w.Unit), w.Lang))
 	if err != nil {
 		return err
 	}
 	defer response.Body.Close()
 
-	return json.NewDecoder(response.Body).Decode(&w)
+	if err := checkResponse(response); err != nil {
+		return err
+	}
+
+	return w.decode(response.Body)
 }
 
 // CurrentByZipcode will provide the current weather for the
 // provided zip code.
 func (w *CurrentWeatherData) CurrentByZipcode(zip string, countryCode string) error {
-	response, err := w.client.Get(fmt.Sprintf(fmt.Sprintf(baseURL, "appid=%s&zip=%s,%s&units=%s&lang=%s"), w.Key, zip, countryCode, w.Unit, w.Lang))
+	response, err := w.client.Get(fmt.Sprintf(fmt.Sprintf(baseURL, "appid=%s&zip=%s,%s&units=%s&lang=%s"), w.Key, zip, countryCode, w.requestUnit(w.Unit), w.Lang))
 	if err != nil {
 		return err
 	}
 	defer response.Body.Close()
 
-	if response.StatusCode == http.StatusUnauthorized {
-		return errInvalidKey
+	if err := checkResponse(response); err != nil {
+		return err
 	}
 
-	return json.NewDecoder(response.Body).Decode(&w)
+	return w.decode(response.Body)
 }
 
 // CurrentByArea will provide the current weather for the
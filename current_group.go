@@ -3,7 +3,6 @@ package openweathermap
 import (
 	"encoding/json"
 	"fmt"
-	"net/http"
 	"strconv"
 	"strings"
 )
@@ -20,7 +19,7 @@ type CurrentWeatherGroup struct {
 
 	Unit string
 	Lang string
-	Key  string
+	Key  string `json:"-"`
 
 	*Settings
 }
@@ -73,26 +72,29 @@ func (g *CurrentWeatherGroup) CurrentByIDs(ids ...int) error {
 	id := strings.Join(strIDs, ",")
 	uri := fmt.Sprintf(groupURL, "appid=%s&id=%s&units=%s&lang=%s")
 
-	response, err := g.client.Get(fmt.Sprintf(uri, g.Key, id, g.Unit, g.Lang))
+	response, err := g.client.Get(fmt.Sprintf(uri, g.Key, id, g.requestUnit(g.Unit), g.Lang))
 	if err != nil {
 		return err
 	}
 	defer response.Body.Close()
 
-	if response.StatusCode == http.StatusUnauthorized {
-		return errInvalidKey
+	if err := checkResponse(response); err != nil {
+		return err
 	}
 
-	if err = json.NewDecoder(response.Body).Decode(&g); err != nil {
+	var data CurrentWeatherGroup
+	if err = json.NewDecoder(response.Body).Decode(&data); err != nil {
 		return err
 	}
 
-	for _, w := range g.List {
+	for _, w := range data.List {
 		w.Settings = g.Settings
 		w.Unit = g.Unit
 		w.Lang = g.Lang
 		w.Key = g.Key
+		g.applyUnitPreferences(w)
 	}
+	g.Count, g.List = data.Count, data.List
 
 	return nil
 }
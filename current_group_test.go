@@ -1,7 +1,6 @@
 package openweathermap
 
 import (
-	"os"
 	"testing"
 )
 
@@ -9,9 +8,13 @@ import (
 // a given by location ids
 func TestCurrentByIDs(t *testing.T) {
 	t.Parallel()
-	g, err := NewCurrentGroup("c", "RU", os.Getenv("OWM_API_KEY"))
+
+	srv, opt := newFakeServer()
+	defer srv.Close()
+
+	g, err := NewCurrentGroup("c", "RU", "key", opt)
 	if err != nil {
-		t.Error("Error creating instance of CurrentWeatherData")
+		t.Fatal("Error creating instance of CurrentWeatherData")
 	}
 
 	// testing error
@@ -25,7 +28,7 @@ func TestCurrentByIDs(t *testing.T) {
 	}
 
 	// test receiving real data
-	err = g.CurrentByIDs(5344157, 5391811, 4560349)
+	err = g.CurrentByIDs(5368361, 5391811, 4560349)
 	if err != nil {
 		t.Fatalf("getting list by ids of current waeather failed: %s", err)
 	}
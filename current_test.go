@@ -15,11 +15,13 @@
 package openweathermap
 
 import (
+	"errors"
 	"net/http"
-	"os"
 	"reflect"
 	"testing"
 	"time"
+
+	"github.com/briandowns/openweathermap/owmtest"
 )
 
 // currentWeather holds the query and response
@@ -47,12 +49,12 @@ func TestNewCurrent(t *testing.T) {
 		t.Logf("Data unit: %s", d)
 
 		if ValidDataUnit(d) {
-			c, err := NewCurrent(d, "en", os.Getenv("OWM_API_KEY"))
+			c, err := NewCurrent(d, "en", "key")
 			if err != nil {
 				t.Error(err)
 			}
 
-			if _, err := NewCurrent(d, "blah", os.Getenv("OWM_API_KEY")); err != nil {
+			if _, err := NewCurrent(d, "blah", "key"); err != nil {
 				t.Log("received expected bad language code error")
 			}
 
@@ -70,7 +72,7 @@ func TestNewCurrent(t *testing.T) {
 func TestNewCurrentWithCustomHttpClient(t *testing.T) {
 	hc := http.DefaultClient
 	hc.Timeout = time.Duration(1) * time.Second
-	c, err := NewCurrent("c", "en", os.Getenv("OWM_API_KEY"), WithHttpClient(hc))
+	c, err := NewCurrent("c", "en", "key", WithHttpClient(hc))
 	if err != nil {
 		t.Error(err)
 	}
@@ -96,7 +98,7 @@ func TestNewCurrentWithInvalidOptions(t *testing.T) {
 	}
 
 	for _, options := range optionsPattern {
-		c, err := NewCurrent("c", "en", os.Getenv("OWM_API_KEY"), options...)
+		c, err := NewCurrent("c", "en", "key", options...)
 		if err == errInvalidOption {
 			t.Logf("Received expected invalid option error. message: %s", err.Error())
 		} else if err != nil {
@@ -112,7 +114,7 @@ func TestNewCurrentWithInvalidOptions(t *testing.T) {
 // invalid http client
 func TestNewCurrentWithInvalidHttpClient(t *testing.T) {
 
-	c, err := NewCurrent("c", "en", os.Getenv("OWM_API_KEY"), WithHttpClient(nil))
+	c, err := NewCurrent("c", "en", "key", WithHttpClient(nil))
 	if err == errInvalidHttpClient {
 		t.Logf("Received expected bad client error. message: %s", err.Error())
 	} else if err != nil {
@@ -128,6 +130,9 @@ func TestNewCurrentWithInvalidHttpClient(t *testing.T) {
 func TestCurrentByName(t *testing.T) {
 	t.Parallel()
 
+	srv, opt := newFakeServer()
+	defer srv.Close()
+
 	testCities := []currentWeather{
 		{
 			query: "Philadelphia",
@@ -173,30 +178,31 @@ func TestCurrentByName(t *testing.T) {
 
 	testBadCities := []string{"nowhere_", "somewhere_over_the_"}
 
-	c, err := NewCurrent("f", "ru", os.Getenv("OWM_API_KEY"))
+	c, err := NewCurrent("f", "ru", "key", opt)
 	if err != nil {
-		t.Error(err)
+		t.Fatal(err)
 	}
 
 	for _, city := range testCities {
-		c.CurrentByName(city.query)
+		if err := c.CurrentByName(city.query); err != nil {
+			t.Error(err)
+		}
 
-		if os.Getenv("RTCP_HOST") != "" {
-			if c.ID != city.weather.ID {
-				t.Errorf("Excpect CityID %d, got %d", city.weather.ID, c.ID)
-			}
-			if c.Name != city.weather.Name {
-				t.Errorf("Excpect City %s, got %s", city.weather.Name, c.Name)
-			}
-			if c.Main.Temp != city.weather.Main.Temp {
-				t.Errorf("Excpect Temp %.2f, got %.2f", city.weather.Main.Temp, c.Main.Temp)
-			}
+		if c.ID != city.weather.ID {
+			t.Errorf("Excpect CityID %d, got %d", city.weather.ID, c.ID)
+		}
+		if c.Name != city.weather.Name {
+			t.Errorf("Excpect City %s, got %s", city.weather.Name, c.Name)
+		}
+		if c.Main.Temp != city.weather.Main.Temp {
+			t.Errorf("Excpect Temp %.2f, got %.2f", city.weather.Main.Temp, c.Main.Temp)
 		}
 	}
 
 	for _, badCity := range testBadCities {
-		if err := c.CurrentByName(badCity); err != nil {
-			t.Log("received expected failure for bad city by name")
+		var apiErr *APIError
+		if err := c.CurrentByName(badCity); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+			t.Errorf("expected a 404 for bad city %s, got %v", badCity, err)
 		}
 	}
 }
@@ -205,31 +211,56 @@ func TestCurrentByName(t *testing.T) {
 // given set of coordinates
 func TestCurrentByCoordinates(t *testing.T) {
 	t.Parallel()
-	c, err := NewCurrent("f", "DE", os.Getenv("OWM_API_KEY"))
+
+	srv, opt := newFakeServer()
+	defer srv.Close()
+
+	c, err := NewCurrent("f", "DE", "key", opt)
 	if err != nil {
-		t.Error("Error creating instance of CurrentWeatherData")
+		t.Fatal("Error creating instance of CurrentWeatherData")
 	}
-	c.CurrentByCoordinates(
+	err = c.CurrentByCoordinates(
 		&Coordinates{
 			Longitude: -112.07,
 			Latitude:  33.45,
 		},
 	)
+	if err != nil {
+		t.Error(err)
+	}
+	if c.Name != "Phoenix" || c.GeoPos.Latitude != 33.4484 {
+		t.Errorf("expected Phoenix, got %s at %+v", c.Name, c.GeoPos)
+	}
+	if q := srv.Requests()[0].Query; q.Get("units") != "imperial" || q.Get("lang") != "DE" {
+		t.Errorf("unexpected query %v", q)
+	}
 }
 
 // TestCurrentByID will verify that current data can be retrieved for a given
 // location id
 func TestCurrentByID(t *testing.T) {
 	t.Parallel()
-	c, err := NewCurrent("c", "ZH", os.Getenv("OWM_API_KEY"))
+
+	srv, opt := newFakeServer()
+	defer srv.Close()
+
+	c, err := NewCurrent("c", "ZH_CN", "key", opt)
 	if err != nil {
-		t.Error("Error creating instance of CurrentWeatherData")
+		t.Fatal("Error creating instance of CurrentWeatherData")
+	}
+	if err := c.CurrentByID(5368361); err != nil {
+		t.Error(err)
+	}
+	if c.Name != "Los Angeles" || c.Main.Temp != 18.33 || len(c.Weather) != 1 || c.Weather[0].ID != 721 {
+		t.Errorf("unexpected weather %+v", c)
 	}
-	c.CurrentByID(5344157)
 }
 
 func TestCurrentByZip(t *testing.T) {
-	w, err := NewCurrent("F", "EN", os.Getenv("OWM_API_KEY"))
+	srv, opt := newFakeServer()
+	defer srv.Close()
+
+	w, err := NewCurrent("F", "EN", "key", opt)
 	if err != nil {
 		t.Error(err)
 	}
@@ -237,15 +268,24 @@ func TestCurrentByZip(t *testing.T) {
 	if err := w.CurrentByZip(19125, "US"); err != nil {
 		t.Error(err)
 	}
+	if w.Name != "Philadelphia" {
+		t.Errorf("expected Philadelphia, got %s", w.Name)
+	}
 
 	// 02134 is a valid zip code in the US
 	if err := w.CurrentByZip(2134, "US"); err != nil {
 		t.Error(err)
 	}
+	if w.Name != "Boston" {
+		t.Errorf("expected Boston, got %s", w.Name)
+	}
 }
 
 func TestCurrentByZipcode(t *testing.T) {
-	w, err := NewCurrent("F", "EN", os.Getenv("OWM_API_KEY"))
+	srv, opt := newFakeServer()
+	defer srv.Close()
+
+	w, err := NewCurrent("F", "EN", "key", opt)
 	if err != nil {
 		t.Error(err)
 	}
@@ -253,6 +293,37 @@ func TestCurrentByZipcode(t *testing.T) {
 	if err := w.CurrentByZipcode("19125", "US"); err != nil {
 		t.Error(err)
 	}
+	if w.Name != "Philadelphia" {
+		t.Errorf("expected Philadelphia, got %s", w.Name)
+	}
+}
+
+// TestCurrentFaults will verify that invalid keys and malformed responses
+// are reported
+func TestCurrentFaults(t *testing.T) {
+	t.Parallel()
+
+	srv, opt := newFakeServer()
+	defer srv.Close()
+
+	w, err := NewCurrent("C", "EN", "key", opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv.Inject("/weather", owmtest.Fault{Status: http.StatusUnauthorized, Times: 1})
+	if err := w.CurrentByID(2950159); err != errInvalidKey {
+		t.Errorf("expected %v, got %v", errInvalidKey, err)
+	}
+
+	srv.Inject("/weather", owmtest.Fault{Malformed: true, Times: 1})
+	if err := w.CurrentByID(2950159); err == nil {
+		t.Error("expected malformed JSON to fail")
+	}
+
+	if err := w.CurrentByID(2950159); err != nil || w.Name != "Berlin" {
+		t.Errorf("expected Berlin once the faults wore off, got %v", err)
+	}
 }
 
 func TestCurrentByArea(t *testing.T) {}
@@ -0,0 +1,121 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"github.com/briandowns/openweathermap/meteo"
+	"github.com/briandowns/openweathermap/units"
+)
+
+// Derived holds the values computed from the temperature, humidity and
+// wind of a result. They're in the temperature unit of the result. See
+// the meteo package for the formulas used.
+type Derived struct {
+	HeatIndex           units.Temperature
+	WindChill           units.Temperature
+	Humidex             units.Temperature
+	ApparentTemperature units.Temperature
+	WetBulb             units.Temperature
+}
+
+// newDerived computes the derived values.
+func newDerived(t units.Temperature, rh float64, wind units.Speed) Derived {
+	return Derived{
+		HeatIndex:           meteo.HeatIndex(t, rh),
+		WindChill:           meteo.WindChill(t, wind),
+		Humidex:             meteo.Humidex(t, rh),
+		ApparentTemperature: meteo.ApparentTemperature(t, rh, wind),
+		WetBulb:             meteo.WetBulb(t, rh),
+	}
+}
+
+// Derived returns the values computed from the current temperature,
+// humidity and wind.
+func (w *CurrentWeatherData) Derived() Derived {
+	q := w.Quantities()
+	return newDerived(q.Temperature, float64(w.Main.Humidity), q.WindSpeed)
+}
+
+// Derived returns the values computed from the entry's temperature,
+// humidity and wind, given in the units of the system.
+func (f Forecast5WeatherList) Derived(s units.System) Derived {
+	q := f.Quantities(s)
+	return newDerived(q.Temperature, float64(f.Main.Humidity), q.WindSpeed)
+}
+
+// Derived returns the values computed from the hour's temperature,
+// humidity and wind, given in the units of the system.
+func (h OneCallHourlyData) Derived(s units.System) Derived {
+	q := h.Quantities(s)
+	return newDerived(q.Temperature, float64(h.Humidity), q.WindSpeed)
+}
+
+// Moisture holds the moisture of the air computed from the temperature
+// and relative humidity of a result. Temperatures are in the unit of the
+// result.
+type Moisture struct {
+	RelativeHumidity float64 // %
+	AbsoluteHumidity float64 // g/m³
+	DewPoint         units.Temperature
+	FrostPoint       units.Temperature
+	VaporPressure    units.Pressure
+}
+
+// newMoisture computes the moisture of the air.
+func newMoisture(t units.Temperature, rh float64) Moisture {
+	return Moisture{
+		RelativeHumidity: rh,
+		AbsoluteHumidity: meteo.AbsoluteHumidity(t, rh),
+		DewPoint:         meteo.DewPoint(t, rh),
+		FrostPoint:       meteo.FrostPoint(t, rh),
+		VaporPressure:    meteo.VaporPressure(t, rh),
+	}
+}
+
+// Moisture returns the moisture of the air computed from the current
+// temperature and humidity.
+func (w *CurrentWeatherData) Moisture() Moisture {
+	return newMoisture(w.Quantities().Temperature, float64(w.Main.Humidity))
+}
+
+// Moisture returns the moisture of the air computed from the entry's
+// temperature and humidity, given in the units of the system.
+func (f Forecast5WeatherList) Moisture(s units.System) Moisture {
+	return newMoisture(f.Quantities(s).Temperature, float64(f.Main.Humidity))
+}
+
+// Moisture returns the moisture of the air computed from the current
+// temperature and humidity, given in the units of the system.
+func (c OneCallCurrentData) Moisture(s units.System) Moisture {
+	return newMoisture(c.Quantities(s).Temperature, float64(c.Humidity))
+}
+
+// Moisture returns the moisture of the air computed from the hour's
+// temperature and humidity, given in the units of the system.
+func (h OneCallHourlyData) Moisture(s units.System) Moisture {
+	return newMoisture(h.Quantities(s).Temperature, float64(h.Humidity))
+}
+
+// Moisture returns the moisture of the air computed from the day's
+// temperature and humidity, given in the units of the system.
+func (f Forecast16WeatherList) Moisture(s units.System) Moisture {
+	return newMoisture(f.Quantities(s).Temperature, float64(f.Humidity))
+}
+
+// Moisture returns the moisture of the air computed from the day's
+// temperature and humidity, given in the units of the system.
+func (d OneCallDailyData) Moisture(s units.System) Moisture {
+	return newMoisture(d.Quantities(s).Temperature, float64(d.Humidity))
+}
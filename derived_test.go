@@ -0,0 +1,106 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"math"
+	"testing"
+
+	"github.com/briandowns/openweathermap/units"
+)
+
+// TestCurrentDerived will verify that derived values use the units of
+// the result
+func TestCurrentDerived(t *testing.T) {
+	t.Parallel()
+
+	w := &CurrentWeatherData{
+		Main: Main{Temp: 90, Humidity: 50},
+		Wind: Wind{Speed: 5},
+		Unit: DataUnits["F"],
+	}
+
+	d := w.Derived()
+	if d.HeatIndex.Unit != units.Fahrenheit || math.Round(d.HeatIndex.Value) != 95 {
+		t.Errorf("unexpected heat index %v", d.HeatIndex)
+	}
+	if d.WindChill.Value != 90 {
+		t.Errorf("expected wind chill to be the air temperature, got %v", d.WindChill)
+	}
+
+	w = &CurrentWeatherData{
+		Main: Main{Temp: 253.15, Humidity: 70},
+		Wind: Wind{Speed: 30 / 3.6},
+		Unit: DataUnits["K"],
+	}
+	if c := w.Derived().WindChill.Celsius(); math.Round(c) != -33 {
+		t.Errorf("expected -33°C, got %v", c)
+	}
+}
+
+// TestListDerived will verify the derived values of forecast entries and
+// one call hours
+func TestListDerived(t *testing.T) {
+	t.Parallel()
+
+	f := Forecast5WeatherList{Main: Main{Temp: 20, Humidity: 50}}
+	if wb := f.Derived(units.Metric).WetBulb; math.Abs(wb.Value-13.7) > 0.05 {
+		t.Errorf("expected 13.7°C, got %v", wb)
+	}
+
+	h := OneCallHourlyData{Temp: 30, Humidity: 70}
+	if hx := h.Derived(units.Metric).Humidex; math.Round(hx.Value) != 41 {
+		t.Errorf("expected 41, got %v", hx)
+	}
+}
+
+// TestMoisture will verify that every endpoint computes the same moisture
+// for the same conditions
+func TestMoisture(t *testing.T) {
+	t.Parallel()
+
+	w := &CurrentWeatherData{Main: Main{Temp: 68, Humidity: 50}, Unit: DataUnits["F"]}
+	m := w.Moisture()
+	if m.DewPoint.Unit != units.Fahrenheit || math.Abs(m.DewPoint.Celsius()-9.27) > 0.01 {
+		t.Errorf("unexpected dew point %v", m.DewPoint)
+	}
+	if q := w.Quantities(); q.DewPoint != m.DewPoint {
+		t.Errorf("expected the quantities dew point to be %v, got %v", m.DewPoint, q.DewPoint)
+	}
+
+	f := Forecast5WeatherList{Main: Main{Temp: 20, Humidity: 50}}
+	c := OneCallCurrentData{Temp: 20, Humidity: 50}
+	h := OneCallHourlyData{Temp: 20, Humidity: 50}
+	d := OneCallDailyData{Temp: Temperature{Day: 20}, Humidity: 50}
+	f16 := Forecast16WeatherList{Temp: Temperature{Day: 20}, Humidity: 50}
+	for _, got := range []Moisture{
+		f.Moisture(units.Metric), c.Moisture(units.Metric), h.Moisture(units.Metric),
+		d.Moisture(units.Metric), f16.Moisture(units.Metric),
+	} {
+		if math.Abs(got.DewPoint.Value-9.27) > 0.01 || math.Abs(got.AbsoluteHumidity-8.64) > 0.01 {
+			t.Errorf("unexpected moisture %+v", got)
+		}
+		if got.RelativeHumidity != 50 || got.FrostPoint != got.DewPoint {
+			t.Errorf("unexpected moisture %+v", got)
+		}
+	}
+
+	if q := (Forecast5WeatherList{Main: Main{Temp: 20}}).Quantities(units.Metric); q.DewPoint.Value != 0 {
+		t.Errorf("expected no dew point without humidity, got %v", q.DewPoint)
+	}
+	if q := f16.Quantities(units.Metric); math.Abs(q.DewPoint.Value-9.27) > 0.01 {
+		t.Errorf("expected the daily dew point to be computed, got %v", q.DewPoint)
+	}
+}
@@ -47,6 +47,7 @@ type City struct {
 	Sys        ForecastSys `json:"sys"`
 }
 
+// ForecastWeather is the forecast API, implemented by ForecastWeatherData.
 type ForecastWeather interface {
 	DailyByName(location string, days int) error
 	DailyByCoordinates(location *Coordinates, days int) error
@@ -62,7 +63,7 @@ type ForecastWeatherJson interface {
 type ForecastWeatherData struct {
 	Unit    string
 	Lang    string
-	Key     string
+	Key     string `json:"-"`
 	baseURL string
 	*Settings
 	ForecastWeatherJson
@@ -105,71 +106,103 @@ func NewForecast(forecastType, unit, lang, key string, options ...Option) (*Fore
 
 	if forecastType == "16" {
 		forecastData.baseURL = forecast16Base
-		forecastData.ForecastWeatherJson = &Forecast16WeatherData{}
+		forecastData.ForecastWeatherJson = &Forecast16WeatherData{Unit: forecastData.Unit}
 	} else {
 		forecastData.baseURL = forecast5Base
-		forecastData.ForecastWeatherJson = &Forecast5WeatherData{}
+		forecastData.ForecastWeatherJson = &Forecast5WeatherData{Unit: forecastData.Unit}
 	}
 
 	return &forecastData, nil
 }
 
+// decode unmarshals the response into the forecast and applies the unit
+// preferences when set.
+func (f *ForecastWeatherData) decode(r io.Reader) error {
+	if err := f.ForecastWeatherJson.Decode(r); err != nil {
+		return err
+	}
+	if n, ok := f.ForecastWeatherJson.(unitNormalizer); ok {
+		f.applyUnitPreferences(n)
+	}
+	return nil
+}
+
 // DailyByName will provide a forecast for the location given for the
 // number of days given.
 func (f *ForecastWeatherData) DailyByName(location string, days int) error {
-	response, err := f.client.Get(fmt.Sprintf(f.baseURL, f.Key, fmt.Sprintf("%s=%s", "q", url.QueryEscape(location)), f.Unit, f.Lang, days))
+	response, err := f.client.Get(fmt.Sprintf(f.baseURL, f.Key, fmt.Sprintf("%s=%s", "q", url.QueryEscape(location)), f.requestUnit(f.Unit), f.Lang, days))
 	if err != nil {
 		return err
 	}
 	defer response.Body.Close()
 
-	return f.ForecastWeatherJson.Decode(response.Body)
+	if err := checkResponse(response); err != nil {
+		return err
+	}
+
+	return f.decode(response.Body)
 }
 
 // DailyByCoordinates will provide a forecast for the coordinates ID give
 // for the number of days given.
 func (f *ForecastWeatherData) DailyByCoordinates(location *Coordinates, days int) error {
-	response, err := f.client.Get(fmt.Sprintf(f.baseURL, f.Key, fmt.Sprintf("lat=%f&lon=%f", location.Latitude, location.Longitude), f.Unit, f.Lang, days))
+	response, err := f.client.Get(fmt.Sprintf(f.baseURL, f.Key, fmt.Sprintf("lat=%f&lon=%f", location.Latitude, location.Longitude), f.requestUnit(f.Unit), f.Lang, days))
 	if err != nil {
 		return err
 	}
 	defer response.Body.Close()
 
-	return f.ForecastWeatherJson.Decode(response.Body)
+	if err := checkResponse(response); err != nil {
+		return err
+	}
+
+	return f.decode(response.Body)
 }
 
 // DailyByID will provide a forecast for the location ID give for the
 // number of days given.
 func (f *ForecastWeatherData) DailyByID(id, days int) error {
-	response, err := f.client.Get(fmt.Sprintf(f.baseURL, f.Key, fmt.Sprintf("%s=%s", "id", strconv.Itoa(id)), f.Unit, f.Lang, days))
+	response, err := f.client.Get(fmt.Sprintf(f.baseURL, f.Key, fmt.Sprintf("%s=%s", "id", strconv.Itoa(id)), f.requestUnit(f.Unit), f.Lang, days))
 	if err != nil {
 		return err
 	}
 	defer response.Body.Close()
 
-	return f.ForecastWeatherJson.Decode(response.Body)
+	if err := checkResponse(response); err != nil {
+		return err
+	}
+
+	return f.decode(response.Body)
 }
 
 // DailyByZip will provide a forecast for the provided zip code.
 //
 // Deprecated: use DailyByZipcode instead.
 func (f *ForecastWeatherData) DailyByZip(zip int, countryCode string, days int) error {
-	response, err := f.client.Get(fmt.Sprintf(f.baseURL, f.Key, fmt.Sprintf("zip=%05d,%s", zip, countryCode), f.Unit, f.Lang, days))
+	response, err := f.client.Get(fmt.Sprintf(f.baseURL, f.Key, fmt.Sprintf("zip=%05d,%s", zip, countryCode), f.requestUnit(f.Unit), f.Lang, days))
 	if err != nil {
 		return err
 	}
 	defer response.Body.Close()
 
-	return f.ForecastWeatherJson.Decode(response.Body)
+	if err := checkResponse(response); err != nil {
+		return err
+	}
+
+	return f.decode(response.Body)
 }
 
 // DailyByZipcode will provide a forecast for the provided zip code.
 func (f *ForecastWeatherData) DailyByZipcode(zip string, countryCode string, days int) error {
-	response, err := f.client.Get(fmt.Sprintf(f.baseURL, f.Key, fmt.Sprintf("zip=%s,%s", zip, countryCode), f.Unit, f.Lang, days))
+	response, err := f.client.Get(fmt.Sprintf(f.baseURL, f.Key, fmt.Sprintf("zip=%s,%s", zip, countryCode), f.requestUnit(f.Unit), f.Lang, days))
 	if err != nil {
 		return err
 	}
 	defer response.Body.Close()
 
-	return f.ForecastWeatherJson.Decode(response.Body)
+	if err := checkResponse(response); err != nil {
+		return err
+	}
+
+	return f.decode(response.Body)
 }
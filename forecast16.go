@@ -14,6 +14,7 @@ type Forecast16WeatherList struct {
 	Weather  []Weather   `json:"weather"`
 	Speed    float64     `json:"speed"`
 	Deg      int         `json:"deg"`
+	Gust     float64     `json:"gust"`
 	Clouds   int         `json:"clouds"`
 	Snow     float64     `json:"snow"`
 	Rain     float64     `json:"rain"`
@@ -21,16 +22,22 @@ type Forecast16WeatherList struct {
 
 // Forecast16WeatherData will hold returned data from queries
 type Forecast16WeatherData struct {
-	COD     int                     `json:"cod"`
-	Message string                  `json:"message"`
-	City    City                    `json:"city"`
-	Cnt     int                     `json:"cnt"`
-	List    []Forecast16WeatherList `json:"list"`
+	COD          int                     `json:"cod"`
+	Message      string                  `json:"message"`
+	City         City                    `json:"city"`
+	Cnt          int                     `json:"cnt"`
+	List         []Forecast16WeatherList `json:"list"`
+	Unit         string                  `json:"-"`
+	AppliedUnits *UnitPreferences        `json:"-"` // units the values were converted to, if any
 }
 
+// Decode replaces the forecast with the one read from r.
 func (f *Forecast16WeatherData) Decode(r io.Reader) error {
-	if err := json.NewDecoder(r).Decode(&f); err != nil {
+	var data Forecast16WeatherData
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
 		return err
 	}
+	data.Unit = f.Unit
+	*f = data
 	return nil
 }
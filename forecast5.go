@@ -7,44 +7,55 @@ import (
 	"time"
 )
 
+// dtTxtLayout is the layout of the dt_txt field.
+const dtTxtLayout = "2006-01-02 15:04:05"
+
 type DtTxt struct {
 	time.Time
 }
 
 func (dt *DtTxt) UnmarshalJSON(b []byte) error {
-	t, err := time.Parse("2006-01-02 15:04:05", strings.Trim(string(b), "\""))
+	t, err := time.Parse(dtTxtLayout, strings.Trim(string(b), "\""))
 	dt.Time = t
 	return err
 }
 
-func (t *DtTxt) MarshalJSON() ([]byte, error) {
-	return json.Marshal(t)
+// MarshalJSON writes the time in the layout it was read in.
+func (t DtTxt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.Format(dtTxtLayout))
 }
 
 // Forecast5WeatherList holds specific query data
 type Forecast5WeatherList struct {
-	Dt      int       `json:"dt"`
-	Main    Main      `json:"main"`
-	Weather []Weather `json:"weather"`
-	Clouds  Clouds    `json:"clouds"`
-	Wind    Wind      `json:"wind"`
-	Rain    Rain      `json:"rain"`
-	Snow    Snow      `json:"snow"`
-	DtTxt   DtTxt     `json:"dt_txt"`
+	Dt         int       `json:"dt"`
+	Main       Main      `json:"main"`
+	Weather    []Weather `json:"weather"`
+	Clouds     Clouds    `json:"clouds"`
+	Wind       Wind      `json:"wind"`
+	Rain       Rain      `json:"rain"`
+	Snow       Snow      `json:"snow"`
+	Visibility float64   `json:"visibility"`
+	DtTxt      DtTxt     `json:"dt_txt"`
 }
 
 // Forecast5WeatherData will hold returned data from queries
 type Forecast5WeatherData struct {
 	// COD     string                `json:"cod"`
 	// Message float64               `json:"message"`
-	City City                   `json:"city"`
-	Cnt  int                    `json:"cnt"`
-	List []Forecast5WeatherList `json:"list"`
+	City         City                   `json:"city"`
+	Cnt          int                    `json:"cnt"`
+	List         []Forecast5WeatherList `json:"list"`
+	Unit         string                 `json:"-"`
+	AppliedUnits *UnitPreferences       `json:"-"` // units the values were converted to, if any
 }
 
+// Decode replaces the forecast with the one read from r.
 func (f *Forecast5WeatherData) Decode(r io.Reader) error {
-	if err := json.NewDecoder(r).Decode(&f); err != nil {
+	var data Forecast5WeatherData
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
 		return err
 	}
+	data.Unit = f.Unit
+	*f = data
 	return nil
 }
@@ -15,8 +15,8 @@
 package openweathermap
 
 import (
+	"encoding/json"
 	"net/http"
-	"os"
 	"reflect"
 	"testing"
 	"time"
@@ -32,7 +32,7 @@ func TestNewForecast(t *testing.T) {
 		t.Logf("Data unit: %s", d)
 
 		if ValidDataUnit(d) {
-			c5, err := NewForecast("5", d, "ru", os.Getenv("OWM_API_KEY"))
+			c5, err := NewForecast("5", d, "ru", "key")
 			if err != nil {
 				t.Error(err)
 			}
@@ -41,7 +41,7 @@ func TestNewForecast(t *testing.T) {
 				t.Error("incorrect data type returned")
 			}
 
-			c16, err := NewForecast("16", d, "ru", os.Getenv("OWM_API_KEY"))
+			c16, err := NewForecast("16", d, "ru", "key")
 			if err != nil {
 				t.Error(err)
 			}
@@ -54,7 +54,7 @@ func TestNewForecast(t *testing.T) {
 		}
 	}
 
-	_, err := NewForecast("", "asdf", "en", os.Getenv("OWM_API_KEY"))
+	_, err := NewForecast("", "asdf", "en", "key")
 	if err == nil {
 		t.Error("created instance when it shouldn't have")
 	}
@@ -66,7 +66,7 @@ func TestNewForecastWithCustomHttpClient(t *testing.T) {
 
 	hc := http.DefaultClient
 	hc.Timeout = time.Duration(1) * time.Second
-	f, err := NewForecast("5", "c", "en", os.Getenv("OWM_API_KEY"), WithHttpClient(hc))
+	f, err := NewForecast("5", "c", "en", "key", WithHttpClient(hc))
 	if err != nil {
 		t.Error(err)
 	}
@@ -93,7 +93,7 @@ func TestNewForecastWithInvalidOptions(t *testing.T) {
 	}
 
 	for _, options := range optionsPattern {
-		c, err := NewForecast("5", "c", "en", os.Getenv("OWM_API_KEY"), options...)
+		c, err := NewForecast("5", "c", "en", "key", options...)
 		if err == errInvalidOption {
 			t.Logf("Received expected invalid option error. message: %s", err.Error())
 		} else if err != nil {
@@ -109,7 +109,7 @@ func TestNewForecastWithInvalidOptions(t *testing.T) {
 // invalid http client
 func TestNewForecastWithInvalidHttpClient(t *testing.T) {
 
-	f, err := NewForecast("5", "c", "en", os.Getenv("OWM_API_KEY"), WithHttpClient(nil))
+	f, err := NewForecast("5", "c", "en", "key", WithHttpClient(nil))
 	if err == errInvalidHttpClient {
 		t.Logf("Received expected bad client error. message: %s", err.Error())
 	} else if err != nil {
@@ -125,9 +125,12 @@ func TestNewForecastWithInvalidHttpClient(t *testing.T) {
 func TestDailyByName(t *testing.T) {
 	t.Parallel()
 
-	f, err := NewForecast("5", "f", "fi", os.Getenv("OWM_API_KEY"))
+	srv, opt := newFakeServer()
+	defer srv.Close()
+
+	f, err := NewForecast("5", "f", "fi", "key", opt)
 	if err != nil {
-		t.Error(err)
+		t.Fatal(err)
 	}
 
 	for _, d := range forecastRange {
@@ -135,6 +138,11 @@ func TestDailyByName(t *testing.T) {
 		if err != nil {
 			t.Error(err)
 		}
+
+		data := f.ForecastWeatherJson.(*Forecast5WeatherData)
+		if data.City.Name != "Dubai" || len(data.List) != d {
+			t.Errorf("expected %d entries for Dubai, got %d for %s", d, len(data.List), data.City.Name)
+		}
 	}
 }
 
@@ -143,9 +151,12 @@ func TestDailyByName(t *testing.T) {
 func TestDailyByCoordinates(t *testing.T) {
 	t.Parallel()
 
-	f, err := NewForecast("5", "f", "PL", os.Getenv("OWM_API_KEY"))
+	srv, opt := newFakeServer()
+	defer srv.Close()
+
+	f, err := NewForecast("5", "f", "PL", "key", opt)
 	if err != nil {
-		t.Error(err)
+		t.Fatal(err)
 	}
 
 	for _, d := range forecastRange {
@@ -158,6 +169,11 @@ func TestDailyByCoordinates(t *testing.T) {
 		if err != nil {
 			t.Error(err)
 		}
+
+		data := f.ForecastWeatherJson.(*Forecast5WeatherData)
+		if data.City.Name != "Phoenix" || len(data.List) != d {
+			t.Errorf("expected %d entries for Phoenix, got %d for %s", d, len(data.List), data.City.Name)
+		}
 	}
 }
 
@@ -166,9 +182,12 @@ func TestDailyByCoordinates(t *testing.T) {
 func TestDailyByID(t *testing.T) {
 	t.Parallel()
 
-	f, err := NewForecast("5", "c", "fr", os.Getenv("OWM_API_KEY"))
+	srv, opt := newFakeServer()
+	defer srv.Close()
+
+	f, err := NewForecast("5", "c", "fr", "key", opt)
 	if err != nil {
-		t.Error(err)
+		t.Fatal(err)
 	}
 
 	for _, d := range forecastRange {
@@ -176,5 +195,40 @@ func TestDailyByID(t *testing.T) {
 		if err != nil {
 			t.Error(err)
 		}
+
+		data := f.ForecastWeatherJson.(*Forecast5WeatherData)
+		if data.City.Name != "Moscow" || len(data.List) != d {
+			t.Errorf("expected %d entries for Moscow, got %d for %s", d, len(data.List), data.City.Name)
+		}
+	}
+}
+
+// TestForecast5JSONRoundTrip will verify that the 5 day forecast is
+// marshaled back to JSON with dt_txt in its original layout
+func TestForecast5JSONRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	srv, opt := newFakeServer()
+	defer srv.Close()
+
+	f, err := NewForecast("5", "c", "en", "key", opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.DailyByName("Berlin", 1); err != nil {
+		t.Fatal(err)
+	}
+	data := f.ForecastWeatherJson.(*Forecast5WeatherData)
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Forecast5WeatherData
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.List) != 1 || !decoded.List[0].DtTxt.Equal(data.List[0].DtTxt.Time) {
+		t.Errorf("expected %v, got %+v", data.List[0].DtTxt, decoded.List)
 	}
 }
@@ -0,0 +1,210 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package format renders weather results as an aligned table, JSON,
+// NDJSON, CSV, YAML or through a template, e.g. for exports. Tables, CSV
+// and NDJSON have a column per value in a stable order, see Tabulate,
+// while JSON and YAML keep the whole result.
+package format
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	errUnknownFormat = errors.New("format should be table, json, ndjson, csv, yaml or template")
+	errNoTemplate    = errors.New("template format needs a template")
+)
+
+// Format is an output format.
+type Format int
+
+// Formats.
+const (
+	Table Format = iota
+	JSON
+	NDJSON
+	CSV
+	YAML
+	Template
+)
+
+var formatNames = []string{"table", "json", "ndjson", "csv", "yaml", "template"}
+
+// String returns the name of the format.
+func (f Format) String() string {
+	if f < 0 || int(f) >= len(formatNames) {
+		return fmt.Sprintf("Format(%d)", int(f))
+	}
+	return formatNames[f]
+}
+
+// ParseFormat returns the format of the name, ignoring case.
+func ParseFormat(name string) (Format, error) {
+	for i, n := range formatNames {
+		if strings.EqualFold(n, name) {
+			return Format(i), nil
+		}
+	}
+	return 0, errUnknownFormat
+}
+
+// Formatter writes results in a format.
+type Formatter struct {
+	Format   Format
+	Template *template.Template // executed with the result by the Template format
+}
+
+// Write writes the result in the format of the formatter.
+func Write(w io.Writer, format Format, v interface{}) error {
+	return (&Formatter{Format: format}).Write(w, v)
+}
+
+// Write writes the result.
+func (f *Formatter) Write(w io.Writer, v interface{}) error {
+	v = unwrap(v)
+
+	switch f.Format {
+	case Table, CSV, NDJSON:
+		r, err := Tabulate(v)
+		if err != nil {
+			return err
+		}
+		switch f.Format {
+		case Table:
+			return writeTable(w, r)
+		case CSV:
+			return writeCSV(w, r)
+		}
+		return writeNDJSON(w, r)
+	case JSON:
+		e := json.NewEncoder(w)
+		e.SetIndent("", "  ")
+		return e.Encode(v)
+	case YAML:
+		return writeYAML(w, v)
+	case Template:
+		if f.Template == nil {
+			return errNoTemplate
+		}
+		return f.Template.Execute(w, v)
+	}
+	return errUnknownFormat
+}
+
+// writeTable writes the records as columns aligned with spaces under an
+// upper case header.
+func writeTable(w io.Writer, r *Records) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := make([]string, len(r.Columns))
+	for i, c := range r.Columns {
+		header[i] = strings.ToUpper(c)
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+	cells := make([]string, len(r.Columns))
+	for _, row := range r.Rows {
+		for i, v := range row {
+			cells[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(cell(v))
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+// writeCSV writes the records as CSV with a header.
+func writeCSV(w io.Writer, r *Records) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(r.Columns); err != nil {
+		return err
+	}
+	cells := make([]string, len(r.Columns))
+	for _, row := range r.Rows {
+		for i, v := range row {
+			cells[i] = cell(v)
+		}
+		if err := cw.Write(cells); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeNDJSON writes each record as a JSON object on its own line, with
+// the keys in the order of the columns.
+func writeNDJSON(w io.Writer, r *Records) error {
+	var b bytes.Buffer
+	for _, row := range r.Rows {
+		b.Reset()
+		b.WriteByte('{')
+		for i, v := range row {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			k, _ := json.Marshal(r.Columns[i])
+			val, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			b.Write(k)
+			b.WriteByte(':')
+			b.Write(val)
+		}
+		b.WriteString("}\n")
+		if _, err := w.Write(b.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeYAML writes the result as YAML. It goes through JSON so the keys
+// and the fields left out are the same as for the JSON format.
+func writeYAML(w io.Writer, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var n yaml.Node
+	if err := yaml.Unmarshal(b, &n); err != nil {
+		return err
+	}
+	block(&n)
+
+	e := yaml.NewEncoder(w)
+	e.SetIndent(2)
+	if err := e.Encode(&n); err != nil {
+		return err
+	}
+	return e.Close()
+}
+
+// block drops the flow style the JSON was decoded with.
+func block(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		block(c)
+	}
+}
@@ -0,0 +1,348 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package format
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	owm "github.com/briandowns/openweathermap"
+	"github.com/briandowns/openweathermap/owmtest"
+	"gopkg.in/yaml.v3"
+)
+
+// berlin returns the current weather of Berlin from the fake server.
+func berlin(t *testing.T) *owm.CurrentWeatherData {
+	t.Helper()
+
+	srv := owmtest.NewServer()
+	defer srv.Close()
+
+	w, err := owm.NewCurrent("C", "EN", "s3cret", owm.WithHttpClient(srv.Client()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.CurrentByName("Berlin"); err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+// TestParseFormat will verify that formats are parsed from their names
+func TestParseFormat(t *testing.T) {
+	t.Parallel()
+
+	for _, f := range []Format{Table, JSON, NDJSON, CSV, YAML, Template} {
+		got, err := ParseFormat(strings.ToUpper(f.String()))
+		if err != nil || got != f {
+			t.Errorf("%s: got %v, %v", f, got, err)
+		}
+	}
+	if _, err := ParseFormat("xml"); err != errUnknownFormat {
+		t.Errorf("expected %v, got %v", errUnknownFormat, err)
+	}
+	if s := Format(42).String(); s != "Format(42)" {
+		t.Errorf("unexpected name %q", s)
+	}
+}
+
+// TestTabulate will verify the columns and rows of the supported results
+func TestTabulate(t *testing.T) {
+	t.Parallel()
+
+	srv := owmtest.NewServer()
+	defer srv.Close()
+	opt := owm.WithHttpClient(srv.Client())
+	phoenix := &owm.Coordinates{Latitude: 33.45, Longitude: -112.07}
+
+	f5, err := owm.NewForecast("5", "C", "EN", "key", opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f5.DailyByName("Berlin", 16); err != nil {
+		t.Fatal(err)
+	}
+	f16, err := owm.NewForecast("16", "C", "EN", "key", opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f16.DailyByName("Berlin", 7); err != nil {
+		t.Fatal(err)
+	}
+	o, err := owm.NewOneCall("C", "EN", "key", []string{owm.ExcludeMinutely}, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.OneCallByCoordinates(phoenix); err != nil {
+		t.Fatal(err)
+	}
+	p, err := owm.NewPollution("key", opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.PollutionByParams(&owm.PollutionParameters{Location: *phoenix, Datetime: "current"}); err != nil {
+		t.Fatal(err)
+	}
+	u, err := owm.NewUV("key", opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := u.Current(phoenix); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		v       interface{}
+		columns []string
+		rows    int
+	}{
+		{"current", berlin(t), currentColumns, 1},
+		{"current value", *berlin(t), currentColumns, 1},
+		{"forecast5", f5, forecast5Columns, 16},
+		{"forecast16", f16.ForecastWeatherJson, forecast16Columns, 7},
+		{"onecall", o, oneCallColumns, 1 + 48 + 8},
+		{"pollution", p, pollutionColumns, 1},
+		{"uv", u, uvColumns, 1},
+	} {
+		r, err := Tabulate(tc.v)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(r.Columns, tc.columns) || len(r.Rows) != tc.rows {
+			t.Errorf("%s: expected %d rows of %v, got %d of %v", tc.name, tc.rows, tc.columns, len(r.Rows), r.Columns)
+		}
+		for i, row := range r.Rows {
+			if len(row) != len(r.Columns) {
+				t.Errorf("%s: row %d has %d values", tc.name, i, len(row))
+			}
+		}
+	}
+
+	r, _ := Tabulate(o)
+	if r.Rows[0][0] != "current" || r.Rows[1][0] != "hourly" || r.Rows[len(r.Rows)-1][0] != "daily" {
+		t.Errorf("unexpected parts %v, %v, %v", r.Rows[0][0], r.Rows[1][0], r.Rows[len(r.Rows)-1][0])
+	}
+	r, _ = Tabulate(berlin(t))
+	if r.Rows[0][0] != "Berlin" || r.Rows[0][15] != "broken clouds" {
+		t.Errorf("unexpected row %v", r.Rows[0])
+	}
+}
+
+type station struct {
+	ID       string    `json:"id"`
+	Secret   string    `json:"-"`
+	Updated  time.Time `json:"updated"`
+	Location struct {
+		Lat float64 `json:"lat"`
+		Lon float64 `json:"lon"`
+	} `json:"location"`
+	Temp    *float64
+	Tags    []string      `json:"tags"`
+	Weather []owm.Weather `json:"weather"`
+	Alerts  []owm.OneCallAlertData
+	private int
+}
+
+type tabular struct{}
+
+func (tabular) Records() (*Records, error) {
+	return &Records{Columns: []string{"a"}, Rows: [][]interface{}{{1.5}}}, nil
+}
+
+// TestTabulateReflect will verify that other structs are tabulated by
+// their fields
+func TestTabulateReflect(t *testing.T) {
+	t.Parallel()
+
+	s := station{ID: "10", Secret: "s3cret", Updated: time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC), Temp: owm.Float64(21.5),
+		Tags: []string{"roof", "north"}, Weather: []owm.Weather{{Description: "rain"}, {Description: "mist"}}}
+	s.Location.Lat = 52.5
+
+	r, err := Tabulate([]*station{&s, {}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	columns := []string{"id", "updated", "location_lat", "location_lon", "temp", "tags", "description"}
+	if !reflect.DeepEqual(r.Columns, columns) {
+		t.Errorf("expected %v, got %v", columns, r.Columns)
+	}
+	row := []interface{}{"10", s.Updated, 52.5, 0.0, 21.5, "roof north", "rain, mist"}
+	if len(r.Rows) != 2 || !reflect.DeepEqual(r.Rows[0], row) || r.Rows[1][4] != nil {
+		t.Errorf("unexpected rows %v", r.Rows)
+	}
+
+	if r, err := Tabulate(tabular{}); err != nil || r.Columns[0] != "a" {
+		t.Errorf("expected the records of the result, got %v, %v", r, err)
+	}
+	for _, v := range []interface{}{42, []int{1}, (*station)(nil)} {
+		if _, err := Tabulate(v); !errors.Is(err, errUnsupported) {
+			t.Errorf("%T: expected %v, got %v", v, errUnsupported, err)
+		}
+	}
+}
+
+// TestWriteTable will verify that the table is aligned under its header
+func TestWriteTable(t *testing.T) {
+	t.Parallel()
+
+	var b bytes.Buffer
+	if err := Write(&b, Table, berlin(t)); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", b.String())
+	}
+	if !strings.HasPrefix(lines[0], "NAME    COUNTRY  LAT") || !strings.HasPrefix(lines[1], "Berlin  DE       52.5244") {
+		t.Errorf("unexpected table\n%s", b.String())
+	}
+	if i := strings.Index(lines[0], "DESCRIPTION"); i < 0 || !strings.HasSuffix(lines[1][:i+len("broken clouds")], "broken clouds") {
+		t.Errorf("columns aren't aligned\n%s", b.String())
+	}
+}
+
+// TestWriteCSV will verify the header and rows of the CSV
+func TestWriteCSV(t *testing.T) {
+	t.Parallel()
+
+	var b bytes.Buffer
+	if err := Write(&b, CSV, []*owm.CurrentWeatherData{berlin(t), berlin(t)}); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&b).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 || !reflect.DeepEqual(records[0], currentColumns) {
+		t.Errorf("unexpected records %v", records)
+	}
+
+	b.Reset()
+	if err := Write(&b, CSV, berlin(t)); err != nil {
+		t.Fatal(err)
+	}
+	records, err = csv.NewReader(&b).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(records[0], currentColumns) || records[1][0] != "Berlin" || records[1][4] != "2024-05-13T12:00:00Z" {
+		t.Errorf("unexpected records %v", records)
+	}
+}
+
+// TestWriteNDJSON will verify that each record is a JSON line with the
+// keys in the column order
+func TestWriteNDJSON(t *testing.T) {
+	t.Parallel()
+
+	var b bytes.Buffer
+	if err := Write(&b, NDJSON, &owm.OneCallData{Daily: make([]owm.OneCallDailyData, 3)}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %q", b.String())
+	}
+	for _, l := range lines {
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(l), &m); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(l, `{"part":"daily","time":"1970-01-01T00:00:00Z","temp":0`) || len(m) != len(oneCallColumns) {
+			t.Errorf("unexpected line %s", l)
+		}
+	}
+}
+
+// TestWriteJSONAndYAML will verify that the whole result is written
+// without the API key
+func TestWriteJSONAndYAML(t *testing.T) {
+	t.Parallel()
+
+	w := berlin(t)
+	for _, f := range []Format{JSON, YAML} {
+		var b bytes.Buffer
+		if err := Write(&b, f, w); err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(b.String(), "s3cret") {
+			t.Errorf("%s: the output contains the key", f)
+		}
+
+		var decoded owm.CurrentWeatherData
+		if f == JSON {
+			err := json.Unmarshal(b.Bytes(), &decoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+		} else {
+			if !strings.HasPrefix(b.String(), "coord:\n  lon: 13.4105\n  lat: 52.5244\n") {
+				t.Errorf("expected block style in the JSON order, got\n%s", b.String())
+			}
+			var m map[string]interface{}
+			if err := yaml.Unmarshal(b.Bytes(), &m); err != nil {
+				t.Fatal(err)
+			}
+			j, _ := json.Marshal(m)
+			if err := json.Unmarshal(j, &decoded); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if decoded.Name != w.Name || decoded.Main != w.Main || decoded.Weather[0] != w.Weather[0] {
+			t.Errorf("%s: expected %+v, got %+v", f, w, decoded)
+		}
+	}
+
+	var b bytes.Buffer
+	if err := Write(&b, YAML, struct {
+		Code string `json:"code"`
+	}{"10"}); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "code: \"10\"\n" {
+		t.Errorf("expected the string to stay quoted, got %q", b.String())
+	}
+}
+
+// TestWriteTemplate will verify that the template is executed with the
+// result
+func TestWriteTemplate(t *testing.T) {
+	t.Parallel()
+
+	tmpl := template.Must(template.New("t").Parse("{{.Name}}: {{.Main.Humidity}}%\n"))
+	var b bytes.Buffer
+	if err := (&Formatter{Format: Template, Template: tmpl}).Write(&b, berlin(t)); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "Berlin: 62%\n" {
+		t.Errorf("unexpected output %q", b.String())
+	}
+
+	if err := Write(&b, Template, berlin(t)); err != errNoTemplate {
+		t.Errorf("expected %v, got %v", errNoTemplate, err)
+	}
+	if err := Write(&b, Format(42), berlin(t)); err != errUnknownFormat {
+		t.Errorf("expected %v, got %v", errUnknownFormat, err)
+	}
+}
@@ -0,0 +1,350 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package format
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	owm "github.com/briandowns/openweathermap"
+)
+
+var errUnsupported = errors.New("result can't be tabulated")
+
+// Records is the tabular view of a result: named columns and a row of
+// values per record. The values are nil, strings, bools, int64, uint64,
+// float64 or UTC times.
+type Records struct {
+	Columns []string
+	Rows    [][]interface{}
+}
+
+// Tabular is implemented by results tabulating themselves.
+type Tabular interface {
+	Records() (*Records, error)
+}
+
+// Columns of the results with a fixed layout.
+var (
+	currentColumns = []string{"name", "country", "lat", "lon", "time", "temp", "feels_like", "temp_min", "temp_max",
+		"pressure", "humidity", "wind_speed", "wind_deg", "clouds", "visibility", "description"}
+	forecast5Columns = []string{"time", "temp", "feels_like", "temp_min", "temp_max", "pressure", "humidity",
+		"wind_speed", "wind_deg", "clouds", "rain_3h", "snow_3h", "description"}
+	forecast16Columns = []string{"time", "temp_day", "temp_min", "temp_max", "temp_night", "pressure", "humidity",
+		"wind_speed", "wind_deg", "clouds", "rain", "snow", "description"}
+	oneCallColumns = []string{"part", "time", "temp", "feels_like", "temp_min", "temp_max", "pressure", "humidity",
+		"wind_speed", "wind_deg", "clouds", "uvi", "pop", "description"}
+	pollutionColumns = []string{"time", "lat", "lon", "aqi", "co", "no", "no2", "o3", "so2", "pm2_5", "pm10", "nh3"}
+	uvColumns        = []string{"time", "lat", "lon", "value"}
+)
+
+// Tabulate returns the tabular view of the result. The current weather,
+// groups and slices of it, the 5 and 16 day forecasts, one call,
+// pollution and UV have fixed columns. One call has a row for the current
+// weather and each hour and day of the forecasts, told apart by the part
+// column. Other structs and slices of structs get a column per field named
+// after its JSON name, nested ones joined with "_", and leave out the
+// slices other than the weather conditions.
+func Tabulate(v interface{}) (*Records, error) {
+	v = unwrap(v)
+	if t, ok := v.(Tabular); ok {
+		return t.Records()
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Struct {
+		p := reflect.New(rv.Type())
+		p.Elem().Set(rv)
+		v = p.Interface()
+	}
+
+	switch v := v.(type) {
+	case *owm.CurrentWeatherData:
+		return &Records{Columns: currentColumns, Rows: [][]interface{}{currentRow(v)}}, nil
+	case *owm.CurrentWeatherGroup:
+		return Tabulate(v.List)
+	case []*owm.CurrentWeatherData:
+		r := &Records{Columns: currentColumns}
+		for _, w := range v {
+			r.Rows = append(r.Rows, currentRow(w))
+		}
+		return r, nil
+	case []owm.CurrentWeatherData:
+		r := &Records{Columns: currentColumns}
+		for i := range v {
+			r.Rows = append(r.Rows, currentRow(&v[i]))
+		}
+		return r, nil
+	case *owm.Forecast5WeatherData:
+		r := &Records{Columns: forecast5Columns}
+		for _, f := range v.List {
+			r.Rows = append(r.Rows, []interface{}{unix(int64(f.Dt)), f.Main.Temp, f.Main.FeelsLike, f.Main.TempMin,
+				f.Main.TempMax, f.Main.Pressure, int64(f.Main.Humidity), f.Wind.Speed, f.Wind.Deg,
+				int64(f.Clouds.All), f.Rain.ThreeH, f.Snow.ThreeH, describe(f.Weather)})
+		}
+		return r, nil
+	case *owm.Forecast16WeatherData:
+		r := &Records{Columns: forecast16Columns}
+		for _, f := range v.List {
+			r.Rows = append(r.Rows, []interface{}{unix(int64(f.Dt)), f.Temp.Day, f.Temp.Min, f.Temp.Max,
+				f.Temp.Night, f.Pressure, int64(f.Humidity), f.Speed, int64(f.Deg), int64(f.Clouds), f.Rain,
+				f.Snow, describe(f.Weather)})
+		}
+		return r, nil
+	case *owm.OneCallData:
+		return oneCallRecords(v), nil
+	case *owm.Pollution:
+		r := &Records{Columns: pollutionColumns}
+		for _, p := range v.List {
+			c := p.Components
+			r.Rows = append(r.Rows, []interface{}{unix(int64(p.Dt)), v.Location.Latitude, v.Location.Longitude,
+				p.Main.Aqi, c.Co, c.No, c.No2, c.O3, c.So2, c.Pm25, c.Pm10, c.Nh3})
+		}
+		return r, nil
+	case *owm.UV:
+		var lat, lon interface{}
+		if len(v.Coord) == 2 {
+			lat, lon = v.Coord[0], v.Coord[1]
+		}
+		r := &Records{Columns: uvColumns}
+		if len(v.Data) == 0 {
+			r.Rows = append(r.Rows, []interface{}{unix(v.DT), lat, lon, v.Value})
+		}
+		for _, d := range v.Data {
+			r.Rows = append(r.Rows, []interface{}{unix(d.DT), lat, lon, d.Value})
+		}
+		return r, nil
+	}
+	return reflectRecords(reflect.ValueOf(v))
+}
+
+// unwrap returns the forecast of the forecast weather data, and the result
+// otherwise.
+func unwrap(v interface{}) interface{} {
+	if f, ok := v.(*owm.ForecastWeatherData); ok && f.ForecastWeatherJson != nil {
+		return f.ForecastWeatherJson
+	}
+	return v
+}
+
+// currentRow returns the row of the current weather.
+func currentRow(w *owm.CurrentWeatherData) []interface{} {
+	return []interface{}{w.Name, w.Sys.Country, w.GeoPos.Latitude, w.GeoPos.Longitude, unix(int64(w.Dt)),
+		w.Main.Temp, w.Main.FeelsLike, w.Main.TempMin, w.Main.TempMax, w.Main.Pressure, int64(w.Main.Humidity),
+		w.Wind.Speed, w.Wind.Deg, int64(w.Clouds.All), w.Visibility, describe(w.Weather)}
+}
+
+// oneCallRecords returns the rows of the current weather and of the hourly
+// and daily forecasts, leaving out the values they don't have.
+func oneCallRecords(o *owm.OneCallData) *Records {
+	r := &Records{Columns: oneCallColumns}
+	if c := o.Current; c.Dt != 0 {
+		r.Rows = append(r.Rows, []interface{}{"current", unix(int64(c.Dt)), c.Temp, c.FeelsLike, nil, nil,
+			c.Pressure, int64(c.Humidity), c.WindSpeed, c.WindDeg, int64(c.Clouds), c.UVI, nil, describe(c.Weather)})
+	}
+	for _, h := range o.Hourly {
+		r.Rows = append(r.Rows, []interface{}{"hourly", unix(int64(h.Dt)), h.Temp, h.FeelsLike, nil, nil,
+			h.Pressure, int64(h.Humidity), h.WindSpeed, h.WindDeg, int64(h.Clouds), h.UVI, h.Pop, describe(h.Weather)})
+	}
+	for _, d := range o.Daily {
+		r.Rows = append(r.Rows, []interface{}{"daily", unix(int64(d.Dt)), d.Temp.Day, d.FeelsLike.Day, d.Temp.Min,
+			d.Temp.Max, d.Pressure, int64(d.Humidity), d.WindSpeed, d.WindDeg, int64(d.Clouds), d.UVI, d.Pop,
+			describe(d.Weather)})
+	}
+	return r
+}
+
+func unix(dt int64) time.Time { return time.Unix(dt, 0).UTC() }
+
+// describe joins the descriptions of the weather conditions.
+func describe(weather []owm.Weather) string {
+	d := make([]string, len(weather))
+	for i, w := range weather {
+		d[i] = w.Description
+	}
+	return strings.Join(d, ", ")
+}
+
+// cell returns the text of a value.
+func cell(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	}
+	return fmt.Sprint(v)
+}
+
+var (
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	weatherType       = reflect.TypeOf([]owm.Weather(nil))
+	timeType          = reflect.TypeOf(time.Time{})
+)
+
+// column is a column of a struct, reached through the field indexes.
+type column struct {
+	name  string
+	index []int
+}
+
+// reflectRecords tabulates a struct or a slice of structs by reflection.
+func reflectRecords(v reflect.Value) (*Records, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, errUnsupported
+		}
+		v = v.Elem()
+	}
+
+	var rows []reflect.Value
+	t := v.Type()
+	switch v.Kind() {
+	case reflect.Struct:
+		rows = []reflect.Value{v}
+	case reflect.Slice, reflect.Array:
+		t = t.Elem()
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		for i := 0; i < v.Len(); i++ {
+			e := v.Index(i)
+			for e.Kind() == reflect.Ptr && !e.IsNil() {
+				e = e.Elem()
+			}
+			rows = append(rows, e)
+		}
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %s", errUnsupported, v.Type())
+	}
+
+	columns := columns(t, "", nil)
+	r := &Records{Columns: make([]string, len(columns))}
+	for i, c := range columns {
+		r.Columns[i] = c.name
+	}
+	for _, row := range rows {
+		values := make([]interface{}, len(columns))
+		if row.Kind() == reflect.Struct {
+			for i, c := range columns {
+				values[i] = value(row.FieldByIndex(c.index))
+			}
+		}
+		r.Rows = append(r.Rows, values)
+	}
+	return r, nil
+}
+
+// columns returns the columns of the exported fields of the struct type.
+func columns(t reflect.Type, prefix string, index []int) []column {
+	var cols []column
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		tag := strings.Split(f.Tag.Get("json"), ",")[0]
+		if tag == "-" {
+			continue
+		}
+		name := tag
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		idx := append(append([]int(nil), index...), i)
+		ft := f.Type
+
+		switch {
+		case ft == weatherType:
+			cols = append(cols, column{prefix + "description", idx})
+		case leaf(ft):
+			cols = append(cols, column{prefix + name, idx})
+		case ft.Kind() == reflect.Struct && f.Anonymous && tag == "":
+			cols = append(cols, columns(ft, prefix, idx)...)
+		case ft.Kind() == reflect.Struct:
+			cols = append(cols, columns(ft, prefix+name+"_", idx)...)
+		}
+	}
+	return cols
+}
+
+// leaf reports whether values of the type are a single cell.
+func leaf(t reflect.Type) bool {
+	if t == timeType || t.Implements(textMarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return t.Elem().Kind() != reflect.Struct && leaf(t.Elem())
+	case reflect.Slice, reflect.Array:
+		return t.Elem().Kind() != reflect.Struct && t.Elem().Kind() != reflect.Ptr && leaf(t.Elem())
+	case reflect.Struct, reflect.Map, reflect.Interface, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return false
+	}
+	return true
+}
+
+// value returns the value of a leaf field.
+func value(v reflect.Value) interface{} {
+	if v.Type() == weatherType {
+		return describe(v.Interface().([]owm.Weather))
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Type() == timeType {
+		return v.Interface().(time.Time).UTC()
+	}
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		b, err := m.MarshalText()
+		if err != nil {
+			return nil
+		}
+		return string(b)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Slice, reflect.Array:
+		parts := make([]string, v.Len())
+		for i := range parts {
+			parts[i] = cell(value(v.Index(i)))
+		}
+		return strings.Join(parts, " ")
+	}
+	return fmt.Sprint(v.Interface())
+}
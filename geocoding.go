@@ -0,0 +1,112 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// GeoLocation is a place found by the geocoding API.
+type GeoLocation struct {
+	Name       string            `json:"name"`
+	LocalNames map[string]string `json:"local_names,omitempty"`
+	Latitude   float64           `json:"lat"`
+	Longitude  float64           `json:"lon"`
+	Country    string            `json:"country"`
+	State      string            `json:"state,omitempty"`
+	Zip        string            `json:"zip,omitempty"` // set by Zip only
+}
+
+// Coordinates returns the coordinates of the location.
+func (g GeoLocation) Coordinates() *Coordinates {
+	return &Coordinates{Latitude: g.Latitude, Longitude: g.Longitude}
+}
+
+// Geocoding looks up the coordinates of places by name or zip code and the
+// names of places by coordinates.
+type Geocoding struct {
+	Key string
+	*Settings
+}
+
+// NewGeocoding returns a new Geocoding pointer with the supplied key.
+func NewGeocoding(key string, options ...Option) (*Geocoding, error) {
+	k, err := setKey(key)
+	if err != nil {
+		return nil, err
+	}
+	g := &Geocoding{
+		Key:      k,
+		Settings: NewSettings(),
+	}
+
+	if err := setOptions(g.Settings, options); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// get calls the given geocoding endpoint and decodes the response.
+func (g *Geocoding) get(endpoint string, v url.Values, data interface{}) error {
+	v.Set("appid", g.Key)
+	response, err := g.client.Get(fmt.Sprintf(geoURL, endpoint, v.Encode()))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if err := checkResponse(response); err != nil {
+		return err
+	}
+	return json.NewDecoder(response.Body).Decode(data)
+}
+
+// Direct returns up to limit places matching the query, e.g. "London",
+// "London,GB" or "Portland,OR,US". The limit is at most 5.
+func (g *Geocoding) Direct(query string, limit int) ([]GeoLocation, error) {
+	if limit < 1 || limit > 5 {
+		return nil, errInvalidLimit
+	}
+	var locations []GeoLocation
+	err := g.get("direct", url.Values{"q": {query}, "limit": {strconv.Itoa(limit)}}, &locations)
+	return locations, err
+}
+
+// Zip returns the place of the zip code in the country.
+func (g *Geocoding) Zip(zip, countryCode string) (*GeoLocation, error) {
+	var location GeoLocation
+	if err := g.get("zip", url.Values{"zip": {zip + "," + countryCode}}, &location); err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
+
+// Reverse returns up to limit places closest to the coordinates. The
+// limit is at most 5.
+func (g *Geocoding) Reverse(location *Coordinates, limit int) ([]GeoLocation, error) {
+	if limit < 1 || limit > 5 {
+		return nil, errInvalidLimit
+	}
+	var locations []GeoLocation
+	err := g.get("reverse", url.Values{
+		"lat":   {strconv.FormatFloat(location.Latitude, 'f', -1, 64)},
+		"lon":   {strconv.FormatFloat(location.Longitude, 'f', -1, 64)},
+		"limit": {strconv.Itoa(limit)},
+	}, &locations)
+	return locations, err
+}
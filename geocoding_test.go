@@ -0,0 +1,118 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/briandowns/openweathermap/owmtest"
+)
+
+// TestGeocodingDirect will verify that places are found by name
+func TestGeocodingDirect(t *testing.T) {
+	t.Parallel()
+
+	srv, opt := newFakeServer()
+	defer srv.Close()
+
+	g, err := NewGeocoding("key", opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	locations, err := g.Direct("Philadelphia,PA,US", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(locations) != 1 {
+		t.Fatalf("expected 1 location, got %d", len(locations))
+	}
+	l := locations[0]
+	if l.Name != "Philadelphia" || l.State != "Pennsylvania" || l.Country != "US" || l.Latitude != 39.9523 {
+		t.Errorf("unexpected location %+v", l)
+	}
+	if c := l.Coordinates(); c.Latitude != l.Latitude || c.Longitude != l.Longitude {
+		t.Errorf("unexpected coordinates %+v", c)
+	}
+	if q := srv.Requests()[0].Query; q.Get("limit") != "5" || q.Get("appid") != "key" {
+		t.Errorf("unexpected query %v", q)
+	}
+
+	locations, err = g.Direct("nowhere_", 1)
+	if err != nil || len(locations) != 0 {
+		t.Errorf("expected no locations, got %v, %v", locations, err)
+	}
+
+	for _, limit := range []int{0, 6} {
+		if _, err := g.Direct("Berlin", limit); err != errInvalidLimit {
+			t.Errorf("%d: expected %v, got %v", limit, errInvalidLimit, err)
+		}
+	}
+}
+
+// TestGeocodingZip will verify that places are found by zip code
+func TestGeocodingZip(t *testing.T) {
+	t.Parallel()
+
+	srv, opt := newFakeServer()
+	defer srv.Close()
+
+	g, err := NewGeocoding("key", opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := g.Zip("02134", "US")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Name != "Boston" || l.Zip != "02134" {
+		t.Errorf("unexpected location %+v", l)
+	}
+
+	_, err = g.Zip("00000", "US")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected a 404, got %v", err)
+	}
+}
+
+// TestGeocodingReverse will verify that places are found by coordinates
+func TestGeocodingReverse(t *testing.T) {
+	t.Parallel()
+
+	srv, opt := newFakeServer()
+	defer srv.Close()
+
+	g, err := NewGeocoding("key", opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	locations, err := g.Reverse(&Coordinates{Latitude: 33.45, Longitude: -112.07}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(locations) != 2 || locations[0].Name != "Phoenix" {
+		t.Errorf("expected Phoenix first, got %+v", locations)
+	}
+
+	srv.Inject("/reverse", owmtest.Unauthorized())
+	if _, err := g.Reverse(&Coordinates{}, 1); !IsInvalidKey(err) {
+		t.Errorf("expected %v, got %v", errInvalidKey, err)
+	}
+}
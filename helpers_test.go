@@ -0,0 +1,39 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/briandowns/openweathermap/owmtest"
+)
+
+// newTestServer starts a server with the given handler and returns it
+// along with an option routing the API calls to it.
+func newTestServer(t *testing.T, handler http.Handler) (*httptest.Server, Option) {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	return srv, WithHttpClient(owmtest.NewClient(srv.URL))
+}
+
+// newFakeServer starts the fake API and returns it along with an option
+// routing the API calls to it.
+func newFakeServer() (*owmtest.Server, Option) {
+	srv := owmtest.NewServer()
+	return srv, WithHttpClient(srv.Client())
+}
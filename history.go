@@ -17,7 +17,7 @@ package openweathermap
 import (
 	"encoding/json"
 	"fmt"
-	"net/http"
+	"io"
 	"net/url"
 	"strings"
 )
@@ -56,14 +56,15 @@ type WeatherHistory struct {
 // HistoricalWeatherData struct is where the JSON is unmarshaled to
 // when receiving data for a historical request.
 type HistoricalWeatherData struct {
-	Message  string           `json:"message"`
-	Cod      int              `json:"cod"`
-	CityData int              `json:"city_data"`
-	CalcTime float64          `json:"calctime"`
-	Cnt      int              `json:"cnt"`
-	List     []WeatherHistory `json:"list"`
-	Unit     string
-	Key      string
+	Message      string           `json:"message"`
+	Cod          int              `json:"cod"`
+	CityData     int              `json:"city_data"`
+	CalcTime     float64          `json:"calctime"`
+	Cnt          int              `json:"cnt"`
+	List         []WeatherHistory `json:"list"`
+	Unit         string
+	AppliedUnits *UnitPreferences `json:"-"` // units the values were converted to, if any
+	Key          string           `json:"-"`
 	*Settings
 }
 
@@ -92,19 +93,32 @@ func NewHistorical(unit, key string, options ...Option) (*HistoricalWeatherData,
 	return h, nil
 }
 
+// decode replaces the result with the response, converted into the
+// preferred units, so nothing of a previous result is kept.
+func (h *HistoricalWeatherData) decode(r io.Reader) error {
+	var data HistoricalWeatherData
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return err
+	}
+	h.applyUnitPreferences(&data)
+	data.Unit, data.Key, data.Settings = h.Unit, h.Key, h.Settings
+	*h = data
+	return nil
+}
+
 // HistoryByName will return the history for the provided location
 func (h *HistoricalWeatherData) HistoryByName(location string) error {
-	response, err := h.client.Get(fmt.Sprintf(fmt.Sprintf(historyURL, "city?appid=%s&q=%s"), h.Key, url.QueryEscape(location)))
+	response, err := h.client.Get(fmt.Sprintf(fmt.Sprintf(historyURL, "city?appid=%s&q=%s&units=%s"), h.Key, url.QueryEscape(location), h.requestUnit(h.Unit)))
 	if err != nil {
 		return err
 	}
 	defer response.Body.Close()
 
-	if response.StatusCode == http.StatusUnauthorized {
-		return errInvalidKey
+	if err := checkResponse(response); err != nil {
+		return err
 	}
 
-	if err = json.NewDecoder(response.Body).Decode(&h); err != nil {
+	if err = h.decode(response.Body); err != nil {
 		return err
 	}
 
@@ -114,32 +128,32 @@ func (h *HistoricalWeatherData) HistoryByName(location string) error {
 // HistoryByID will return the history for the provided location ID
 func (h *HistoricalWeatherData) HistoryByID(id int, hp ...*HistoricalParameters) error {
 	if len(hp) > 0 {
-		response, err := h.client.Get(fmt.Sprintf(fmt.Sprintf(historyURL, "city?appid=%s&id=%d&type=hour&start%d&end=%d&cnt=%d"), h.Key, id, hp[0].Start, hp[0].End, hp[0].Cnt))
+		response, err := h.client.Get(fmt.Sprintf(fmt.Sprintf(historyURL, "city?appid=%s&id=%d&type=hour&start%d&end=%d&cnt=%d&units=%s"), h.Key, id, hp[0].Start, hp[0].End, hp[0].Cnt, h.requestUnit(h.Unit)))
 		if err != nil {
 			return err
 		}
 		defer response.Body.Close()
 
-		if response.StatusCode == http.StatusUnauthorized {
-			return errInvalidKey
+		if err := checkResponse(response); err != nil {
+			return err
 		}
 
-		if err = json.NewDecoder(response.Body).Decode(&h); err != nil {
+		if err = h.decode(response.Body); err != nil {
 			return err
 		}
 	}
 
-	response, err := h.client.Get(fmt.Sprintf(fmt.Sprintf(historyURL, "city?appid=%s&id=%d"), h.Key, id))
+	response, err := h.client.Get(fmt.Sprintf(fmt.Sprintf(historyURL, "city?appid=%s&id=%d&units=%s"), h.Key, id, h.requestUnit(h.Unit)))
 	if err != nil {
 		return err
 	}
 	defer response.Body.Close()
 
-	if response.StatusCode == http.StatusUnauthorized {
-		return errInvalidKey
+	if err := checkResponse(response); err != nil {
+		return err
 	}
 
-	if err = json.NewDecoder(response.Body).Decode(&h); err != nil {
+	if err = h.decode(response.Body); err != nil {
 		return err
 	}
 
@@ -148,17 +162,17 @@ func (h *HistoricalWeatherData) HistoryByID(id int, hp ...*HistoricalParameters)
 
 // HistoryByCoord will return the history for the provided coordinates
 func (h *HistoricalWeatherData) HistoryByCoord(location *Coordinates, hp *HistoricalParameters) error {
-	response, err := h.client.Get(fmt.Sprintf(fmt.Sprintf(historyURL, "appid=%s&lat=%f&lon=%f&start=%d&end=%d"), h.Key, location.Latitude, location.Longitude, hp.Start, hp.End))
+	response, err := h.client.Get(fmt.Sprintf(fmt.Sprintf(historyURL, "city?appid=%s&type=hour&lat=%f&lon=%f&start=%d&end=%d&units=%s"), h.Key, location.Latitude, location.Longitude, hp.Start, hp.End, h.requestUnit(h.Unit)))
 	if err != nil {
 		return err
 	}
 	defer response.Body.Close()
 
-	if response.StatusCode == http.StatusUnauthorized {
-		return errInvalidKey
+	if err := checkResponse(response); err != nil {
+		return err
 	}
 
-	if err = json.NewDecoder(response.Body).Decode(&h); err != nil {
+	if err = h.decode(response.Body); err != nil {
 		return err
 	}
 
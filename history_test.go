@@ -16,7 +16,6 @@ package openweathermap
 
 import (
 	"net/http"
-	"os"
 	"reflect"
 	"testing"
 	"time"
@@ -30,7 +29,7 @@ func TestNewHistory(t *testing.T) {
 		t.Logf("Data unit: %s", d)
 
 		if ValidDataUnit(d) {
-			c, err := NewHistorical(d, os.Getenv("OWM_API_KEY"))
+			c, err := NewHistorical(d, "key")
 			if err != nil {
 				t.Error(err)
 			}
@@ -42,7 +41,7 @@ func TestNewHistory(t *testing.T) {
 		}
 	}
 
-	_, err := NewHistorical("asdf", os.Getenv("OWM_API_KEY"))
+	_, err := NewHistorical("asdf", "key")
 	if err == nil {
 		t.Error("created instance when it shouldn't have")
 	}
@@ -54,7 +53,7 @@ func TestNewHistoryWithCustomHttpClient(t *testing.T) {
 
 	hc := http.DefaultClient
 	hc.Timeout = time.Duration(1) * time.Second
-	h, err := NewHistorical("c", os.Getenv("OWM_API_KEY"), WithHttpClient(hc))
+	h, err := NewHistorical("c", "key", WithHttpClient(hc))
 	if err != nil {
 		t.Error(err)
 	}
@@ -81,7 +80,7 @@ func TestNewHistoryWithInvalidOptions(t *testing.T) {
 	}
 
 	for _, options := range optionsPattern {
-		c, err := NewHistorical("c", os.Getenv("OWM_API_KEY"), options...)
+		c, err := NewHistorical("c", "key", options...)
 		if err == errInvalidOption {
 			t.Logf("Received expected invalid option error. message: %s", err.Error())
 		} else if err != nil {
@@ -97,7 +96,7 @@ func TestNewHistoryWithInvalidOptions(t *testing.T) {
 // invalid http client
 func TestNewHistoryWithInvalidHttpClient(t *testing.T) {
 
-	h, err := NewHistorical("c", os.Getenv("OWM_API_KEY"), WithHttpClient(nil))
+	h, err := NewHistorical("c", "key", WithHttpClient(nil))
 	if err == errInvalidHttpClient {
 		t.Logf("Received expected bad client error. message: %s", err.Error())
 	} else if err != nil {
@@ -111,49 +110,70 @@ func TestNewHistoryWithInvalidHttpClient(t *testing.T) {
 // TestHistoryByName
 func TestHistoryByName(t *testing.T) {
 	t.Parallel()
-	h, err := NewHistorical("F", os.Getenv("OWM_API_KEY"))
+
+	srv, opt := newFakeServer()
+	defer srv.Close()
+
+	h, err := NewHistorical("F", "key", opt)
 	if err != nil {
-		t.Error(err)
+		t.Fatal(err)
 	}
 	if err := h.HistoryByName("Vancouver"); err != nil {
 		t.Error(err)
 	}
+	if len(h.List) == 0 {
+		t.Error("expected the history of Vancouver")
+	}
 }
 
 // TestHistoryByID
 func TestHistoryByID(t *testing.T) {
 	t.Parallel()
-	h, err := NewHistorical("F", os.Getenv("OWM_API_KEY"))
+
+	srv, opt := newFakeServer()
+	defer srv.Close()
+
+	h, err := NewHistorical("F", "key", opt)
 	if err != nil {
-		t.Error(err)
+		t.Fatal(err)
 	}
 	hp := &HistoricalParameters{
-		Start: 1461598510,
-		End:   1461588510,
+		Start: 1461588510,
+		End:   1461598510,
 		Cnt:   1,
 	}
-	if err := h.HistoryByID(5344157, hp); err != nil {
+	if err := h.HistoryByID(5368361, hp); err != nil {
 		t.Error(err)
 	}
+	if len(h.List) == 0 {
+		t.Error("expected the history of Los Angeles")
+	}
 }
 
 // TestHistoryByCoord
 func TestHistoryByCoord(t *testing.T) {
 	t.Parallel()
-	h, err := NewHistorical("F", os.Getenv("OWM_API_KEY"))
+
+	srv, opt := newFakeServer()
+	defer srv.Close()
+
+	h, err := NewHistorical("F", "key", opt)
 	if err != nil {
-		t.Error(err)
+		t.Fatal(err)
 	}
 	coords := &Coordinates{
 		Longitude: -112.07,
 		Latitude:  33.45,
 	}
 	hp := &HistoricalParameters{
-		Start: 1461598510,
-		End:   1461588510,
+		Start: 1461588510,
+		End:   1461598510,
 		Cnt:   1,
 	}
 	if err := h.HistoryByCoord(coords, hp); err != nil {
 		t.Error(err)
 	}
+	if len(h.List) == 0 {
+		t.Error("expected the history of Phoenix")
+	}
 }
@@ -0,0 +1,186 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	errInvalidIconCode = errors.New("invalid icon code")
+	errInvalidIconSize = errors.New("icon size should be 1x, 2x or 4x")
+)
+
+// IconSize is the scale of an icon.
+type IconSize string
+
+// Icon sizes served by the API.
+const (
+	IconSize1x IconSize = ""
+	IconSize2x IconSize = "@2x"
+	IconSize4x IconSize = "@4x"
+)
+
+// validIconSize makes sure the size is one served by the API.
+func validIconSize(s IconSize) bool {
+	return s == IconSize1x || s == IconSize2x || s == IconSize4x
+}
+
+// validIconCode makes sure the code looks like "10d" or "10n".
+func validIconCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	if code[0] < '0' || code[0] > '9' || code[1] < '0' || code[1] > '9' {
+		return false
+	}
+	return code[2] == 'd' || code[2] == 'n'
+}
+
+// IconFileName returns the file name of the icon, e.g. "10d@2x.png".
+func IconFileName(code string, size IconSize) string {
+	return code + string(size) + ".png"
+}
+
+// DayNightIcons returns the day and night variants of the icon code,
+// e.g. "10n" gives "10d" and "10n".
+func DayNightIcons(code string) (string, string) {
+	if !validIconCode(code) {
+		return code, code
+	}
+	return code[:2] + "d", code[:2] + "n"
+}
+
+// IsNight reports whether the weather's icon is a night icon.
+func (w Weather) IsNight() bool {
+	return strings.HasSuffix(w.Icon, "n")
+}
+
+// IconFile returns the file name of the weather's icon for the given
+// size, e.g. "10n@2x.png".
+func (w Weather) IconFile(size IconSize) string {
+	return IconFileName(w.Icon, size)
+}
+
+// Icons is used to download the weather icons.
+type Icons struct {
+	*Settings
+}
+
+// NewIcons creates a new reference to Icons.
+func NewIcons(options ...Option) (*Icons, error) {
+	i := &Icons{
+		Settings: NewSettings(),
+	}
+
+	if err := setOptions(i.Settings, options); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+// Icon returns the PNG of the given icon code, e.g. "10d".
+func (i *Icons) Icon(code string, size IconSize) ([]byte, error) {
+	u, err := iconURLFor(code, size)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := i.client.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if err := checkPNGResponse(response); err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(response.Body)
+}
+
+// Retrieve downloads the given icon code into the destination directory
+// unless it's already there, and returns the number of bytes written.
+func (i *Icons) Retrieve(destination, code string, size IconSize) (int64, error) {
+	u, err := iconURLFor(code, size)
+	if err != nil {
+		return 0, err
+	}
+	return i.retrieve(filepath.Join(destination, IconFileName(code, size)), u)
+}
+
+// RetrieveWeather downloads the icon of the given weather into the
+// destination directory.
+func (i *Icons) RetrieveWeather(destination string, w Weather, size IconSize) (int64, error) {
+	return i.Retrieve(destination, w.Icon, size)
+}
+
+// iconURLFor builds the URL of the icon.
+func iconURLFor(code string, size IconSize) (string, error) {
+	if !validIconCode(code) {
+		return "", errInvalidIconCode
+	}
+	if !validIconSize(size) {
+		return "", errInvalidIconSize
+	}
+	return fmt.Sprintf(iconWNURL, code, size), nil
+}
+
+// retrieve downloads the URL into path. The icon is written to a
+// temporary file and renamed once complete so failed downloads never
+// leave a partial file behind.
+func (i *Icons) retrieve(path, u string) (int64, error) {
+	// Check to see if we've already gotten that icon file.  If so, use it
+	// rather than getting it again.
+	if _, err := os.Stat(path); err == nil {
+		return 0, nil
+	}
+
+	response, err := i.client.Get(u)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	if err := checkPNGResponse(response); err != nil {
+		return 0, err
+	}
+
+	b, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return 0, err
+	}
+	if err := writeFileAtomic(path, b); err != nil {
+		return 0, err
+	}
+	return int64(len(b)), nil
+}
+
+// checkPNGResponse makes sure the call was successful and the response
+// holds a PNG. Responses without a Content-Type are trusted.
+func checkPNGResponse(response *http.Response) error {
+	if err := checkResponse(response); err != nil {
+		return err
+	}
+	if ct := response.Header.Get("Content-Type"); ct != "" && !strings.HasPrefix(ct, "image/png") {
+		return errNotPNG
+	}
+	return nil
+}
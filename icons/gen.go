@@ -0,0 +1,301 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build ignore
+// +build ignore
+
+// gen.go renders the embedded icon set into the png directory. The icons
+// follow the codes and sizes of the API's icons, 50x50 for 1x and
+// 100x100 for 2x, but are original artwork drawn from simple shapes, not
+// copies of the API's icons, so they can be regenerated offline.
+//
+//	go run gen.go
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// samples is the number of sub pixels per axis used for anti-aliasing.
+const samples = 4
+
+var (
+	sunColor     = color.RGBA{R: 0xf5, G: 0xb8, B: 0x00, A: 0xff}
+	moonColor    = color.RGBA{R: 0xc9, G: 0xcf, B: 0xdc, A: 0xff}
+	cloudColor   = color.RGBA{R: 0xe4, G: 0xe7, B: 0xeb, A: 0xff}
+	outlineColor = color.RGBA{R: 0x9a, G: 0xa3, B: 0xad, A: 0xff}
+	darkColor    = color.RGBA{R: 0x8c, G: 0x96, B: 0xa3, A: 0xff}
+	rainColor    = color.RGBA{R: 0x3a, G: 0x7b, B: 0xd5, A: 0xff}
+	boltColor    = color.RGBA{R: 0xff, G: 0xd0, B: 0x1c, A: 0xff}
+	snowColor    = color.RGBA{R: 0x8c, G: 0xc4, B: 0xf2, A: 0xff}
+	mistColor    = color.RGBA{R: 0xaa, G: 0xb1, B: 0xba, A: 0xff}
+)
+
+// shape reports whether a point of the unit square is covered.
+type shape func(x, y float64) bool
+
+// layer is a shape painted with a color.
+type layer struct {
+	shape shape
+	color color.RGBA
+}
+
+func circle(cx, cy, r float64) shape {
+	return func(x, y float64) bool {
+		return (x-cx)*(x-cx)+(y-cy)*(y-cy) <= r*r
+	}
+}
+
+func rect(x0, y0, x1, y1 float64) shape {
+	return func(x, y float64) bool {
+		return x >= x0 && x <= x1 && y >= y0 && y <= y1
+	}
+}
+
+// segment is a line from a to b with the given half width.
+func segment(ax, ay, bx, by, w float64) shape {
+	return func(x, y float64) bool {
+		dx, dy := bx-ax, by-ay
+		t := ((x-ax)*dx + (y-ay)*dy) / (dx*dx + dy*dy)
+		t = math.Max(0, math.Min(1, t))
+		px, py := ax+t*dx-x, ay+t*dy-y
+		return px*px+py*py <= w*w
+	}
+}
+
+// polygon uses the even-odd rule.
+func polygon(pts ...[2]float64) shape {
+	return func(x, y float64) bool {
+		in := false
+		for i, j := 0, len(pts)-1; i < len(pts); j, i = i, i+1 {
+			xi, yi := pts[i][0], pts[i][1]
+			xj, yj := pts[j][0], pts[j][1]
+			if (yi > y) != (yj > y) && x < (xj-xi)*(y-yi)/(yj-yi)+xi {
+				in = !in
+			}
+		}
+		return in
+	}
+}
+
+func union(shapes ...shape) shape {
+	return func(x, y float64) bool {
+		for _, s := range shapes {
+			if s(x, y) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func minus(a, b shape) shape {
+	return func(x, y float64) bool { return a(x, y) && !b(x, y) }
+}
+
+// offset moves and scales a shape.
+func offset(s shape, dx, dy, scale float64) shape {
+	return func(x, y float64) bool { return s((x-dx)/scale, (y-dy)/scale) }
+}
+
+func sun(cx, cy, r float64) []layer {
+	rays := []shape{}
+	for i := 0; i < 8; i++ {
+		a := float64(i) * math.Pi / 4
+		rays = append(rays, segment(
+			cx+math.Cos(a)*r*1.3, cy+math.Sin(a)*r*1.3,
+			cx+math.Cos(a)*r*1.65, cy+math.Sin(a)*r*1.65,
+			r*0.1,
+		))
+	}
+	return []layer{
+		{union(rays...), sunColor},
+		{circle(cx, cy, r), sunColor},
+	}
+}
+
+func moon(cx, cy, r float64) []layer {
+	return []layer{{minus(circle(cx, cy, r), circle(cx+r*0.45, cy-r*0.35, r*0.85)), moonColor}}
+}
+
+// cloudShape is a cloud centered in the unit square.
+var cloudShape = union(
+	circle(0.34, 0.56, 0.14),
+	circle(0.5, 0.45, 0.19),
+	circle(0.67, 0.57, 0.13),
+	rect(0.34, 0.56, 0.67, 0.7),
+)
+
+func cloud(c color.RGBA, dx, dy, scale float64) []layer {
+	s := offset(cloudShape, dx, dy, scale)
+	grown := func(x, y float64) bool {
+		for _, d := range [][2]float64{{0.015, 0}, {-0.015, 0}, {0, 0.015}, {0, -0.015}} {
+			if s(x+d[0], y+d[1]) {
+				return true
+			}
+		}
+		return false
+	}
+	return []layer{{grown, outlineColor}, {s, c}}
+}
+
+func rain(n int) []layer {
+	drops := []shape{}
+	for i := 0; i < n; i++ {
+		x := 0.36 + float64(i)*0.28/float64(n-1)
+		drops = append(drops, segment(x, 0.76, x-0.04, 0.88, 0.022))
+	}
+	return []layer{{union(drops...), rainColor}}
+}
+
+func snow() []layer {
+	flakes := []shape{}
+	for _, p := range [][2]float64{{0.36, 0.8}, {0.5, 0.86}, {0.64, 0.8}} {
+		for i := 0; i < 3; i++ {
+			a := float64(i) * math.Pi / 3
+			dx, dy := math.Cos(a)*0.045, math.Sin(a)*0.045
+			flakes = append(flakes, segment(p[0]-dx, p[1]-dy, p[0]+dx, p[1]+dy, 0.012))
+		}
+	}
+	return []layer{{union(flakes...), snowColor}}
+}
+
+func bolt() []layer {
+	return []layer{{polygon(
+		[2]float64{0.54, 0.6}, [2]float64{0.42, 0.78}, [2]float64{0.5, 0.78},
+		[2]float64{0.44, 0.94}, [2]float64{0.62, 0.72}, [2]float64{0.53, 0.72},
+		[2]float64{0.6, 0.6},
+	), boltColor}}
+}
+
+func mist() []layer {
+	return []layer{{union(
+		segment(0.2, 0.34, 0.72, 0.34, 0.035),
+		segment(0.3, 0.48, 0.8, 0.48, 0.035),
+		segment(0.2, 0.62, 0.7, 0.62, 0.035),
+		segment(0.34, 0.76, 0.8, 0.76, 0.035),
+	), mistColor}}
+}
+
+// celestial is the sun by day and the moon by night.
+func celestial(night bool, cx, cy, r float64) []layer {
+	if night {
+		return moon(cx, cy, r)
+	}
+	return sun(cx, cy, r)
+}
+
+// icon returns the layers of the given code, bottom first.
+func icon(code string, night bool) []layer {
+	var ls []layer
+	add := func(l ...layer) { ls = append(ls, l...) }
+
+	switch code {
+	case "01":
+		add(celestial(night, 0.5, 0.5, 0.22)...)
+	case "02":
+		add(celestial(night, 0.36, 0.36, 0.15)...)
+		add(cloud(cloudColor, 0.12, 0.12, 0.85)...)
+	case "03":
+		add(cloud(cloudColor, 0, 0, 1)...)
+	case "04":
+		add(cloud(darkColor, -0.1, -0.12, 0.9)...)
+		add(cloud(cloudColor, 0.04, 0.04, 1)...)
+	case "09":
+		add(cloud(darkColor, 0.02, -0.14, 1)...)
+		add(rain(4)...)
+	case "10":
+		add(celestial(night, 0.34, 0.26, 0.13)...)
+		add(cloud(cloudColor, 0.06, -0.1, 0.95)...)
+		add(rain(3)...)
+	case "11":
+		add(cloud(darkColor, 0.02, -0.14, 1)...)
+		add(bolt()...)
+	case "13":
+		add(cloud(cloudColor, 0.02, -0.14, 1)...)
+		add(snow()...)
+	case "50":
+		add(mist()...)
+	}
+	return ls
+}
+
+// render rasterizes the layers into a size x size image.
+func render(ls []layer, size int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	for py := 0; py < size; py++ {
+		for px := 0; px < size; px++ {
+			var r, g, b, a float64
+			for sy := 0; sy < samples; sy++ {
+				for sx := 0; sx < samples; sx++ {
+					x := (float64(px) + (float64(sx)+0.5)/samples) / float64(size)
+					y := (float64(py) + (float64(sy)+0.5)/samples) / float64(size)
+					for i := len(ls) - 1; i >= 0; i-- {
+						if ls[i].shape(x, y) {
+							c := ls[i].color
+							r += float64(c.R)
+							g += float64(c.G)
+							b += float64(c.B)
+							a++
+							break
+						}
+					}
+				}
+			}
+			if a == 0 {
+				continue
+			}
+			img.SetNRGBA(px, py, color.NRGBA{
+				R: uint8(r / a),
+				G: uint8(g / a),
+				B: uint8(b / a),
+				A: uint8(a * 255 / (samples * samples)),
+			})
+		}
+	}
+	return img
+}
+
+func main() {
+	codes := []string{"01", "02", "03", "04", "09", "10", "11", "13", "50"}
+	sizes := []struct {
+		suffix string
+		px     int
+	}{{"", 50}, {"@2x", 100}}
+
+	for _, code := range codes {
+		for _, dn := range []string{"d", "n"} {
+			for _, s := range sizes {
+				name := filepath.Join("png", fmt.Sprintf("%s%s%s.png", code, dn, s.suffix))
+				f, err := os.Create(name)
+				if err != nil {
+					log.Fatalln(err)
+				}
+				if err := png.Encode(f, render(icon(code, dn == "n"), s.px)); err != nil {
+					log.Fatalln(err)
+				}
+				if err := f.Close(); err != nil {
+					log.Fatalln(err)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package icons embeds a weather icon set so icons can be used without
+// reaching openweathermap.org. The set covers every icon code the API
+// returns in Weather.Icon, day and night, at 1x (50x50) and 2x (100x100),
+// and uses the API's file names, e.g. "10d.png" and "10d@2x.png".
+//
+// The set is a substitute for the OpenWeatherMap icon set, not a copy of
+// it: the icons are original artwork rendered by gen.go from simple
+// shapes, sharing only the codes, file names and sizes of the API's icons,
+// and are covered by the license of this repository. Use owm.NewIcons to
+// retrieve OpenWeatherMap's own icons.
+package icons
+
+//go:generate go run gen.go
+
+import (
+	"embed"
+	"errors"
+	"io/fs"
+	"net/http"
+
+	owm "github.com/briandowns/openweathermap"
+)
+
+var errUnavailable = errors.New("icon unavailable")
+
+//go:embed png/*.png
+var files embed.FS
+
+// FS holds the icons at its root, named like the API's icons.
+var FS fs.FS
+
+func init() {
+	var err error
+	if FS, err = fs.Sub(files, "png"); err != nil {
+		panic(err)
+	}
+}
+
+// Codes holds every icon code of the set.
+var Codes = []string{
+	"01d", "01n", "02d", "02n", "03d", "03n",
+	"04d", "04n", "09d", "09n", "10d", "10n",
+	"11d", "11n", "13d", "13n", "50d", "50n",
+}
+
+// Icon returns the PNG of the given icon code, e.g. the Icon field of a
+// Weather. Only the 1x and 2x sizes are embedded.
+func Icon(code string, size owm.IconSize) ([]byte, error) {
+	if size != owm.IconSize1x && size != owm.IconSize2x {
+		return nil, errUnavailable
+	}
+	b, err := fs.ReadFile(FS, owm.IconFileName(code, size))
+	if err != nil {
+		return nil, errUnavailable
+	}
+	return b, nil
+}
+
+// WeatherIcon returns the PNG of the weather's icon.
+func WeatherIcon(w owm.Weather, size owm.IconSize) ([]byte, error) {
+	return Icon(w.Icon, size)
+}
+
+// Handler serves the icons by file name, e.g. "/10d@2x.png". Mount it
+// with http.StripPrefix to serve it under a path.
+func Handler() http.Handler {
+	files := http.FileServer(http.FS(FS))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the set never changes for a given build
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+		files.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,111 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package icons
+
+import (
+	"bytes"
+	"image/png"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	owm "github.com/briandowns/openweathermap"
+)
+
+// TestIcon will verify that every code is embedded at both sizes
+func TestIcon(t *testing.T) {
+	t.Parallel()
+
+	sizes := map[owm.IconSize]int{owm.IconSize1x: 50, owm.IconSize2x: 100}
+	for _, code := range Codes {
+		for size, px := range sizes {
+			b, err := Icon(code, size)
+			if err != nil {
+				t.Fatalf("%s%s: %v", code, size, err)
+			}
+			img, err := png.Decode(bytes.NewReader(b))
+			if err != nil {
+				t.Fatalf("%s%s: %v", code, size, err)
+			}
+			if img.Bounds().Dx() != px || img.Bounds().Dy() != px {
+				t.Errorf("%s%s: unexpected bounds %v", code, size, img.Bounds())
+			}
+		}
+	}
+
+	if _, err := Icon("01d", owm.IconSize4x); err != errUnavailable {
+		t.Errorf("expected %v, got %v", errUnavailable, err)
+	}
+	if _, err := Icon("99x", owm.IconSize1x); err != errUnavailable {
+		t.Errorf("expected %v, got %v", errUnavailable, err)
+	}
+}
+
+// TestConditionIcons will verify that every icon of the condition tables
+// is part of the set
+func TestConditionIcons(t *testing.T) {
+	t.Parallel()
+
+	for _, icon := range owm.IconList {
+		for _, name := range []string{icon.Day, icon.Night} {
+			if _, err := Icon(name[:3], owm.IconSize1x); err != nil {
+				t.Errorf("%s: %v", name, err)
+			}
+		}
+	}
+
+	if _, err := WeatherIcon(owm.Weather{Icon: "10n"}, owm.IconSize2x); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestHandler will verify that the icons are served over http
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.StripPrefix("/static/img/", Handler()))
+	defer srv.Close()
+
+	response, err := http.Get(srv.URL + "/static/img/13n@2x.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status %d", response.StatusCode)
+	}
+	if ct := response.Header.Get("Content-Type"); ct != "image/png" {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	b, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := Icon("13n", owm.IconSize2x)
+	if !bytes.Equal(b, want) {
+		t.Error("unexpected icon content")
+	}
+
+	missing, err := http.Get(srv.URL + "/static/img/nope.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	missing.Body.Close()
+	if missing.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", missing.StatusCode)
+	}
+}
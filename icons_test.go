@@ -0,0 +1,180 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"bytes"
+	"image/color"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// iconServer serves a png for every icon path and fails for the rest.
+func iconServer(t *testing.T, icon []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/img/wn/10n@2x.png", "/img/wn/01d@4x.png", "/img/wn/01d.png", "/img/w/01d.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(icon)
+		case "/img/wn/02d.png":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html></html>"))
+		case "/img/wn/03d.png":
+			// advertise more than is sent so the download fails midway
+			w.Header().Set("Content-Type", "image/png")
+			w.Header().Set("Content-Length", "100000")
+			w.Write(icon[:10])
+		default:
+			http.NotFound(w, r)
+		}
+	}
+}
+
+// TestIcons will verify that icons are downloaded through the configured
+// http client
+func TestIcons(t *testing.T) {
+	t.Parallel()
+
+	icon := pngTile(t, color.RGBA{G: 255, A: 255})
+	srv, opt := newTestServer(t, iconServer(t, icon))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "owm-icons")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	i, err := NewIcons(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := i.Icon("01d", IconSize4x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, icon) {
+		t.Error("unexpected icon content")
+	}
+
+	w := Weather{ID: 500, Icon: "10n"}
+	n, err := i.RetrieveWeather(dir, w, IconSize2x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Stat(filepath.Join(dir, "10n@2x.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Size() != n {
+		t.Error("size of downloaded file does not match actual size of file")
+	}
+
+	// already downloaded
+	if n, err := i.Retrieve(dir, "10n", IconSize2x); err != nil || n != 0 {
+		t.Errorf("expected the icon to be reused, got %d, %v", n, err)
+	}
+
+	if _, err := i.Icon("02d", IconSize1x); err != errNotPNG {
+		t.Errorf("expected %v, got %v", errNotPNG, err)
+	}
+	if _, err := i.Icon("10x", IconSize1x); err != errInvalidIconCode {
+		t.Errorf("expected %v, got %v", errInvalidIconCode, err)
+	}
+	if _, err := i.Icon("10d", IconSize("@3x")); err != errInvalidIconSize {
+		t.Errorf("expected %v, got %v", errInvalidIconSize, err)
+	}
+}
+
+// TestIconsNoPartialFiles will verify that failed downloads don't leave
+// anything behind
+func TestIconsNoPartialFiles(t *testing.T) {
+	t.Parallel()
+
+	srv, opt := newTestServer(t, iconServer(t, pngTile(t, color.Black)))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "owm-icons")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	i, err := NewIcons(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, code := range []string{"02d", "03d", "04d"} {
+		if _, err := i.Retrieve(dir, code, IconSize1x); err == nil {
+			t.Errorf("%s: expected an error", code)
+		}
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Errorf("expected no files, got %d", len(files))
+	}
+}
+
+// TestRetrieveIconWithOptions will verify that the legacy icons respect
+// the given options
+func TestRetrieveIconWithOptions(t *testing.T) {
+	t.Parallel()
+
+	icon := pngTile(t, color.White)
+	srv, opt := newTestServer(t, iconServer(t, icon))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "owm-icons")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	n, err := RetrieveIcon(dir, "01d.png", opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(icon)) {
+		t.Errorf("expected %d bytes, got %d", len(icon), n)
+	}
+}
+
+// TestDayNightIcons will verify the mapping of day and night icons
+func TestDayNightIcons(t *testing.T) {
+	t.Parallel()
+
+	day, night := DayNightIcons("10n")
+	if day != "10d" || night != "10n" {
+		t.Errorf("unexpected icons %s, %s", day, night)
+	}
+	if !(Weather{Icon: "01n"}).IsNight() || (Weather{Icon: "01d"}).IsNight() {
+		t.Error("unexpected night detection")
+	}
+
+	for _, c := range CloudConditions {
+		if c.Icon2 == "" || c.Icon2[2] != 'n' || c.Icon1[:2] != c.Icon2[:2] {
+			t.Errorf("%d: unexpected night icon %q", c.ID, c.Icon2)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"context"
+	"time"
+)
+
+// CurrentWeather is the current weather API, implemented by
+// CurrentWeatherData. The calls fetch into the value returned by Result.
+type CurrentWeather interface {
+	Result() *CurrentWeatherData
+	CurrentByName(location string) error
+	CurrentByCoordinates(location *Coordinates) error
+	CurrentByID(id int) error
+	CurrentByZip(zip int, countryCode string) error
+	CurrentByZipcode(zip string, countryCode string) error
+	Watch(ctx context.Context, location Location, interval time.Duration) (<-chan WeatherUpdate, error)
+}
+
+// Group is the current weather API for several cities at once,
+// implemented by CurrentWeatherGroup.
+type Group interface {
+	Result() *CurrentWeatherGroup
+	CurrentByIDs(ids ...int) error
+}
+
+// OneCaller is the One Call API, implemented by OneCallData.
+type OneCaller interface {
+	Result() *OneCallData
+	OneCallByCoordinates(location *Coordinates) error
+	OneCallTimeMachine(location *Coordinates, datetime time.Time) error
+	TimeMachineRange(ctx context.Context, location *Coordinates, start, end time.Time, step time.Duration) ([]OneCallTimeMachineData, error)
+	DaySummary(location *Coordinates, date time.Time, tz string) (*OneCallDaySummary, error)
+	Overview(location *Coordinates, date time.Time, tz string) (*OneCallOverview, error)
+}
+
+// AirPollution is the air pollution API, implemented by Pollution.
+type AirPollution interface {
+	Result() *Pollution
+	PollutionByParams(params *PollutionParameters) error
+}
+
+// UVIndex is the UV index API, implemented by UV.
+type UVIndex interface {
+	Result() *UV
+	Current(coord *Coordinates) error
+	Historical(coord *Coordinates, start, end time.Time) error
+	UVInformation() ([]UVIndexInfo, error)
+}
+
+// History is the historical weather API, implemented by
+// HistoricalWeatherData.
+type History interface {
+	Result() *HistoricalWeatherData
+	HistoryByName(location string) error
+	HistoryByID(id int, hp ...*HistoricalParameters) error
+	HistoryByCoord(location *Coordinates, hp *HistoricalParameters) error
+}
+
+// Forecaster is ForecastWeather along with the forecast fetched, a
+// *Forecast5WeatherData or a *Forecast16WeatherData, implemented by
+// ForecastWeatherData.
+type Forecaster interface {
+	ForecastWeather
+	Result() ForecastWeatherJson
+}
+
+// Result returns the weather fetched by the last call.
+func (w *CurrentWeatherData) Result() *CurrentWeatherData { return w }
+
+// Result returns the weather of the cities fetched by the last call.
+func (g *CurrentWeatherGroup) Result() *CurrentWeatherGroup { return g }
+
+// Result returns the data fetched by the last call.
+func (w *OneCallData) Result() *OneCallData { return w }
+
+// Result returns the air pollution fetched by the last call.
+func (p *Pollution) Result() *Pollution { return p }
+
+// Result returns the UV index fetched by the last call.
+func (u *UV) Result() *UV { return u }
+
+// Result returns the history fetched by the last call.
+func (h *HistoricalWeatherData) Result() *HistoricalWeatherData { return h }
+
+// Result returns the forecast fetched by the last call, a
+// *Forecast5WeatherData or a *Forecast16WeatherData depending on the
+// forecast type.
+func (f *ForecastWeatherData) Result() ForecastWeatherJson { return f.ForecastWeatherJson }
+
+var (
+	_ CurrentWeather  = (*CurrentWeatherData)(nil)
+	_ Group           = (*CurrentWeatherGroup)(nil)
+	_ OneCaller       = (*OneCallData)(nil)
+	_ AirPollution    = (*Pollution)(nil)
+	_ UVIndex         = (*UV)(nil)
+	_ History         = (*HistoricalWeatherData)(nil)
+	_ ForecastWeather = (*ForecastWeatherData)(nil)
+	_ Forecaster      = (*ForecastWeatherData)(nil)
+)
@@ -0,0 +1,64 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"testing"
+)
+
+// TestResults will verify that the results fetched are read through the
+// interfaces
+func TestResults(t *testing.T) {
+	t.Parallel()
+
+	srv, opt := newFakeServer()
+	defer srv.Close()
+
+	w, err := NewCurrent("C", "EN", "key", opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c CurrentWeather = w
+	if err := c.CurrentByName("Phoenix"); err != nil {
+		t.Fatal(err)
+	}
+	if r := c.Result(); r.Name != "Phoenix" || r.Main.Temp == 0 {
+		t.Errorf("unexpected result %+v", r)
+	}
+
+	fd, err := NewForecast("5", "C", "EN", "key", opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var f Forecaster = fd
+	if err := f.DailyByID(5308655, 8); err != nil {
+		t.Fatal(err)
+	}
+	if r, ok := f.Result().(*Forecast5WeatherData); !ok || len(r.List) != 8 || r.City.Name != "Phoenix" {
+		t.Errorf("unexpected forecast %+v", f.Result())
+	}
+
+	od, err := NewOneCall("C", "EN", "key", nil, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var o OneCaller = od
+	if err := o.OneCallByCoordinates(&Coordinates{Latitude: 33.45, Longitude: -112.07}); err != nil {
+		t.Fatal(err)
+	}
+	if r := o.Result(); r.Timezone != "America/Phoenix" {
+		t.Errorf("unexpected one call %+v", r)
+	}
+}
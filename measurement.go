@@ -0,0 +1,287 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+var (
+	errNoMeasurements       = errors.New("no measurements given")
+	errInvalidAggregation   = errors.New("aggregation type should be one of m, h or d")
+	errMissingStationID     = errors.New("station_id is required")
+	errInvalidMeasurementDt = errors.New("dt is required")
+)
+
+// Aggregation types supported when retrieving measurements.
+const (
+	AggregateMinute = "m"
+	AggregateHour   = "h"
+	AggregateDay    = "d"
+)
+
+// Cloud condition codes accepted in a measurement.
+var MeasurementCloudConditions = []string{"NSC", "FEW", "SCT", "BKN", "OVC", "SKC", "CLR"}
+
+// MeasurementCloud describes a cloud layer observed by a station.
+type MeasurementCloud struct {
+	Distance  *float64 `json:"distance,omitempty"` // Distance to the layer in meters
+	Condition string   `json:"condition,omitempty"`
+	Cumulus   string   `json:"cumulus,omitempty"`
+}
+
+// Measurement holds a single observation sent by a station to the
+// "/data/3.0/measurements" endpoint. Every value besides StationID and Dt is
+// optional and only sent when set. All values are expected in metric units.
+type Measurement struct {
+	StationID          string             `json:"station_id"`
+	Dt                 int64              `json:"dt"`
+	Temperature        *float64           `json:"temperature,omitempty"`         // Celsius
+	WindSpeed          *float64           `json:"wind_speed,omitempty"`          // m/s
+	WindGust           *float64           `json:"wind_gust,omitempty"`           // m/s
+	WindDeg            *float64           `json:"wind_deg,omitempty"`            // degrees
+	Pressure           *float64           `json:"pressure,omitempty"`            // hPa
+	Humidity           *float64           `json:"humidity,omitempty"`            // %
+	Rain1h             *float64           `json:"rain_1h,omitempty"`             // mm
+	Rain6h             *float64           `json:"rain_6h,omitempty"`             // mm
+	Rain24h            *float64           `json:"rain_24h,omitempty"`            // mm
+	Snow1h             *float64           `json:"snow_1h,omitempty"`             // mm
+	Snow6h             *float64           `json:"snow_6h,omitempty"`             // mm
+	Snow24h            *float64           `json:"snow_24h,omitempty"`            // mm
+	VisibilityDistance *float64           `json:"visibility_distance,omitempty"` // km
+	VisibilityPrefix   string             `json:"visibility_prefix,omitempty"`   // N, E or P
+	DewPoint           *float64           `json:"dew_point,omitempty"`           // Celsius
+	Humidex            *float64           `json:"humidex,omitempty"`             // Celsius
+	HeatIndex          *float64           `json:"heat_index,omitempty"`          // Celsius
+	Clouds             []MeasurementCloud `json:"clouds,omitempty"`
+}
+
+// Float64 is a helper to set the optional values of a Measurement.
+func Float64(v float64) *float64 { return &v }
+
+// measurementRange holds the accepted bounds of a measured value.
+type measurementRange struct {
+	name     string
+	min, max float64
+}
+
+// check makes sure the value, when set, is within the range.
+func (r measurementRange) check(v *float64) error {
+	if v == nil {
+		return nil
+	}
+	if *v < r.min || *v > r.max {
+		return fmt.Errorf("%s %g out of range [%g, %g]", r.name, *v, r.min, r.max)
+	}
+	return nil
+}
+
+// Validate makes sure the measurement can be accepted by the API.
+func (m *Measurement) Validate() error {
+	if m.StationID == "" {
+		return errMissingStationID
+	}
+	if m.Dt <= 0 {
+		return errInvalidMeasurementDt
+	}
+
+	checks := []struct {
+		r measurementRange
+		v *float64
+	}{
+		{measurementRange{"temperature", -90, 60}, m.Temperature},
+		{measurementRange{"wind_speed", 0, 120}, m.WindSpeed},
+		{measurementRange{"wind_gust", 0, 120}, m.WindGust},
+		{measurementRange{"wind_deg", 0, 360}, m.WindDeg},
+		{measurementRange{"pressure", 300, 1100}, m.Pressure},
+		{measurementRange{"humidity", 0, 100}, m.Humidity},
+		{measurementRange{"rain_1h", 0, 500}, m.Rain1h},
+		{measurementRange{"rain_6h", 0, 1000}, m.Rain6h},
+		{measurementRange{"rain_24h", 0, 2000}, m.Rain24h},
+		{measurementRange{"snow_1h", 0, 500}, m.Snow1h},
+		{measurementRange{"snow_6h", 0, 1000}, m.Snow6h},
+		{measurementRange{"snow_24h", 0, 2000}, m.Snow24h},
+		{measurementRange{"visibility_distance", 0, 500}, m.VisibilityDistance},
+		{measurementRange{"dew_point", -90, 60}, m.DewPoint},
+		{measurementRange{"humidex", -90, 80}, m.Humidex},
+		{measurementRange{"heat_index", -90, 80}, m.HeatIndex},
+	}
+	for _, c := range checks {
+		if err := c.r.check(c.v); err != nil {
+			return err
+		}
+	}
+
+	switch m.VisibilityPrefix {
+	case "", "N", "E", "P":
+	default:
+		return fmt.Errorf("visibility_prefix %q should be one of N, E or P", m.VisibilityPrefix)
+	}
+
+	for _, c := range m.Clouds {
+		if err := (measurementRange{"clouds distance", 0, 30000}).check(c.Distance); err != nil {
+			return err
+		}
+		if c.Condition != "" && !validCloudCondition(c.Condition) {
+			return fmt.Errorf("cloud condition %q unavailable", c.Condition)
+		}
+	}
+
+	return nil
+}
+
+// validCloudCondition makes sure the given condition is a known one.
+func validCloudCondition(condition string) bool {
+	for _, c := range MeasurementCloudConditions {
+		if c == condition {
+			return true
+		}
+	}
+	return false
+}
+
+// MeasurementParameters holds the parameters to retrieve aggregated
+// measurements for a station.
+type MeasurementParameters struct {
+	StationID string
+	Type      string // AggregateMinute, AggregateHour or AggregateDay
+	Limit     int
+	From      int64 // unix time, UTC time zone
+	To        int64 // unix time, UTC time zone
+}
+
+// AggregatedValue holds the aggregation of a measured value.
+type AggregatedValue struct {
+	Min     float64 `json:"min"`
+	Max     float64 `json:"max"`
+	Average float64 `json:"average"`
+	Weight  int     `json:"weight"`
+}
+
+// AggregatedMeasurement holds the aggregated data returned for a station.
+type AggregatedMeasurement struct {
+	Type      string          `json:"type"`
+	Date      int64           `json:"date"`
+	StationID string          `json:"station_id"`
+	Temp      AggregatedValue `json:"temp"`
+	Humidity  AggregatedValue `json:"humidity"`
+	Pressure  AggregatedValue `json:"pressure"`
+	Wind      struct {
+		Deg   float64 `json:"deg"`
+		Speed float64 `json:"speed"`
+	} `json:"wind"`
+	Precipitation struct {
+		Rain float64 `json:"rain"`
+		Snow float64 `json:"snow"`
+	} `json:"precipitation"`
+}
+
+// Measurements is used to send and retrieve station measurements.
+type Measurements struct {
+	Key string
+	*Settings
+}
+
+// NewMeasurements creates a new reference to Measurements.
+func NewMeasurements(key string, options ...Option) (*Measurements, error) {
+	k, err := setKey(key)
+	if err != nil {
+		return nil, err
+	}
+	m := &Measurements{
+		Key:      k,
+		Settings: NewSettings(),
+	}
+
+	if err := setOptions(m.Settings, options); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Send validates and posts the given measurements to the API in a
+// single batch.
+func (m *Measurements) Send(measurements []Measurement) error {
+	if len(measurements) == 0 {
+		return errNoMeasurements
+	}
+	for i := range measurements {
+		if err := measurements[i].Validate(); err != nil {
+			return fmt.Errorf("measurement %d: %w", i, err)
+		}
+	}
+
+	body, err := json.Marshal(measurements)
+	if err != nil {
+		return err
+	}
+
+	v := url.Values{}
+	v.Set("appid", m.Key)
+	response, err := m.client.Post(fmt.Sprintf(measurementURL, v.Encode()), "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return checkResponse(response)
+}
+
+// Aggregated retrieves the aggregated measurements of a station.
+func (m *Measurements) Aggregated(params *MeasurementParameters) ([]AggregatedMeasurement, error) {
+	if params == nil || params.StationID == "" {
+		return nil, errMissingStationID
+	}
+	switch params.Type {
+	case AggregateMinute, AggregateHour, AggregateDay:
+	default:
+		return nil, errInvalidAggregation
+	}
+
+	v := url.Values{}
+	v.Set("appid", m.Key)
+	v.Set("station_id", params.StationID)
+	v.Set("type", params.Type)
+	if params.Limit > 0 {
+		v.Set("limit", strconv.Itoa(params.Limit))
+	}
+	if params.From > 0 {
+		v.Set("from", strconv.FormatInt(params.From, 10))
+	}
+	if params.To > 0 {
+		v.Set("to", strconv.FormatInt(params.To, 10))
+	}
+
+	response, err := m.client.Get(fmt.Sprintf(measurementURL, v.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if err := checkResponse(response); err != nil {
+		return nil, err
+	}
+
+	var data []AggregatedMeasurement
+	if err := json.NewDecoder(response.Body).Decode(&data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,176 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// TestMeasurementValidate will verify that measurements are validated
+// before being sent
+func TestMeasurementValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		m    Measurement
+		ok   bool
+	}{
+		{"valid", Measurement{StationID: "abc", Dt: 1479817340, Temperature: Float64(18.7), Humidity: Float64(87)}, true},
+		{"zero temperature", Measurement{StationID: "abc", Dt: 1479817340, Temperature: Float64(0)}, true},
+		{"missing station", Measurement{Dt: 1479817340}, false},
+		{"missing dt", Measurement{StationID: "abc"}, false},
+		{"humidity", Measurement{StationID: "abc", Dt: 1, Humidity: Float64(101)}, false},
+		{"wind direction", Measurement{StationID: "abc", Dt: 1, WindDeg: Float64(-1)}, false},
+		{"pressure", Measurement{StationID: "abc", Dt: 1, Pressure: Float64(2000)}, false},
+		{"visibility prefix", Measurement{StationID: "abc", Dt: 1, VisibilityPrefix: "X"}, false},
+		{"cloud condition", Measurement{StationID: "abc", Dt: 1, Clouds: []MeasurementCloud{{Condition: "XYZ"}}}, false},
+		{"clouds", Measurement{StationID: "abc", Dt: 1, Clouds: []MeasurementCloud{{Condition: "BKN", Distance: Float64(1200)}}}, true},
+	}
+
+	for _, tt := range tests {
+		err := tt.m.Validate()
+		if tt.ok && err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+		if !tt.ok && err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+// TestMeasurementsSend will verify that measurements are posted as a batch
+func TestMeasurementsSend(t *testing.T) {
+	t.Parallel()
+
+	var received []map[string]interface{}
+	srv, opt := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/data/3.0/measurements" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.URL.Query().Get("appid") != "key" {
+			t.Error("missing appid")
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	m, err := NewMeasurements("key", opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = m.Send([]Measurement{
+		{StationID: "abc", Dt: 1479817340, Temperature: Float64(0), WindSpeed: Float64(1.2)},
+		{StationID: "abc", Dt: 1479817400, Pressure: Float64(1021)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(received) != 2 {
+		t.Fatalf("expected 2 measurements, got %d", len(received))
+	}
+	if _, ok := received[0]["temperature"]; !ok {
+		t.Error("zero temperature should be sent")
+	}
+	if _, ok := received[1]["temperature"]; ok {
+		t.Error("unset temperature should not be sent")
+	}
+
+	if err := m.Send(nil); err != errNoMeasurements {
+		t.Errorf("expected %v, got %v", errNoMeasurements, err)
+	}
+	if err := m.Send([]Measurement{{Dt: 1}}); err == nil {
+		t.Error("expected validation error")
+	}
+}
+
+// TestMeasurementsAggregated will verify that aggregated measurements are
+// retrieved with the given parameters
+func TestMeasurementsAggregated(t *testing.T) {
+	t.Parallel()
+
+	srv, opt := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("station_id") != "abc" || q.Get("type") != "h" || q.Get("limit") != "10" ||
+			q.Get("from") != "1469817340" || q.Get("to") != "1469827340" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`[{"type":"h","date":1469817300,"station_id":"abc",
+			"temp":{"max":18.7,"min":17.1,"average":18,"weight":3},
+			"humidity":{"average":87,"weight":3},
+			"wind":{"deg":120,"speed":1.2},
+			"pressure":{"min":1021,"max":1023,"average":1022,"weight":3},
+			"precipitation":{"rain":2}}]`))
+	}))
+	defer srv.Close()
+
+	m, err := NewMeasurements("key", opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := m.Aggregated(&MeasurementParameters{
+		StationID: "abc",
+		Type:      AggregateHour,
+		Limit:     10,
+		From:      1469817340,
+		To:        1469827340,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 1 || data[0].Temp.Max != 18.7 || data[0].Precipitation.Rain != 2 {
+		t.Errorf("unexpected data %+v", data)
+	}
+
+	if _, err := m.Aggregated(nil); err != errMissingStationID {
+		t.Errorf("expected %v, got %v", errMissingStationID, err)
+	}
+	if _, err := m.Aggregated(&MeasurementParameters{StationID: "abc", Type: "y"}); err != errInvalidAggregation {
+		t.Errorf("expected %v, got %v", errInvalidAggregation, err)
+	}
+}
+
+// TestMeasurementsAPIError will verify that failed calls are reported
+func TestMeasurementsAPIError(t *testing.T) {
+	t.Parallel()
+
+	srv, opt := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"cod":404,"message":"Station not found"}`))
+	}))
+	defer srv.Close()
+
+	m, err := NewMeasurements("key", opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = m.Aggregated(&MeasurementParameters{StationID: "abc", Type: AggregateDay})
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		t.Fatalf("expected *APIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound || apiErr.COD != "404" || apiErr.Message != "Station not found" {
+		t.Errorf("unexpected error %+v", apiErr)
+	}
+}
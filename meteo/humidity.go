@@ -0,0 +1,111 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package meteo
+
+import (
+	"math"
+
+	"github.com/briandowns/openweathermap/units"
+)
+
+// waterVaporGasConstant is the specific gas constant of water vapor in
+// J/(kg·K).
+const waterVaporGasConstant = 461.5
+
+// buck holds the constants of the Arden Buck saturation vapor pressure
+// equation, es = a·exp((b - T/d)·(T/(c + T))) in hPa with T in Celsius.
+type buck struct {
+	a, b, c, d float64
+}
+
+// Constants over water and ice from Buck (1996).
+var (
+	buckWater = buck{6.1121, 18.678, 257.14, 234.5}
+	buckIce   = buck{6.1115, 23.036, 279.82, 333.7}
+)
+
+// pressure returns the saturation vapor pressure in hPa.
+func (k buck) pressure(c float64) float64 {
+	return k.a * math.Exp((k.b-c/k.d)*(c/(k.c+c)))
+}
+
+// temperature returns the temperature in Celsius at which e hPa is the
+// saturation vapor pressure. It starts from the Magnus form of the
+// equation and refines the b - T/d term, which converges in a few
+// steps.
+func (k buck) temperature(e float64) float64 {
+	g := math.Log(e / k.a)
+	t := k.c * g / (k.b - g)
+	for i := 0; i < 5; i++ {
+		b := k.b - t/k.d
+		t = k.c * g / (b - g)
+	}
+	return t
+}
+
+// hPa returns the value as a pressure in hPa.
+func hPa(v float64) units.Pressure {
+	return units.Pressure{Value: v, Unit: units.Hectopascal}
+}
+
+// SaturationVaporPressure returns the saturation vapor pressure over
+// water using the Arden Buck equation.
+func SaturationVaporPressure(t units.Temperature) units.Pressure {
+	return hPa(buckWater.pressure(t.Celsius()))
+}
+
+// SaturationVaporPressureIce returns the saturation vapor pressure over
+// ice using the Arden Buck equation.
+func SaturationVaporPressureIce(t units.Temperature) units.Pressure {
+	return hPa(buckIce.pressure(t.Celsius()))
+}
+
+// VaporPressure returns the partial pressure of water vapor in the air.
+// As reported by weather stations, relative humidity is taken with
+// respect to water at every temperature.
+func VaporPressure(t units.Temperature, rh float64) units.Pressure {
+	return hPa(rh / 100 * buckWater.pressure(t.Celsius()))
+}
+
+// DewPoint returns the temperature at which the air becomes saturated
+// with respect to water. The relative humidity should be above 0.
+func DewPoint(t units.Temperature, rh float64) units.Temperature {
+	e := rh / 100 * buckWater.pressure(t.Celsius())
+	return fromCelsius(buckWater.temperature(e), t)
+}
+
+// FrostPoint returns the temperature at which the air becomes saturated
+// with respect to ice. It's higher than the dew point below freezing and
+// the dew point is returned when above it.
+func FrostPoint(t units.Temperature, rh float64) units.Temperature {
+	e := rh / 100 * buckWater.pressure(t.Celsius())
+	f := buckIce.temperature(e)
+	if f > 0 {
+		return DewPoint(t, rh)
+	}
+	return fromCelsius(f, t)
+}
+
+// RelativeHumidity returns the relative humidity, in percent, of air at
+// the given temperature and dew point.
+func RelativeHumidity(t, dewPoint units.Temperature) float64 {
+	return 100 * buckWater.pressure(dewPoint.Celsius()) / buckWater.pressure(t.Celsius())
+}
+
+// AbsoluteHumidity returns the mass of water vapor in the air in g/m³.
+func AbsoluteHumidity(t units.Temperature, rh float64) float64 {
+	e := rh / 100 * buckWater.pressure(t.Celsius()) * 100 // Pa
+	return e / (waterVaporGasConstant * t.Kelvin()) * 1000
+}
@@ -0,0 +1,125 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package meteo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/briandowns/openweathermap/units"
+)
+
+// TestSaturationVaporPressure will verify the vapor pressures against the
+// values tabulated by Buck
+func TestSaturationVaporPressure(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		temp float64
+		ice  bool
+		want float64
+	}{
+		{20, false, 23.38},
+		{100, false, 1013.1},
+		{-10, false, 2.866},
+		{-10, true, 2.599},
+		{-30, true, 0.380},
+	}
+	for _, tt := range tests {
+		got := SaturationVaporPressure(celsius(tt.temp))
+		if tt.ice {
+			got = SaturationVaporPressureIce(celsius(tt.temp))
+		}
+		if got.Unit != units.Hectopascal || math.Abs(got.Value-tt.want)/tt.want > 0.002 {
+			t.Errorf("%v°C (ice %v): expected %v hPa, got %v", tt.temp, tt.ice, tt.want, got)
+		}
+	}
+
+	if e := VaporPressure(celsius(20), 50); math.Abs(e.Value-11.69) > 0.01 {
+		t.Errorf("expected 11.69 hPa, got %v", e)
+	}
+}
+
+// TestDewPoint will verify the dew point and that it round trips through
+// the relative humidity
+func TestDewPoint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		temp, rh, want float64
+	}{
+		{20, 50, 9.3},
+		{30, 40, 14.9},
+		{25, 100, 25},
+		{-10, 80, -12.8},
+	}
+	for _, tt := range tests {
+		got := DewPoint(celsius(tt.temp), tt.rh)
+		if math.Abs(got.Value-tt.want) > 0.05 {
+			t.Errorf("%v°C at %v%%: expected %v, got %v", tt.temp, tt.rh, tt.want, got)
+		}
+		if rh := RelativeHumidity(celsius(tt.temp), got); math.Abs(rh-tt.rh) > 1e-6 {
+			t.Errorf("%v°C: expected %v%% back, got %v", tt.temp, tt.rh, rh)
+		}
+	}
+
+	if d := DewPoint(fahrenheit(68), 50); d.Unit != units.Fahrenheit || math.Abs(d.Value-48.7) > 0.05 {
+		t.Errorf("expected 48.7°F, got %v", d)
+	}
+}
+
+// TestFrostPoint will verify the frost point lies between the dew point
+// and the air temperature below freezing
+func TestFrostPoint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		temp, rh, want float64
+	}{
+		{-10, 80, -11.4},
+		{-20, 60, -23.2},
+	}
+	for _, tt := range tests {
+		got := FrostPoint(celsius(tt.temp), tt.rh)
+		if math.Abs(got.Value-tt.want) > 0.05 {
+			t.Errorf("%v°C at %v%%: expected %v, got %v", tt.temp, tt.rh, tt.want, got)
+		}
+		if d := DewPoint(celsius(tt.temp), tt.rh); got.Value <= d.Value || got.Value > tt.temp {
+			t.Errorf("%v°C: frost point %v should be above dew point %v", tt.temp, got, d)
+		}
+	}
+
+	if f, d := FrostPoint(celsius(20), 50), DewPoint(celsius(20), 50); f != d {
+		t.Errorf("expected the dew point above freezing, got %v", f)
+	}
+}
+
+// TestAbsoluteHumidity will verify the mass of water vapor in the air
+func TestAbsoluteHumidity(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		temp, rh, want float64
+	}{
+		{20, 50, 8.64},
+		{30, 80, 24.27},
+		{0, 100, 4.85},
+	}
+	for _, tt := range tests {
+		if got := AbsoluteHumidity(celsius(tt.temp), tt.rh); math.Abs(got-tt.want) > 0.01 {
+			t.Errorf("%v°C at %v%%: expected %v g/m³, got %v", tt.temp, tt.rh, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package meteo derives values from the measured ones: heat index, wind
+// chill, humidex, apparent temperature, wet-bulb temperature and the
+// moisture of the air. Temperatures and speeds are taken in any unit and
+// results are returned in the unit of the given temperature. Relative
+// humidity is a percentage between 0 and 100.
+package meteo
+
+import (
+	"math"
+
+	"github.com/briandowns/openweathermap/units"
+)
+
+// fromCelsius returns the value, given in Celsius, as a temperature in
+// the unit of t.
+func fromCelsius(c float64, t units.Temperature) units.Temperature {
+	return units.Temperature{Value: c, Unit: units.Celsius}.In(t.Unit)
+}
+
+// fromFahrenheit returns the value, given in Fahrenheit, as a temperature
+// in the unit of t.
+func fromFahrenheit(f float64, t units.Temperature) units.Temperature {
+	return units.Temperature{Value: f, Unit: units.Fahrenheit}.In(t.Unit)
+}
+
+// HeatIndex returns the heat index using the NWS Rothfusz regression
+// along with its adjustments for low and high humidity. Below 80°F the
+// simpler Steadman formula used by the NWS is returned instead.
+func HeatIndex(t units.Temperature, rh float64) units.Temperature {
+	f := t.Fahrenheit()
+
+	hi := 0.5 * (f + 61 + (f-68)*1.2 + rh*0.094)
+	if (hi+f)/2 < 80 {
+		return fromFahrenheit(hi, t)
+	}
+
+	hi = -42.379 + 2.04901523*f + 10.14333127*rh -
+		0.22475541*f*rh - 0.00683783*f*f - 0.05481717*rh*rh +
+		0.00122874*f*f*rh + 0.00085282*f*rh*rh - 0.00000199*f*f*rh*rh
+
+	switch {
+	case rh < 13 && f >= 80 && f <= 112:
+		hi -= (13 - rh) / 4 * math.Sqrt((17-math.Abs(f-95))/17)
+	case rh > 85 && f >= 80 && f <= 87:
+		hi += (rh - 85) / 10 * (87 - f) / 5
+	}
+	return fromFahrenheit(hi, t)
+}
+
+// WindChill returns the wind chill using the formula shared by the NWS
+// and Environment Canada. It's only defined at or below 10°C (50°F) with
+// winds of at least 4.8 km/h (3 mph); the air temperature is returned
+// otherwise.
+func WindChill(t units.Temperature, wind units.Speed) units.Temperature {
+	c := t.Celsius()
+	v := wind.In(units.KilometersPerHour).Value
+	if c > 10 || v < 4.8 {
+		return t
+	}
+
+	p := math.Pow(v, 0.16)
+	return fromCelsius(13.12+0.6215*c-11.37*p+0.3965*c*p, t)
+}
+
+// Humidex returns the Environment Canada humidex.
+func Humidex(t units.Temperature, rh float64) units.Temperature {
+	c := t.Celsius()
+	e := rh / 100 * buckWater.pressure(c)
+	return fromCelsius(c+0.5555*(e-10), t)
+}
+
+// ApparentTemperature returns the Australian Bureau of Meteorology
+// apparent temperature, without the effect of the sun.
+func ApparentTemperature(t units.Temperature, rh float64, wind units.Speed) units.Temperature {
+	c := t.Celsius()
+	e := rh / 100 * 6.105 * math.Exp(17.27*c/(237.7+c))
+	ws := wind.In(units.MetersPerSecond).Value
+	return fromCelsius(c+0.33*e-0.70*ws-4.00, t)
+}
+
+// WetBulb returns the wet-bulb temperature at sea level using Stull's
+// empirical formula. It's accurate to within 1°C for relative humidity
+// between 5% and 99% and temperatures between -20°C and 50°C.
+func WetBulb(t units.Temperature, rh float64) units.Temperature {
+	c := t.Celsius()
+	tw := c*math.Atan(0.151977*math.Sqrt(rh+8.313659)) +
+		math.Atan(c+rh) - math.Atan(rh-1.676331) +
+		0.00391838*math.Pow(rh, 1.5)*math.Atan(0.023101*rh) - 4.686035
+	return fromCelsius(tw, t)
+}
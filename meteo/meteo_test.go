@@ -0,0 +1,181 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package meteo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/briandowns/openweathermap/units"
+)
+
+func celsius(v float64) units.Temperature {
+	return units.Temperature{Value: v, Unit: units.Celsius}
+}
+
+func fahrenheit(v float64) units.Temperature {
+	return units.Temperature{Value: v, Unit: units.Fahrenheit}
+}
+
+func mph(v float64) units.Speed {
+	return units.Speed{Value: v, Unit: units.MilesPerHour}
+}
+
+func ms(v float64) units.Speed {
+	return units.Speed{Value: v, Unit: units.MetersPerSecond}
+}
+
+// TestHeatIndex will verify the heat index against the NWS heat index
+// chart, which is rounded to whole degrees
+func TestHeatIndex(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		temp, rh, want float64
+	}{
+		{80, 40, 80},
+		{86, 90, 105},
+		{90, 50, 95},
+		{96, 65, 121},
+		{100, 40, 109},
+		{104, 55, 137},
+		{110, 40, 136},
+	}
+	for _, tt := range tests {
+		got := HeatIndex(fahrenheit(tt.temp), tt.rh)
+		if math.Round(got.Value) != tt.want || got.Unit != units.Fahrenheit {
+			t.Errorf("%v°F at %v%%: expected %v, got %v", tt.temp, tt.rh, tt.want, got)
+		}
+	}
+
+	// the low humidity adjustment lowers the index
+	if dry, raw := HeatIndex(fahrenheit(110), 10), 104.389; math.Abs(dry.Value-raw) > 0.01 {
+		t.Errorf("expected the low humidity adjustment to give %v, got %v", raw, dry)
+	}
+
+	// the result is in the unit of the given temperature
+	if c := HeatIndex(celsius(32.2222), 50); c.Unit != units.Celsius || math.Abs(c.Fahrenheit()-94.6) > 0.1 {
+		t.Errorf("unexpected heat index %v", c)
+	}
+}
+
+// TestWindChill will verify the wind chill against the NWS wind chill
+// chart
+func TestWindChill(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		temp, wind, want float64
+	}{
+		{40, 5, 36},
+		{30, 10, 21},
+		{25, 30, 8},
+		{0, 15, -19},
+		{-10, 20, -35},
+		{-40, 60, -91},
+	}
+	for _, tt := range tests {
+		got := WindChill(fahrenheit(tt.temp), mph(tt.wind))
+		if math.Round(got.Value) != tt.want {
+			t.Errorf("%v°F with %v mph: expected %v, got %v", tt.temp, tt.wind, tt.want, got)
+		}
+	}
+
+	// Environment Canada's table is in Celsius and km/h
+	got := WindChill(celsius(-20), units.Speed{Value: 30, Unit: units.KilometersPerHour})
+	if math.Round(got.Value) != -33 {
+		t.Errorf("expected -33°C, got %v", got)
+	}
+
+	// outside of the defined range the air temperature is kept
+	for _, c := range []struct {
+		temp units.Temperature
+		wind units.Speed
+	}{
+		{fahrenheit(60), mph(20)},
+		{fahrenheit(20), mph(2)},
+	} {
+		if got := WindChill(c.temp, c.wind); got != c.temp {
+			t.Errorf("expected %v to be kept, got %v", c.temp, got)
+		}
+	}
+}
+
+// TestHumidex will verify the humidex against the Environment Canada
+// humidex table
+func TestHumidex(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		temp, rh, want float64
+	}{
+		{25, 50, 28},
+		{30, 40, 34},
+		{30, 70, 41},
+		{35, 60, 48},
+		{40, 30, 47},
+	}
+	for _, tt := range tests {
+		got := Humidex(celsius(tt.temp), tt.rh)
+		if math.Abs(got.Value-tt.want) > 0.6 {
+			t.Errorf("%v°C at %v%%: expected %v, got %v", tt.temp, tt.rh, tt.want, got)
+		}
+	}
+}
+
+// TestApparentTemperature will verify the Bureau of Meteorology apparent
+// temperature
+func TestApparentTemperature(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		temp, rh, wind, want float64
+	}{
+		{30, 50, 0, 33.0},
+		{10, 80, 5, 5.7},
+		{20, 60, 2, 19.2},
+	}
+	for _, tt := range tests {
+		got := ApparentTemperature(celsius(tt.temp), tt.rh, ms(tt.wind))
+		if math.Abs(got.Value-tt.want) > 0.05 {
+			t.Errorf("%v°C at %v%% with %v m/s: expected %v, got %v", tt.temp, tt.rh, tt.wind, tt.want, got)
+		}
+	}
+
+	// wind is converted before use
+	a := ApparentTemperature(fahrenheit(50), 80, mph(11.18468))
+	if math.Abs(a.Celsius()-5.7) > 0.05 {
+		t.Errorf("expected 5.7°C, got %v", a.In(units.Celsius))
+	}
+}
+
+// TestWetBulb will verify the wet-bulb temperature against the values
+// published by Stull
+func TestWetBulb(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		temp, rh, want float64
+	}{
+		{20, 50, 13.7},
+		{30, 80, 27.1},
+	}
+	for _, tt := range tests {
+		got := WetBulb(celsius(tt.temp), tt.rh)
+		if math.Abs(got.Value-tt.want) > 0.05 {
+			t.Errorf("%v°C at %v%%: expected %v, got %v", tt.temp, tt.rh, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,428 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mock provides in-memory implementations of the interfaces of the
+// APIs for unit tests. Every call of the API records itself and calls the
+// function set for it, e.g. CurrentByNameFunc. Without one it returns Err.
+// Result returns the canned result set in Data, so code taking the
+// interfaces reads it as if it had been fetched.
+//
+//	m := &mock.CurrentWeather{
+//		Data: &owm.CurrentWeatherData{Name: "Berlin", Main: owm.Main{Temp: 21.5}},
+//	}
+//	temp, err := temperature(m, "Berlin") // takes an owm.CurrentWeather
+//	if temp != 21.5 || m.Called("CurrentByName") != 1 {
+//		t.Error("expected the temperature of Berlin")
+//	}
+package mock
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	owm "github.com/briandowns/openweathermap"
+)
+
+// Call is a recorded call of a method.
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// Recorder records the calls of a mock. It's safe for concurrent use.
+type Recorder struct {
+	mu    sync.Mutex
+	calls []Call
+}
+
+// record adds a call.
+func (r *Recorder) record(method string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns the recorded calls in order.
+func (r *Recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Call(nil), r.calls...)
+}
+
+// Called returns how many times the method was called.
+func (r *Recorder) Called(method string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, c := range r.calls {
+		if c.Method == method {
+			n++
+		}
+	}
+	return n
+}
+
+// Reset forgets the recorded calls.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = nil
+}
+
+// CurrentWeather is a mock of owm.CurrentWeather. Result returns Data.
+// Without WatchFunc, Watch sends Updates and closes the channel once the
+// context is done.
+type CurrentWeather struct {
+	Recorder
+	Err     error
+	Data    *owm.CurrentWeatherData
+	Updates []owm.WeatherUpdate
+
+	CurrentByNameFunc        func(location string) error
+	CurrentByCoordinatesFunc func(location *owm.Coordinates) error
+	CurrentByIDFunc          func(id int) error
+	CurrentByZipFunc         func(zip int, countryCode string) error
+	CurrentByZipcodeFunc     func(zip string, countryCode string) error
+	WatchFunc                func(ctx context.Context, location owm.Location, interval time.Duration) (<-chan owm.WeatherUpdate, error)
+}
+
+// Result implements owm.CurrentWeather.
+func (m *CurrentWeather) Result() *owm.CurrentWeatherData { return m.Data }
+
+// CurrentByName implements owm.CurrentWeather.
+func (m *CurrentWeather) CurrentByName(location string) error {
+	m.record("CurrentByName", location)
+	if m.CurrentByNameFunc != nil {
+		return m.CurrentByNameFunc(location)
+	}
+	return m.Err
+}
+
+// CurrentByCoordinates implements owm.CurrentWeather.
+func (m *CurrentWeather) CurrentByCoordinates(location *owm.Coordinates) error {
+	m.record("CurrentByCoordinates", location)
+	if m.CurrentByCoordinatesFunc != nil {
+		return m.CurrentByCoordinatesFunc(location)
+	}
+	return m.Err
+}
+
+// CurrentByID implements owm.CurrentWeather.
+func (m *CurrentWeather) CurrentByID(id int) error {
+	m.record("CurrentByID", id)
+	if m.CurrentByIDFunc != nil {
+		return m.CurrentByIDFunc(id)
+	}
+	return m.Err
+}
+
+// CurrentByZip implements owm.CurrentWeather.
+func (m *CurrentWeather) CurrentByZip(zip int, countryCode string) error {
+	m.record("CurrentByZip", zip, countryCode)
+	if m.CurrentByZipFunc != nil {
+		return m.CurrentByZipFunc(zip, countryCode)
+	}
+	return m.Err
+}
+
+// CurrentByZipcode implements owm.CurrentWeather.
+func (m *CurrentWeather) CurrentByZipcode(zip string, countryCode string) error {
+	m.record("CurrentByZipcode", zip, countryCode)
+	if m.CurrentByZipcodeFunc != nil {
+		return m.CurrentByZipcodeFunc(zip, countryCode)
+	}
+	return m.Err
+}
+
+// Watch implements owm.CurrentWeather.
+func (m *CurrentWeather) Watch(ctx context.Context, location owm.Location, interval time.Duration) (<-chan owm.WeatherUpdate, error) {
+	m.record("Watch", location, interval)
+	if m.WatchFunc != nil {
+		return m.WatchFunc(ctx, location, interval)
+	}
+	if m.Err != nil {
+		return nil, m.Err
+	}
+
+	ch := make(chan owm.WeatherUpdate)
+	go func(updates []owm.WeatherUpdate) {
+		defer close(ch)
+		for _, u := range updates {
+			select {
+			case ch <- u:
+			case <-ctx.Done():
+				return
+			}
+		}
+		<-ctx.Done()
+	}(m.Updates)
+	return ch, nil
+}
+
+// Group is a mock of owm.Group. Result returns Data.
+type Group struct {
+	Recorder
+	Err  error
+	Data *owm.CurrentWeatherGroup
+
+	CurrentByIDsFunc func(ids ...int) error
+}
+
+// Result implements owm.Group.
+func (m *Group) Result() *owm.CurrentWeatherGroup { return m.Data }
+
+// CurrentByIDs implements owm.Group.
+func (m *Group) CurrentByIDs(ids ...int) error {
+	m.record("CurrentByIDs", ids)
+	if m.CurrentByIDsFunc != nil {
+		return m.CurrentByIDsFunc(ids...)
+	}
+	return m.Err
+}
+
+// OneCaller is a mock of owm.OneCaller. Result returns Data, and
+// TimeMachineRange, DaySummary and Overview return TimeMachine, Summary
+// and Overviews without their functions.
+type OneCaller struct {
+	Recorder
+	Err         error
+	Data        *owm.OneCallData
+	TimeMachine []owm.OneCallTimeMachineData
+	Summary     *owm.OneCallDaySummary
+	Overviews   *owm.OneCallOverview
+
+	OneCallByCoordinatesFunc func(location *owm.Coordinates) error
+	OneCallTimeMachineFunc   func(location *owm.Coordinates, datetime time.Time) error
+	TimeMachineRangeFunc     func(ctx context.Context, location *owm.Coordinates, start, end time.Time, step time.Duration) ([]owm.OneCallTimeMachineData, error)
+	DaySummaryFunc           func(location *owm.Coordinates, date time.Time, tz string) (*owm.OneCallDaySummary, error)
+	OverviewFunc             func(location *owm.Coordinates, date time.Time, tz string) (*owm.OneCallOverview, error)
+}
+
+// Result implements owm.OneCaller.
+func (m *OneCaller) Result() *owm.OneCallData { return m.Data }
+
+// OneCallByCoordinates implements owm.OneCaller.
+func (m *OneCaller) OneCallByCoordinates(location *owm.Coordinates) error {
+	m.record("OneCallByCoordinates", location)
+	if m.OneCallByCoordinatesFunc != nil {
+		return m.OneCallByCoordinatesFunc(location)
+	}
+	return m.Err
+}
+
+// OneCallTimeMachine implements owm.OneCaller.
+func (m *OneCaller) OneCallTimeMachine(location *owm.Coordinates, datetime time.Time) error {
+	m.record("OneCallTimeMachine", location, datetime)
+	if m.OneCallTimeMachineFunc != nil {
+		return m.OneCallTimeMachineFunc(location, datetime)
+	}
+	return m.Err
+}
+
+// TimeMachineRange implements owm.OneCaller.
+func (m *OneCaller) TimeMachineRange(ctx context.Context, location *owm.Coordinates, start, end time.Time, step time.Duration) ([]owm.OneCallTimeMachineData, error) {
+	m.record("TimeMachineRange", location, start, end, step)
+	if m.TimeMachineRangeFunc != nil {
+		return m.TimeMachineRangeFunc(ctx, location, start, end, step)
+	}
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	return m.TimeMachine, nil
+}
+
+// DaySummary implements owm.OneCaller.
+func (m *OneCaller) DaySummary(location *owm.Coordinates, date time.Time, tz string) (*owm.OneCallDaySummary, error) {
+	m.record("DaySummary", location, date, tz)
+	if m.DaySummaryFunc != nil {
+		return m.DaySummaryFunc(location, date, tz)
+	}
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	return m.Summary, nil
+}
+
+// Overview implements owm.OneCaller.
+func (m *OneCaller) Overview(location *owm.Coordinates, date time.Time, tz string) (*owm.OneCallOverview, error) {
+	m.record("Overview", location, date, tz)
+	if m.OverviewFunc != nil {
+		return m.OverviewFunc(location, date, tz)
+	}
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	return m.Overviews, nil
+}
+
+// AirPollution is a mock of owm.AirPollution. Result returns Data.
+type AirPollution struct {
+	Recorder
+	Err  error
+	Data *owm.Pollution
+
+	PollutionByParamsFunc func(params *owm.PollutionParameters) error
+}
+
+// Result implements owm.AirPollution.
+func (m *AirPollution) Result() *owm.Pollution { return m.Data }
+
+// PollutionByParams implements owm.AirPollution.
+func (m *AirPollution) PollutionByParams(params *owm.PollutionParameters) error {
+	m.record("PollutionByParams", params)
+	if m.PollutionByParamsFunc != nil {
+		return m.PollutionByParamsFunc(params)
+	}
+	return m.Err
+}
+
+// UVIndex is a mock of owm.UVIndex. Result returns Data, and
+// UVInformation returns Info without its function.
+type UVIndex struct {
+	Recorder
+	Err  error
+	Data *owm.UV
+	Info []owm.UVIndexInfo
+
+	CurrentFunc       func(coord *owm.Coordinates) error
+	HistoricalFunc    func(coord *owm.Coordinates, start, end time.Time) error
+	UVInformationFunc func() ([]owm.UVIndexInfo, error)
+}
+
+// Result implements owm.UVIndex.
+func (m *UVIndex) Result() *owm.UV { return m.Data }
+
+// Current implements owm.UVIndex.
+func (m *UVIndex) Current(coord *owm.Coordinates) error {
+	m.record("Current", coord)
+	if m.CurrentFunc != nil {
+		return m.CurrentFunc(coord)
+	}
+	return m.Err
+}
+
+// Historical implements owm.UVIndex.
+func (m *UVIndex) Historical(coord *owm.Coordinates, start, end time.Time) error {
+	m.record("Historical", coord, start, end)
+	if m.HistoricalFunc != nil {
+		return m.HistoricalFunc(coord, start, end)
+	}
+	return m.Err
+}
+
+// UVInformation implements owm.UVIndex.
+func (m *UVIndex) UVInformation() ([]owm.UVIndexInfo, error) {
+	m.record("UVInformation")
+	if m.UVInformationFunc != nil {
+		return m.UVInformationFunc()
+	}
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	return m.Info, nil
+}
+
+// History is a mock of owm.History. Result returns Data.
+type History struct {
+	Recorder
+	Err  error
+	Data *owm.HistoricalWeatherData
+
+	HistoryByNameFunc  func(location string) error
+	HistoryByIDFunc    func(id int, hp ...*owm.HistoricalParameters) error
+	HistoryByCoordFunc func(location *owm.Coordinates, hp *owm.HistoricalParameters) error
+}
+
+// Result implements owm.History.
+func (m *History) Result() *owm.HistoricalWeatherData { return m.Data }
+
+// HistoryByName implements owm.History.
+func (m *History) HistoryByName(location string) error {
+	m.record("HistoryByName", location)
+	if m.HistoryByNameFunc != nil {
+		return m.HistoryByNameFunc(location)
+	}
+	return m.Err
+}
+
+// HistoryByID implements owm.History.
+func (m *History) HistoryByID(id int, hp ...*owm.HistoricalParameters) error {
+	m.record("HistoryByID", id, hp)
+	if m.HistoryByIDFunc != nil {
+		return m.HistoryByIDFunc(id, hp...)
+	}
+	return m.Err
+}
+
+// HistoryByCoord implements owm.History.
+func (m *History) HistoryByCoord(location *owm.Coordinates, hp *owm.HistoricalParameters) error {
+	m.record("HistoryByCoord", location, hp)
+	if m.HistoryByCoordFunc != nil {
+		return m.HistoryByCoordFunc(location, hp)
+	}
+	return m.Err
+}
+
+// ForecastWeather is a mock of owm.Forecaster. Result returns Data, e.g.
+// a *owm.Forecast5WeatherData.
+type ForecastWeather struct {
+	Recorder
+	Err  error
+	Data owm.ForecastWeatherJson
+
+	DailyByNameFunc        func(location string, days int) error
+	DailyByCoordinatesFunc func(location *owm.Coordinates, days int) error
+	DailyByIDFunc          func(id, days int) error
+}
+
+// Result implements owm.Forecaster.
+func (m *ForecastWeather) Result() owm.ForecastWeatherJson { return m.Data }
+
+// DailyByName implements owm.ForecastWeather.
+func (m *ForecastWeather) DailyByName(location string, days int) error {
+	m.record("DailyByName", location, days)
+	if m.DailyByNameFunc != nil {
+		return m.DailyByNameFunc(location, days)
+	}
+	return m.Err
+}
+
+// DailyByCoordinates implements owm.ForecastWeather.
+func (m *ForecastWeather) DailyByCoordinates(location *owm.Coordinates, days int) error {
+	m.record("DailyByCoordinates", location, days)
+	if m.DailyByCoordinatesFunc != nil {
+		return m.DailyByCoordinatesFunc(location, days)
+	}
+	return m.Err
+}
+
+// DailyByID implements owm.ForecastWeather.
+func (m *ForecastWeather) DailyByID(id, days int) error {
+	m.record("DailyByID", id, days)
+	if m.DailyByIDFunc != nil {
+		return m.DailyByIDFunc(id, days)
+	}
+	return m.Err
+}
+
+var (
+	_ owm.CurrentWeather = (*CurrentWeather)(nil)
+	_ owm.Group          = (*Group)(nil)
+	_ owm.OneCaller      = (*OneCaller)(nil)
+	_ owm.AirPollution   = (*AirPollution)(nil)
+	_ owm.UVIndex        = (*UVIndex)(nil)
+	_ owm.History        = (*History)(nil)
+	_ owm.Forecaster     = (*ForecastWeather)(nil)
+)
@@ -0,0 +1,227 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mock
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	owm "github.com/briandowns/openweathermap"
+)
+
+var errTest = errors.New("test")
+
+// refresh stands in for consumer code taking an interface.
+func refresh(w owm.CurrentWeather, names ...string) error {
+	for _, n := range names {
+		if err := w.CurrentByName(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// temperature stands in for consumer code reading the results through an
+// interface.
+func temperature(w owm.CurrentWeather, name string) (float64, error) {
+	if err := w.CurrentByName(name); err != nil {
+		return 0, err
+	}
+	return w.Result().Main.Temp, nil
+}
+
+// TestCurrentWeather will verify that calls are recorded and answered by
+// the functions or Err
+func TestCurrentWeather(t *testing.T) {
+	t.Parallel()
+
+	m := &CurrentWeather{}
+	if err := refresh(m, "Berlin", "London"); err != nil {
+		t.Fatal(err)
+	}
+	want := []Call{
+		{Method: "CurrentByName", Args: []interface{}{"Berlin"}},
+		{Method: "CurrentByName", Args: []interface{}{"London"}},
+	}
+	if got := m.Calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	m.Err = errTest
+	if err := m.CurrentByID(2950159); err != errTest {
+		t.Errorf("expected %v, got %v", errTest, err)
+	}
+
+	m.CurrentByIDFunc = func(id int) error {
+		if id != 2950159 {
+			t.Errorf("unexpected id %d", id)
+		}
+		return nil
+	}
+	if err := m.CurrentByID(2950159); err != nil {
+		t.Error(err)
+	}
+	if n := m.Called("CurrentByID"); n != 2 {
+		t.Errorf("expected 2 calls, got %d", n)
+	}
+
+	m.Reset()
+	if n := len(m.Calls()); n != 0 {
+		t.Errorf("expected the calls to be reset, got %d", n)
+	}
+}
+
+// TestWatch will verify that the canned updates are sent and the channel
+// closed with the context
+func TestWatch(t *testing.T) {
+	t.Parallel()
+
+	m := &CurrentWeather{Updates: []owm.WeatherUpdate{{Err: errTest}, {}}}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	updates, err := m.Watch(ctx, owm.LocationID(2950159), time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u := <-updates; u.Err != errTest {
+		t.Errorf("expected the first update, got %+v", u)
+	}
+	<-updates
+	cancel()
+	if _, ok := <-updates; ok {
+		t.Error("expected the channel to be closed")
+	}
+
+	m.Err = errTest
+	if _, err := m.Watch(context.Background(), owm.LocationID(2950159), time.Minute); err != errTest {
+		t.Errorf("expected %v, got %v", errTest, err)
+	}
+}
+
+// TestCannedResults will verify that the mocks with results return them
+func TestCannedResults(t *testing.T) {
+	t.Parallel()
+
+	o := &OneCaller{
+		TimeMachine: []owm.OneCallTimeMachineData{{Dt: 1715601600}},
+		Summary:     &owm.OneCallDaySummary{Date: "2024-05-13"},
+	}
+	var c owm.OneCaller = o
+	data, err := c.TimeMachineRange(context.Background(), &owm.Coordinates{}, time.Now(), time.Now(), time.Hour)
+	if err != nil || len(data) != 1 {
+		t.Errorf("expected the canned data, got %v, %v", data, err)
+	}
+	if s, err := c.DaySummary(&owm.Coordinates{}, time.Now(), ""); err != nil || s.Date != "2024-05-13" {
+		t.Errorf("expected the canned summary, got %v, %v", s, err)
+	}
+	if v, err := c.Overview(&owm.Coordinates{}, time.Now(), ""); err != nil || v != nil {
+		t.Errorf("expected no overview, got %v, %v", v, err)
+	}
+
+	o.Err = errTest
+	if _, err := c.DaySummary(&owm.Coordinates{}, time.Now(), ""); err != errTest {
+		t.Errorf("expected %v, got %v", errTest, err)
+	}
+
+	u := &UVIndex{Info: []owm.UVIndexInfo{{UVIndex: []float64{0, 2.9}}}}
+	if info, err := u.UVInformation(); err != nil || len(info) != 1 {
+		t.Errorf("expected the canned information, got %v, %v", info, err)
+	}
+}
+
+// TestResults will verify that code taking the interfaces reads the
+// canned data of the mocks
+func TestResults(t *testing.T) {
+	t.Parallel()
+
+	m := &CurrentWeather{Data: &owm.CurrentWeatherData{Name: "Berlin", Main: owm.Main{Temp: 21.5}}}
+	if temp, err := temperature(m, "Berlin"); err != nil || temp != 21.5 {
+		t.Errorf("expected 21.5, got %v, %v", temp, err)
+	}
+	m.Err = errTest
+	if _, err := temperature(m, "Berlin"); err != errTest {
+		t.Errorf("expected %v, got %v", errTest, err)
+	}
+
+	var (
+		g owm.Group        = &Group{Data: &owm.CurrentWeatherGroup{Count: 2}}
+		o owm.OneCaller    = &OneCaller{Data: &owm.OneCallData{Timezone: "Europe/Berlin"}}
+		p owm.AirPollution = &AirPollution{Data: &owm.Pollution{List: []owm.PollutionData{{}}}}
+		u owm.UVIndex      = &UVIndex{Data: &owm.UV{Value: 6.2}}
+		h owm.History      = &History{Data: &owm.HistoricalWeatherData{Cnt: 24}}
+		f owm.Forecaster   = &ForecastWeather{Data: &owm.Forecast5WeatherData{Cnt: 40}}
+	)
+	if g.CurrentByIDs(1, 2) != nil || g.Result().Count != 2 {
+		t.Errorf("unexpected group %+v", g.Result())
+	}
+	if o.OneCallByCoordinates(&owm.Coordinates{}) != nil || o.Result().Timezone != "Europe/Berlin" {
+		t.Errorf("unexpected one call %+v", o.Result())
+	}
+	if p.PollutionByParams(&owm.PollutionParameters{}) != nil || len(p.Result().List) != 1 {
+		t.Errorf("unexpected pollution %+v", p.Result())
+	}
+	if u.Current(&owm.Coordinates{}) != nil || u.Result().Value != 6.2 {
+		t.Errorf("unexpected UV %+v", u.Result())
+	}
+	if h.HistoryByName("Berlin") != nil || h.Result().Cnt != 24 {
+		t.Errorf("unexpected history %+v", h.Result())
+	}
+	if f.DailyByName("Berlin", 40) != nil {
+		t.Fatal("unexpected error")
+	}
+	if d, ok := f.Result().(*owm.Forecast5WeatherData); !ok || d.Cnt != 40 {
+		t.Errorf("unexpected forecast %+v", f.Result())
+	}
+}
+
+// TestConcurrentCalls will verify that the calls of many goroutines are
+// recorded
+func TestConcurrentCalls(t *testing.T) {
+	t.Parallel()
+
+	var (
+		g  = &Group{}
+		h  = &History{}
+		p  = &AirPollution{}
+		f  = &ForecastWeather{}
+		wg sync.WaitGroup
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			g.CurrentByIDs(i, i+1)
+			h.HistoryByID(i)
+			p.PollutionByParams(&owm.PollutionParameters{})
+			f.DailyByID(i, 3)
+		}(i)
+	}
+	wg.Wait()
+
+	for name, r := range map[string]*Recorder{
+		"CurrentByIDs":      &g.Recorder,
+		"HistoryByID":       &h.Recorder,
+		"PollutionByParams": &p.Recorder,
+		"DailyByID":         &f.Recorder,
+	} {
+		if n := r.Called(name); n != 10 {
+			t.Errorf("%s: expected 10 calls, got %d", name, n)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"math"
+)
+
+var (
+	errInvalidBoundingBox = errors.New("invalid bounding box")
+	errTooManyTiles       = errors.New("bounding box needs too many tiles at this zoom level")
+	errNoLayers           = errors.New("at least one layer is needed")
+	errTileNotCached      = errors.New("tile not cached")
+)
+
+// tileSize is the width and height of a map tile in pixels.
+const tileSize = 256
+
+// maxMosaicTiles is the most tiles fetched per layer for a mosaic.
+const maxMosaicTiles = 256
+
+// TileSource provides the PNG of a map tile. It's satisfied by Tiles to
+// fetch from the tile servers and by TileCache to read from disk.
+type TileSource interface {
+	Tile(layer TileLayer, z, x, y int, opts *TileOptions) ([]byte, error)
+}
+
+// Tile returns the cached tile, or an error when it hasn't been cached,
+// so the cache can be used as an offline TileSource.
+func (c *TileCache) Tile(layer TileLayer, z, x, y int, opts *TileOptions) ([]byte, error) {
+	key, err := cacheKey(layer, opts)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := c.Get(key, z, x, y)
+	if !ok {
+		return nil, fmt.Errorf("%s/%d/%d/%d: %w", layer, z, x, y, errTileNotCached)
+	}
+	return b, nil
+}
+
+// BoundingBox holds the area to render in degrees.
+type BoundingBox struct {
+	North float64
+	South float64
+	East  float64
+	West  float64
+}
+
+// valid makes sure the box can be projected. Boxes crossing the
+// antimeridian aren't supported.
+func (b BoundingBox) valid() bool {
+	return b.North > b.South && b.East > b.West &&
+		b.North <= 90 && b.South >= -90 && b.East <= 180 && b.West >= -180
+}
+
+// MosaicOptions holds the optional settings of a mosaic.
+type MosaicOptions struct {
+	// Base is drawn under the layers. It's expected to cover the bounding
+	// box and is scaled to it when the sizes differ.
+	Base image.Image
+
+	// Crop trims the result to the bounding box rather than returning
+	// whole tiles.
+	Crop bool
+
+	// TileOptions is passed along when fetching 2.0 layers.
+	TileOptions *TileOptions
+}
+
+// worldPixel returns the pixel position of the coordinates at zoom
+// level z using the Web Mercator projection.
+func worldPixel(lat, lon float64, z int) (float64, float64) {
+	// the projection is undefined at the poles
+	lat = math.Max(math.Min(lat, 85.05112878), -85.05112878)
+
+	n := math.Exp2(float64(z)) * tileSize
+	latRad := lat * math.Pi / 180
+	x := (lon + 180) / 360 * n
+	y := (1 - math.Log(math.Tan(latRad)+1/math.Cos(latRad))/math.Pi) / 2 * n
+	return x, y
+}
+
+// Mosaic renders the given layers over the bounding box at zoom level z
+// into one image. Tiles are read from the source in the order of the
+// layers and composited on top of each other, over the base map when
+// one is given.
+func Mosaic(src TileSource, box BoundingBox, z int, layers []TileLayer, opts *MosaicOptions) (image.Image, error) {
+	if !box.valid() {
+		return nil, errInvalidBoundingBox
+	}
+	if z < 0 || z > maxZoom {
+		return nil, errInvalidZoom
+	}
+	if len(layers) == 0 {
+		return nil, errNoLayers
+	}
+	if opts == nil {
+		opts = &MosaicOptions{}
+	}
+
+	left, top := worldPixel(box.North, box.West, z)
+	right, bottom := worldPixel(box.South, box.East, z)
+
+	max := 1<<uint(z) - 1
+	x0, y0 := int(left)/tileSize, int(top)/tileSize
+	x1, y1 := minInt(int(right)/tileSize, max), minInt(int(bottom)/tileSize, max)
+
+	if (x1-x0+1)*(y1-y0+1) > maxMosaicTiles {
+		return nil, errTooManyTiles
+	}
+
+	canvas := image.NewRGBA(image.Rect(0, 0, (x1-x0+1)*tileSize, (y1-y0+1)*tileSize))
+	area := image.Rect(
+		int(left)-x0*tileSize,
+		int(top)-y0*tileSize,
+		int(math.Ceil(right))-x0*tileSize,
+		int(math.Ceil(bottom))-y0*tileSize,
+	).Intersect(canvas.Bounds())
+
+	if opts.Base != nil && !opts.Base.Bounds().Empty() {
+		drawScaled(canvas, area, opts.Base)
+	}
+
+	for _, layer := range layers {
+		for y := y0; y <= y1; y++ {
+			for x := x0; x <= x1; x++ {
+				b, err := src.Tile(layer, z, x, y, opts.TileOptions)
+				if err != nil {
+					return nil, err
+				}
+				tile, err := png.Decode(bytes.NewReader(b))
+				if err != nil {
+					return nil, fmt.Errorf("%s/%d/%d/%d: %w", layer, z, x, y, err)
+				}
+				r := image.Rect((x-x0)*tileSize, (y-y0)*tileSize, (x-x0+1)*tileSize, (y-y0+1)*tileSize)
+				draw.Draw(canvas, r, tile, tile.Bounds().Min, draw.Over)
+			}
+		}
+	}
+
+	if !opts.Crop {
+		return canvas, nil
+	}
+
+	cropped := image.NewRGBA(image.Rect(0, 0, area.Dx(), area.Dy()))
+	draw.Draw(cropped, cropped.Bounds(), canvas, area.Min, draw.Src)
+	return cropped, nil
+}
+
+// drawScaled draws src into the rectangle of dst using nearest neighbour
+// scaling.
+func drawScaled(dst draw.Image, r image.Rectangle, src image.Image) {
+	sb := src.Bounds()
+	if sb.Dx() == r.Dx() && sb.Dy() == r.Dy() {
+		draw.Draw(dst, r, src, sb.Min, draw.Src)
+		return
+	}
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		sy := sb.Min.Y + (y-r.Min.Y)*sb.Dy()/r.Dy()
+		for x := r.Min.X; x < r.Max.X; x++ {
+			sx := sb.Min.X + (x-r.Min.X)*sb.Dx()/r.Dx()
+			dst.Set(x, y, src.At(sx, sy))
+		}
+	}
+}
+
+// minInt returns the smaller of the two ints.
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,183 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+var (
+	_ TileSource = (*Tiles)(nil)
+	_ TileSource = (*TileCache)(nil)
+)
+
+// philadelphia covers a few tiles at zoom level 8.
+var philadelphia = BoundingBox{North: 40.5, South: 39.5, East: -74.5, West: -76}
+
+// TestMosaic will verify that tiles of every layer are composited
+func TestMosaic(t *testing.T) {
+	t.Parallel()
+
+	clouds := pngTile(t, color.RGBA{R: 255, A: 255})
+	wind := pngTile(t, color.RGBA{G: 255, A: 255})
+	srv, opt := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/map/clouds_new/"):
+			w.Write(clouds)
+		case strings.HasPrefix(r.URL.Path, "/map/wind_new/"):
+			w.Write(wind)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	tl, err := NewTiles("key", opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := Mosaic(tl, philadelphia, 8, []TileLayer{LayerClouds}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x0, y0 := LatLonToTile(philadelphia.North, philadelphia.West, 8)
+	x1, y1 := LatLonToTile(philadelphia.South, philadelphia.East, 8)
+	if b := img.Bounds(); b.Dx() != (x1-x0+1)*256 || b.Dy() != (y1-y0+1)*256 {
+		t.Errorf("unexpected bounds %v", b)
+	}
+	if r, _, _, _ := img.At(10, 10).RGBA(); r != 0xffff {
+		t.Error("expected the clouds layer to be drawn")
+	}
+
+	img, err = Mosaic(tl, philadelphia, 8, []TileLayer{LayerClouds, LayerWind}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, g, _, _ := img.At(10, 10).RGBA(); r != 0 || g != 0xffff {
+		t.Error("expected the wind layer to be drawn over the clouds layer")
+	}
+
+	if _, err := Mosaic(tl, philadelphia, 8, []TileLayer{LayerTemp}, nil); err == nil {
+		t.Error("expected an error for missing tiles")
+	}
+}
+
+// TestMosaicCropAndBase will verify that the mosaic is cropped to the
+// bounding box and drawn over the base map
+func TestMosaicCropAndBase(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "owm-mosaic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cache, err := NewTileCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// half transparent red tiles on the left column, fully transparent
+	// ones everywhere else
+	x0, y0 := LatLonToTile(philadelphia.North, philadelphia.West, 8)
+	x1, y1 := LatLonToTile(philadelphia.South, philadelphia.East, 8)
+	for x := x0; x <= x1; x++ {
+		for y := y0; y <= y1; y++ {
+			c := color.RGBA{}
+			if x == x0 {
+				c = color.RGBA{R: 128, A: 128}
+			}
+			if err := cache.Put(string(LayerPrecipitation), 8, x, y, pngTile(t, c)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	base := image.NewUniform(color.RGBA{B: 255, A: 255})
+	img, err := Mosaic(cache, philadelphia, 8, []TileLayer{LayerPrecipitation}, &MosaicOptions{
+		Base: &image.RGBA{}, // empty images are ignored
+		Crop: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	left, top := worldPixel(philadelphia.North, philadelphia.West, 8)
+	right, bottom := worldPixel(philadelphia.South, philadelphia.East, 8)
+	if b := img.Bounds(); b.Min != (image.Point{}) || abs(b.Dx()-int(right-left)) > 1 || abs(b.Dy()-int(bottom-top)) > 1 {
+		t.Errorf("unexpected cropped bounds %v", b)
+	}
+
+	baseImg := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			baseImg.Set(x, y, base.C)
+		}
+	}
+	img, err = Mosaic(cache, philadelphia, 8, []TileLayer{LayerPrecipitation}, &MosaicOptions{
+		Base: baseImg,
+		Crop: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, _, b, a := img.At(0, 0).RGBA()
+	if a != 0xffff || r == 0 || b == 0 {
+		t.Errorf("expected precipitation blended over the base map, got %v", img.At(0, 0))
+	}
+	last := img.Bounds().Max
+	r, _, b, _ = img.At(last.X-1, last.Y-1).RGBA()
+	if r != 0 || b != 0xffff {
+		t.Errorf("expected the base map to show through, got %v", img.At(last.X-1, last.Y-1))
+	}
+
+	if _, err := Mosaic(cache, philadelphia, 9, []TileLayer{LayerPrecipitation}, nil); !errors.Is(err, errTileNotCached) {
+		t.Errorf("expected %v, got %v", errTileNotCached, err)
+	}
+}
+
+// TestMosaicInvalid will verify that invalid arguments are rejected
+func TestMosaicInvalid(t *testing.T) {
+	t.Parallel()
+
+	cache := &TileCache{Dir: os.TempDir()}
+	if _, err := Mosaic(cache, BoundingBox{North: 1, South: 2, East: 1, West: 0}, 3, []TileLayer{LayerWind}, nil); err != errInvalidBoundingBox {
+		t.Errorf("expected %v, got %v", errInvalidBoundingBox, err)
+	}
+	if _, err := Mosaic(cache, philadelphia, 3, nil, nil); err != errNoLayers {
+		t.Errorf("expected %v, got %v", errNoLayers, err)
+	}
+	if _, err := Mosaic(cache, BoundingBox{North: 80, South: -80, East: 170, West: -170}, 10, []TileLayer{LayerWind}, nil); err != errTooManyTiles {
+		t.Errorf("expected %v, got %v", errTooManyTiles, err)
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,291 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Intensity is the intensity of precipitation.
+type Intensity int
+
+// Precipitation intensities.
+const (
+	IntensityNone Intensity = iota
+	IntensityLight
+	IntensityModerate
+	IntensityHeavy
+)
+
+// String satisfies the fmt.Stringer interface.
+func (i Intensity) String() string {
+	switch i {
+	case IntensityLight:
+		return "light"
+	case IntensityModerate:
+		return "moderate"
+	case IntensityHeavy:
+		return "heavy"
+	}
+	return "none"
+}
+
+// NowcastThresholds holds the lowest precipitation rate of each
+// intensity, in the units of the minutely data (mm/h unless unit
+// preferences say otherwise).
+type NowcastThresholds struct {
+	Light    float64
+	Moderate float64
+	Heavy    float64
+}
+
+// DefaultNowcastThresholds follows the American Meteorological Society
+// definitions for rain in mm/h.
+var DefaultNowcastThresholds = NowcastThresholds{
+	Light:    0.1,
+	Moderate: 2.5,
+	Heavy:    7.6,
+}
+
+// classify returns the intensity of the rate.
+func (t NowcastThresholds) classify(rate float64) Intensity {
+	switch {
+	case rate >= t.Heavy:
+		return IntensityHeavy
+	case rate >= t.Moderate:
+		return IntensityModerate
+	case rate >= t.Light:
+		return IntensityLight
+	}
+	return IntensityNone
+}
+
+// Nowcast is the analysis of the minutely precipitation forecast.
+type Nowcast struct {
+	// From and To are the times covered by the forecast.
+	From time.Time
+	To   time.Time
+
+	// Start is when precipitation first reaches the light threshold and
+	// End when it next falls below it. Both are zero when no
+	// precipitation is expected and End is zero when it lasts past To.
+	Start time.Time
+	End   time.Time
+
+	Peak      float64   // highest rate
+	PeakAt    time.Time // time of the highest rate
+	Total     float64   // accumulation over the forecast, in mm (or in)
+	Intensity Intensity // intensity of the peak
+	Snow      bool      // whether the current conditions are snow
+}
+
+// Expected reports whether precipitation is expected.
+func (n Nowcast) Expected() bool { return !n.Start.IsZero() }
+
+// StartsIn returns the time from the start of the forecast until
+// precipitation begins.
+func (n Nowcast) StartsIn() time.Duration { return n.Start.Sub(n.From) }
+
+// Duration returns how long precipitation lasts, up to the end of the
+// forecast.
+func (n Nowcast) Duration() time.Duration {
+	if !n.Expected() {
+		return 0
+	}
+	if n.End.IsZero() {
+		return n.To.Sub(n.Start)
+	}
+	return n.End.Sub(n.Start)
+}
+
+// Nowcast analyses the minutely precipitation forecast. The default
+// thresholds are used when nil.
+func (w *OneCallData) Nowcast(thresholds *NowcastThresholds) Nowcast {
+	if thresholds == nil {
+		thresholds = &DefaultNowcastThresholds
+	}
+
+	var n Nowcast
+	if len(w.Current.Weather) > 0 {
+		n.Snow = w.Current.Weather[0].Group() == GroupSnow
+	}
+	if len(w.Minutely) == 0 {
+		return n
+	}
+
+	n.From = time.Unix(int64(w.Minutely[0].Dt), 0).UTC()
+	last := time.Unix(int64(w.Minutely[len(w.Minutely)-1].Dt), 0).UTC()
+	n.To = last.Add(time.Minute)
+
+	for _, m := range w.Minutely {
+		at := time.Unix(int64(m.Dt), 0).UTC()
+		n.Total += m.Precipitation / 60
+
+		wet := thresholds.classify(m.Precipitation) != IntensityNone
+		switch {
+		case wet && n.Start.IsZero():
+			n.Start = at
+		case !wet && !n.Start.IsZero() && n.End.IsZero():
+			n.End = at
+		}
+
+		if m.Precipitation > n.Peak {
+			n.Peak = m.Precipitation
+			n.PeakAt = at
+		}
+	}
+	n.Intensity = thresholds.classify(n.Peak)
+
+	return n
+}
+
+// NowcastSummary returns a short summary of the minutely forecast in
+// the language of the client, e.g. "Rain starting in 12 min, lasting
+// 25 min, heavy". Only the languages of NowcastLanguages are translated:
+// for the others the summary is in English and the bool is false.
+func (w *OneCallData) NowcastSummary(thresholds *NowcastThresholds) (string, bool) {
+	return w.Nowcast(thresholds).Summary(w.Lang)
+}
+
+// nowcastText holds the translations of a nowcast summary.
+type nowcastText struct {
+	none        string
+	starting    string // kind, minutes
+	lasting     string // kind, minutes, minutes
+	now         string // kind
+	stopping    string // kind, minutes
+	rain, snow  string
+	intensities [4]string
+}
+
+// nowcastTexts holds the summaries by language code.
+var nowcastTexts = map[string]nowcastText{
+	"EN": {
+		none:        "No precipitation expected within the hour",
+		starting:    "%s starting in %d min",
+		lasting:     "%s starting in %d min, lasting %d min",
+		now:         "%s for the next hour",
+		stopping:    "%s stopping in %d min",
+		rain:        "Rain",
+		snow:        "Snow",
+		intensities: [4]string{"", "light", "moderate", "heavy"},
+	},
+	"DE": {
+		none:        "Kein Niederschlag in der nächsten Stunde",
+		starting:    "%s in %d Min.",
+		lasting:     "%s in %d Min., Dauer %d Min.",
+		now:         "%s in der nächsten Stunde",
+		stopping:    "%s, endet in %d Min.",
+		rain:        "Regen",
+		snow:        "Schnee",
+		intensities: [4]string{"", "leicht", "mäßig", "stark"},
+	},
+	"FR": {
+		none:        "Pas de précipitations prévues dans l'heure",
+		starting:    "%s dans %d min",
+		lasting:     "%s dans %d min, pendant %d min",
+		now:         "%s pendant l'heure à venir",
+		stopping:    "%s, fin dans %d min",
+		rain:        "Pluie",
+		snow:        "Neige",
+		intensities: [4]string{"", "faible", "modérée", "forte"},
+	},
+	"ES": {
+		none:        "Sin precipitaciones en la próxima hora",
+		starting:    "%s en %d min",
+		lasting:     "%s en %d min, durante %d min",
+		now:         "%s durante la próxima hora",
+		stopping:    "%s, termina en %d min",
+		rain:        "Lluvia",
+		snow:        "Nieve",
+		intensities: [4]string{"", "débil", "moderada", "fuerte"},
+	},
+	"IT": {
+		none:        "Nessuna precipitazione prevista nella prossima ora",
+		starting:    "%s tra %d min",
+		lasting:     "%s tra %d min, per %d min",
+		now:         "%s per la prossima ora",
+		stopping:    "%s, termina tra %d min",
+		rain:        "Pioggia",
+		snow:        "Neve",
+		intensities: [4]string{"", "debole", "moderata", "forte"},
+	},
+}
+
+// nowcastLang returns the code the summaries of the language are kept
+// under. No language is the API's default, English.
+func nowcastLang(lang string) string {
+	switch lang = strings.ToUpper(lang); lang {
+	case "SP":
+		return "ES"
+	case "":
+		return "EN"
+	}
+	return lang
+}
+
+// NowcastLanguages returns the codes of the languages nowcast summaries
+// are translated to: DE, EN, ES (or SP), FR and IT.
+func NowcastLanguages() []string {
+	langs := make([]string, 0, len(nowcastTexts))
+	for lang := range nowcastTexts {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// ValidNowcastLang reports whether nowcast summaries are translated to
+// the language, rather than falling back to English.
+func ValidNowcastLang(lang string) bool {
+	_, ok := nowcastTexts[nowcastLang(lang)]
+	return ok
+}
+
+// Summary returns a short summary of the nowcast in the given language.
+// The bool is false when the language isn't one of NowcastLanguages and
+// the summary is in English instead.
+func (n Nowcast) Summary(lang string) (string, bool) {
+	text, translated := nowcastTexts[nowcastLang(lang)]
+	if !translated {
+		text = nowcastTexts["EN"]
+	}
+
+	if !n.Expected() {
+		return text.none, translated
+	}
+
+	kind := text.rain
+	if n.Snow {
+		kind = text.snow
+	}
+	minutes := func(d time.Duration) int { return int(d.Round(time.Minute) / time.Minute) }
+
+	var s string
+	switch {
+	case n.StartsIn() <= 0 && n.End.IsZero():
+		s = fmt.Sprintf(text.now, kind)
+	case n.StartsIn() <= 0:
+		s = fmt.Sprintf(text.stopping, kind, minutes(n.End.Sub(n.From)))
+	case n.End.IsZero():
+		s = fmt.Sprintf(text.starting, kind, minutes(n.StartsIn()))
+	default:
+		s = fmt.Sprintf(text.lasting, kind, minutes(n.StartsIn()), minutes(n.Duration()))
+	}
+	return s + ", " + text.intensities[n.Intensity], translated
+}
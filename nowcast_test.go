@@ -0,0 +1,127 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// minutely returns an hour of minutely data starting at from, with the
+// given rate between the start and end minutes.
+func minutely(from int, start, end int, rate func(i int) float64) []OneCallMinutelyData {
+	m := make([]OneCallMinutelyData, 60)
+	for i := range m {
+		m[i].Dt = from + i*60
+		if i >= start && i < end {
+			m[i].Precipitation = rate(i)
+		}
+	}
+	return m
+}
+
+// TestNowcast will verify the start, end, peak and accumulation of the
+// minutely precipitation
+func TestNowcast(t *testing.T) {
+	t.Parallel()
+
+	const from = 1684951200
+	w := &OneCallData{
+		Minutely: minutely(from, 12, 37, func(i int) float64 {
+			if i == 20 {
+				return 9
+			}
+			return 3
+		}),
+	}
+
+	n := w.Nowcast(nil)
+	if !n.Expected() || n.StartsIn() != 12*time.Minute || n.Duration() != 25*time.Minute {
+		t.Errorf("unexpected timing %+v", n)
+	}
+	if n.Peak != 9 || !n.PeakAt.Equal(time.Unix(from+20*60, 0)) || n.Intensity != IntensityHeavy {
+		t.Errorf("unexpected peak %+v", n)
+	}
+	if want := (24*3.0 + 9) / 60; math.Abs(n.Total-want) > 1e-9 {
+		t.Errorf("expected %v mm, got %v", want, n.Total)
+	}
+	if s, ok := w.NowcastSummary(nil); s != "Rain starting in 12 min, lasting 25 min, heavy" || !ok {
+		t.Errorf("unexpected summary %q, %v", s, ok)
+	}
+
+	// custom thresholds
+	n = w.Nowcast(&NowcastThresholds{Light: 1, Moderate: 5, Heavy: 20})
+	if n.Intensity != IntensityModerate {
+		t.Errorf("expected moderate, got %s", n.Intensity)
+	}
+	n = w.Nowcast(&NowcastThresholds{Light: 4, Moderate: 8, Heavy: 20})
+	if n.StartsIn() != 20*time.Minute || n.Duration() != time.Minute {
+		t.Errorf("expected only the peak to count, got %+v", n)
+	}
+}
+
+// TestNowcastSummary will verify the summaries of ongoing, stopping and
+// absent precipitation in each language
+func TestNowcastSummary(t *testing.T) {
+	t.Parallel()
+
+	const from = 1684951200
+	light := func(int) float64 { return 0.5 }
+	snow := []Weather{{ID: 601}}
+
+	tests := []struct {
+		name string
+		w    *OneCallData
+		want string
+	}{
+		{"dry", &OneCallData{Lang: "EN", Minutely: minutely(from, 0, 0, light)}, "No precipitation expected within the hour"},
+		{"ongoing", &OneCallData{Lang: "EN", Minutely: minutely(from, 0, 60, light)}, "Rain for the next hour, light"},
+		{"stopping", &OneCallData{Lang: "EN", Minutely: minutely(from, 0, 10, light)}, "Rain stopping in 10 min, light"},
+		{"starting", &OneCallData{Lang: "EN", Minutely: minutely(from, 45, 60, light)}, "Rain starting in 45 min, light"},
+		{"snow", &OneCallData{Lang: "EN", Current: OneCallCurrentData{Weather: snow}, Minutely: minutely(from, 5, 60, light)}, "Snow starting in 5 min, light"},
+		{"german", &OneCallData{Lang: "DE", Minutely: minutely(from, 12, 37, light)}, "Regen in 12 Min., Dauer 25 Min., leicht"},
+		{"french", &OneCallData{Lang: "FR", Minutely: minutely(from, 0, 60, func(int) float64 { return 3 })}, "Pluie pendant l'heure à venir, modérée"},
+		{"spanish", &OneCallData{Lang: "SP", Minutely: minutely(from, 0, 0, light)}, "Sin precipitaciones en la próxima hora"},
+		{"italian", &OneCallData{Lang: "IT", Minutely: minutely(from, 0, 10, light)}, "Pioggia, termina tra 10 min, debole"},
+		{"fallback", &OneCallData{Lang: "JA", Minutely: minutely(from, 45, 60, light)}, "Rain starting in 45 min, light"},
+		{"no data", &OneCallData{Lang: "EN"}, "No precipitation expected within the hour"},
+	}
+	for _, tt := range tests {
+		got, translated := tt.w.NowcastSummary(nil)
+		if got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+		if want := tt.name != "fallback"; translated != want {
+			t.Errorf("%s: expected translated to be %v", tt.name, want)
+		}
+	}
+}
+
+// TestNowcastLanguages will verify the languages summaries are
+// translated to
+func TestNowcastLanguages(t *testing.T) {
+	t.Parallel()
+
+	if got := strings.Join(NowcastLanguages(), ","); got != "DE,EN,ES,FR,IT" {
+		t.Errorf("unexpected languages %s", got)
+	}
+	for lang, want := range map[string]bool{"de": true, "SP": true, "EN": true, "JA": false, "": true} {
+		if got := ValidNowcastLang(lang); got != want {
+			t.Errorf("%q: expected %v, got %v", lang, want, got)
+		}
+	}
+}
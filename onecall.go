@@ -16,6 +16,7 @@ package openweathermap
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 )
@@ -34,10 +35,11 @@ type OneCallData struct {
 	Alerts         []OneCallAlertData       `json:"alerts,omitempty"`
 	Data           []OneCallTimeMachineData `json:"data,omitempty"`
 
-	Unit     string
-	Lang     string
-	Key      string
-	Excludes string
+	Unit         string
+	AppliedUnits *UnitPreferences `json:"-"` // units the values were converted to, if any
+	Lang         string
+	Key          string `json:"-"`
+	Excludes     string
 	*Settings
 }
 
@@ -47,15 +49,17 @@ type OneCallTimeMachineData struct {
 	Sunset     int       `json:"sunset"`
 	Temp       float64   `json:"temp"`
 	FeelsLike  float64   `json:"feels_like"`
-	Pressure   int       `json:"pressure"`
+	Pressure   float64   `json:"pressure"`
 	Humidity   int       `json:"humidity"`
 	DewPoint   float64   `json:"dew_point"`
 	Clouds     int       `json:"clouds"`
 	UVI        float64   `json:"uvi"`
-	Visibility int       `json:"visibility"`
+	Visibility float64   `json:"visibility"`
 	WindSpeed  float64   `json:"wind_speed"`
 	WindGust   float64   `json:"wind_gust,omitempty"`
 	WindDeg    float64   `json:"wind_deg"`
+	Rain       Rain      `json:"rain,omitempty"`
+	Snow       Snow      `json:"snow,omitempty"`
 	Weather    []Weather `json:"weather"`
 }
 
@@ -65,12 +69,12 @@ type OneCallCurrentData struct {
 	Sunset     int       `json:"sunset"`
 	Temp       float64   `json:"temp"`
 	FeelsLike  float64   `json:"feels_like"`
-	Pressure   int       `json:"pressure"`
+	Pressure   float64   `json:"pressure"`
 	Humidity   int       `json:"humidity"`
 	DewPoint   float64   `json:"dew_point"`
 	Clouds     int       `json:"clouds"`
 	UVI        float64   `json:"uvi"`
-	Visibility int       `json:"visibility"`
+	Visibility float64   `json:"visibility"`
 	WindSpeed  float64   `json:"wind_speed"`
 	WindGust   float64   `json:"wind_gust,omitempty"`
 	WindDeg    float64   `json:"wind_deg"`
@@ -88,12 +92,12 @@ type OneCallHourlyData struct {
 	Dt         int       `json:"dt"`
 	Temp       float64   `json:"temp"`
 	FeelsLike  float64   `json:"feels_like"`
-	Pressure   int       `json:"pressure"`
+	Pressure   float64   `json:"pressure"`
 	Humidity   int       `json:"humidity"`
 	DewPoint   float64   `json:"dew_point"`
 	UVI        float64   `json:"uvi"`
 	Clouds     int       `json:"clouds"`
-	Visibility int       `json:"visibility"`
+	Visibility float64   `json:"visibility"`
 	WindSpeed  float64   `json:"wind_speed"`
 	WindGust   float64   `json:"wind_gust,omitempty"`
 	WindDeg    float64   `json:"wind_deg"`
@@ -117,7 +121,7 @@ type OneCallDailyData struct {
 		Eve   float64 `json:"eve"`
 		Morn  float64 `json:"morn"`
 	} `json:"feels_like"`
-	Pressure  int       `json:"pressure"`
+	Pressure  float64   `json:"pressure"`
 	Humidity  int       `json:"humidity"`
 	DewPoint  float64   `json:"dew_point"`
 	WindSpeed float64   `json:"wind_speed"`
@@ -178,26 +182,47 @@ func NewOneCall(unit, lang, key string, excludes []string, options ...Option) (*
 	return c, nil
 }
 
+// decode replaces the result with the response, converted into the
+// preferred units, so nothing of a previous result is kept.
+func (w *OneCallData) decode(r io.Reader) error {
+	var data OneCallData
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return err
+	}
+	w.applyUnitPreferences(&data)
+	data.Unit, data.Lang, data.Key, data.Excludes, data.Settings = w.Unit, w.Lang, w.Key, w.Excludes, w.Settings
+	*w = data
+	return nil
+}
+
 // OneCallByCoordinates will provide the onecall weather with the
 // provided location coordinates.
 func (w *OneCallData) OneCallByCoordinates(location *Coordinates) error {
-	response, err := w.client.Get(fmt.Sprintf(fmt.Sprintf(onecallURL, "?appid=%s&lat=%f&lon=%f&units=%s&lang=%s&exclude=%s"), w.Key, location.Latitude, location.Longitude, w.Unit, w.Lang, w.Excludes))
+	response, err := w.client.Get(fmt.Sprintf(fmt.Sprintf(onecallURL, "?appid=%s&lat=%f&lon=%f&units=%s&lang=%s&exclude=%s"), w.Key, location.Latitude, location.Longitude, w.requestUnit(w.Unit), w.Lang, w.Excludes))
 	if err != nil {
 		return err
 	}
 	defer response.Body.Close()
 
-	return json.NewDecoder(response.Body).Decode(&w)
+	if err := checkResponse(response); err != nil {
+		return err
+	}
+
+	return w.decode(response.Body)
 }
 
 // OneCallTimeMachine will provide the onecall timemachine weather with the
 // provided location coordinates and unix timestamp
 func (w *OneCallData) OneCallTimeMachine(location *Coordinates, datetime time.Time) error {
-	response, err := w.client.Get(fmt.Sprintf(fmt.Sprintf(onecallURL, "/timemachine?appid=%s&lat=%f&lon=%f&units=%s&lang=%s&dt=%d"), w.Key, location.Latitude, location.Longitude, w.Unit, w.Lang, datetime.Unix()))
+	response, err := w.client.Get(fmt.Sprintf(fmt.Sprintf(onecallURL, "/timemachine?appid=%s&lat=%f&lon=%f&units=%s&lang=%s&dt=%d"), w.Key, location.Latitude, location.Longitude, w.requestUnit(w.Unit), w.Lang, datetime.Unix()))
 	if err != nil {
 		return err
 	}
 	defer response.Body.Close()
 
-	return json.NewDecoder(response.Body).Decode(&w)
+	if err := checkResponse(response); err != nil {
+		return err
+	}
+
+	return w.decode(response.Body)
 }
@@ -0,0 +1,205 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	errInvalidStep          = errors.New("step should be positive")
+	errInvalidRange         = errors.New("end should not be before start")
+	errInvalidMaxRangeCalls = errors.New("max range calls should be at least 1")
+)
+
+// DefaultMaxRangeCalls is the most timemachine calls a range may need,
+// e.g. a week of hourly data.
+const DefaultMaxRangeCalls = 168
+
+// WithMaxRangeCalls sets the most timemachine calls a range may need
+// before it's rejected, see TimeMachineRange.
+func WithMaxRangeCalls(n int) Option {
+	return func(s *Settings) error {
+		if n < 1 {
+			return errInvalidMaxRangeCalls
+		}
+		s.maxRangeCalls = n
+		return nil
+	}
+}
+
+// RangeTooLargeError is returned when a range needs more timemachine
+// calls than allowed. No call is made.
+type RangeTooLargeError struct {
+	Calls int // calls the range needs
+	Max   int // calls allowed
+}
+
+// Error satisfies the error interface.
+func (e *RangeTooLargeError) Error() string {
+	return fmt.Sprintf("range needs %d timemachine calls, more than the %d allowed", e.Calls, e.Max)
+}
+
+// TimeMachineError is the error of a single timemachine call of a range.
+type TimeMachineError struct {
+	Time time.Time // time requested
+	Err  error
+}
+
+// Error satisfies the error interface.
+func (e *TimeMachineError) Error() string {
+	return fmt.Sprintf("timemachine %s: %v", e.Time.UTC().Format(time.RFC3339), e.Err)
+}
+
+// Unwrap returns the error of the call.
+func (e *TimeMachineError) Unwrap() error { return e.Err }
+
+// TimeMachineErrors holds the calls of a range that failed, in time
+// order.
+type TimeMachineErrors []*TimeMachineError
+
+// Error satisfies the error interface.
+func (e TimeMachineErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d timemachine calls failed: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// timeMachine fetches the data of a single time without touching the
+// receiver.
+func (w *OneCallData) timeMachine(ctx context.Context, location *Coordinates, datetime time.Time) ([]OneCallTimeMachineData, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(fmt.Sprintf(onecallURL, "/timemachine?appid=%s&lat=%f&lon=%f&units=%s&lang=%s&dt=%d"), w.Key, location.Latitude, location.Longitude, w.requestUnit(w.Unit), w.Lang, datetime.Unix()), nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := w.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if err := checkResponse(response); err != nil {
+		return nil, err
+	}
+
+	var data OneCallData
+	if err := json.NewDecoder(response.Body).Decode(&data); err != nil {
+		return nil, err
+	}
+	w.applyUnitPreferences(&data)
+
+	return data.Data, nil
+}
+
+// TimeMachineRange makes a timemachine call for every step from start up
+// to and including end, with at most the configured concurrency in flight
+// (see WithConcurrency), and returns the results in time order. Duplicate
+// timestamps are dropped. When calls fail the data of the successful ones
+// is returned along with a TimeMachineErrors. The receiver isn't modified.
+//
+// Ranges needing more calls than allowed (see WithMaxRangeCalls) are
+// rejected with a RangeTooLargeError. No call is started once the context
+// is done, and the data of the calls that succeeded is then returned with
+// the context's error.
+func (w *OneCallData) TimeMachineRange(ctx context.Context, location *Coordinates, start, end time.Time, step time.Duration) ([]OneCallTimeMachineData, error) {
+	if step <= 0 {
+		return nil, errInvalidStep
+	}
+	if end.Before(start) {
+		return nil, errInvalidRange
+	}
+
+	max := w.maxRangeCalls
+	if max < 1 {
+		max = DefaultMaxRangeCalls
+	}
+	if calls := end.Sub(start)/step + 1; calls > time.Duration(max) {
+		return nil, &RangeTooLargeError{Calls: int(calls), Max: max}
+	}
+
+	var times []time.Time
+	for t := start; !t.After(end); t = t.Add(step) {
+		times = append(times, t)
+	}
+
+	concurrency := w.concurrency
+	if concurrency < 1 {
+		concurrency = DefaultConcurrency
+	}
+
+	results := make([][]OneCallTimeMachineData, len(times))
+	errs := make([]error, len(times))
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+schedule:
+	for i, t := range times {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break schedule
+		}
+		if ctx.Err() != nil {
+			<-sem
+			break
+		}
+		wg.Add(1)
+		go func(i int, t time.Time) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i], errs[i] = w.timeMachine(ctx, location, t)
+		}(i, t)
+	}
+	wg.Wait()
+
+	var data []OneCallTimeMachineData
+	var failed TimeMachineErrors
+	for i := range times {
+		if errs[i] != nil {
+			failed = append(failed, &TimeMachineError{Time: times[i], Err: errs[i]})
+			continue
+		}
+		data = append(data, results[i]...)
+	}
+
+	sort.SliceStable(data, func(i, j int) bool { return data[i].Dt < data[j].Dt })
+	unique := data[:0]
+	for _, d := range data {
+		if len(unique) > 0 && unique[len(unique)-1].Dt == d.Dt {
+			continue
+		}
+		unique = append(unique, d)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return unique, err
+	}
+	if len(failed) > 0 {
+		return unique, failed
+	}
+	return unique, nil
+}
@@ -0,0 +1,212 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestTimeMachineRange will verify that a range is fetched with bounded
+// concurrency and returned in time order along with the failed calls
+func TestTimeMachineRange(t *testing.T) {
+	t.Parallel()
+
+	const start = 1600000000
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+
+	srv, opt := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+		time.Sleep(10 * time.Millisecond)
+
+		if r.URL.Path != "/data/3.0/onecall/timemachine" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		dt := r.URL.Query().Get("dt")
+		if dt == fmt.Sprint(start+2*3600) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"cod":400,"message":"wrong dt"}`))
+			return
+		}
+		fmt.Fprintf(w, `{"data": [{"dt": %s, "temp": 10, "rain": {"1h": 0.5}, "snow": {"1h": 0.1}}]}`, dt)
+	}))
+	defer srv.Close()
+
+	w, err := NewOneCall("C", "EN", "key", []string{}, opt, WithConcurrency(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := w.TimeMachineRange(context.Background(), &Coordinates{Latitude: 1, Longitude: 2}, time.Unix(start, 0), time.Unix(start+5*3600, 0), time.Hour)
+
+	var failed TimeMachineErrors
+	if !errors.As(err, &failed) || len(failed) != 1 || failed[0].Time.Unix() != start+2*3600 {
+		t.Fatalf("expected the third call to fail, got %v", err)
+	}
+	var apiErr *APIError
+	if !errors.As(failed[0], &apiErr) || apiErr.Message != "wrong dt" {
+		t.Errorf("expected an API error, got %v", failed[0].Err)
+	}
+
+	if len(data) != 5 {
+		t.Fatalf("expected 5 results, got %d", len(data))
+	}
+	for i := 1; i < len(data); i++ {
+		if data[i].Dt <= data[i-1].Dt {
+			t.Errorf("expected results in time order, got %d after %d", data[i].Dt, data[i-1].Dt)
+		}
+	}
+	if data[0].Rain.OneH != 0.5 || data[0].Snow.OneH != 0.1 {
+		t.Errorf("expected rain and snow, got %+v", data[0])
+	}
+	if maxInFlight > 2 {
+		t.Errorf("expected at most 2 calls in flight, got %d", maxInFlight)
+	}
+	if len(w.Data) != 0 {
+		t.Errorf("expected the receiver untouched, got %+v", w.Data)
+	}
+}
+
+// TestTimeMachineRangeUnitPreferences will verify that the results of a
+// range follow the unit preferences
+func TestTimeMachineRangeUnitPreferences(t *testing.T) {
+	t.Parallel()
+
+	srv, opt := newTestServer(t, unitsHandler(t, `{"data": [{"dt": 1600000000, "temp": 3, "rain": {"1h": 25.4}}]}`))
+	defer srv.Close()
+
+	w, err := NewOneCall("C", "EN", "key", nil, opt, WithUnitPreferences(aviation))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := w.TimeMachineRange(context.Background(), &Coordinates{}, time.Unix(1600000000, 0), time.Unix(1600000000, 0), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 1 || !approx(data[0].Rain.OneH, 1) {
+		t.Errorf("unexpected data %+v", data)
+	}
+}
+
+// TestTimeMachineRangeInvalid will verify that invalid ranges and
+// options are rejected
+func TestTimeMachineRangeInvalid(t *testing.T) {
+	t.Parallel()
+
+	w, err := NewOneCall("C", "EN", "key", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	if _, err := w.TimeMachineRange(context.Background(), &Coordinates{}, now, now.Add(time.Hour), 0); err != errInvalidStep {
+		t.Errorf("expected %v, got %v", errInvalidStep, err)
+	}
+	if _, err := w.TimeMachineRange(context.Background(), &Coordinates{}, now, now.Add(-time.Hour), time.Hour); err != errInvalidRange {
+		t.Errorf("expected %v, got %v", errInvalidRange, err)
+	}
+	if _, err := NewOneCall("C", "EN", "key", nil, WithConcurrency(0)); err != errInvalidConcurrency {
+		t.Errorf("expected %v, got %v", errInvalidConcurrency, err)
+	}
+	if _, err := NewOneCall("C", "EN", "key", nil, WithMaxRangeCalls(0)); err != errInvalidMaxRangeCalls {
+		t.Errorf("expected %v, got %v", errInvalidMaxRangeCalls, err)
+	}
+}
+
+// TestTimeMachineRangeTooLarge will verify that ranges needing more calls
+// than allowed are rejected without a call
+func TestTimeMachineRangeTooLarge(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	srv, opt := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte(`{"data": []}`))
+	}))
+	defer srv.Close()
+
+	w, err := NewOneCall("C", "EN", "key", nil, opt, WithMaxRangeCalls(24))
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Unix(1600000000, 0)
+	if _, err := w.TimeMachineRange(context.Background(), &Coordinates{}, start, start.Add(23*time.Hour), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = w.TimeMachineRange(context.Background(), &Coordinates{}, start, start.Add(24*time.Hour), time.Hour)
+	var tooLarge *RangeTooLargeError
+	if !errors.As(err, &tooLarge) || tooLarge.Calls != 25 || tooLarge.Max != 24 {
+		t.Fatalf("expected a range too large error, got %v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 24 {
+		t.Errorf("expected 24 calls, got %d", n)
+	}
+
+	w, err = NewOneCall("C", "EN", "key", nil, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = w.TimeMachineRange(context.Background(), &Coordinates{}, start.AddDate(-1, 0, 0), start, time.Hour)
+	if !errors.As(err, &tooLarge) || tooLarge.Max != DefaultMaxRangeCalls {
+		t.Errorf("expected the default maximum, got %v", err)
+	}
+}
+
+// TestTimeMachineRangeCanceled will verify that no call is started once
+// the context is done
+func TestTimeMachineRangeCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls int32
+	srv, opt := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		cancel()
+		w.Write([]byte(`{"data": []}`))
+	}))
+	defer srv.Close()
+
+	w, err := NewOneCall("C", "EN", "key", nil, opt, WithConcurrency(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Unix(1600000000, 0)
+	_, err = w.TimeMachineRange(ctx, &Coordinates{}, start, start.Add(23*time.Hour), time.Hour)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("expected 1 call, got %d", n)
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var errInvalidTZ = errors.New("tz should be in the ±XX:XX format")
+
+// tzPattern matches the time zone format of the API, e.g. "+03:00".
+var tzPattern = regexp.MustCompile(`^[+-]\d{2}:\d{2}$`)
+
+// OneCallDaySummary holds the daily aggregation of a date returned by the
+// "/onecall/day_summary" endpoint.
+type OneCallDaySummary struct {
+	Latitude   float64 `json:"lat"`
+	Longitude  float64 `json:"lon"`
+	TZ         string  `json:"tz"`
+	Date       string  `json:"date"`
+	Units      string  `json:"units"`
+	CloudCover struct {
+		Afternoon float64 `json:"afternoon"`
+	} `json:"cloud_cover"`
+	Humidity struct {
+		Afternoon float64 `json:"afternoon"`
+	} `json:"humidity"`
+	Precipitation struct {
+		Total float64 `json:"total"`
+	} `json:"precipitation"`
+	Temperature struct {
+		Min       float64 `json:"min"`
+		Max       float64 `json:"max"`
+		Afternoon float64 `json:"afternoon"`
+		Night     float64 `json:"night"`
+		Evening   float64 `json:"evening"`
+		Morning   float64 `json:"morning"`
+	} `json:"temperature"`
+	Pressure struct {
+		Afternoon float64 `json:"afternoon"`
+	} `json:"pressure"`
+	Wind struct {
+		Max struct {
+			Speed     float64 `json:"speed"`
+			Direction float64 `json:"direction"`
+		} `json:"max"`
+	} `json:"wind"`
+
+	AppliedUnits *UnitPreferences `json:"-"` // units the values were converted to, if any
+}
+
+// OneCallOverview holds the human readable summary of the weather
+// returned by the "/onecall/overview" endpoint.
+type OneCallOverview struct {
+	Latitude        float64 `json:"lat"`
+	Longitude       float64 `json:"lon"`
+	TZ              string  `json:"tz"`
+	Date            string  `json:"date"`
+	Units           string  `json:"units"`
+	WeatherOverview string  `json:"weather_overview"`
+}
+
+// summaryValues builds the parameters shared by the day summary and the
+// overview.
+func (w *OneCallData) summaryValues(location *Coordinates, date time.Time, tz string) (url.Values, error) {
+	if tz != "" && !tzPattern.MatchString(tz) {
+		return nil, errInvalidTZ
+	}
+
+	v := url.Values{}
+	v.Set("appid", w.Key)
+	v.Set("lat", strconv.FormatFloat(location.Latitude, 'f', -1, 64))
+	v.Set("lon", strconv.FormatFloat(location.Longitude, 'f', -1, 64))
+	v.Set("date", date.Format("2006-01-02"))
+	v.Set("units", w.requestUnit(w.Unit))
+	v.Set("lang", w.Lang)
+	if tz != "" {
+		v.Set("tz", tz)
+	}
+	return v, nil
+}
+
+// getSummary calls the given one call endpoint and decodes the response.
+func (w *OneCallData) getSummary(endpoint string, v url.Values, data interface{}) error {
+	response, err := w.client.Get(fmt.Sprintf(onecallURL, endpoint+"?"+v.Encode()))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if err := checkResponse(response); err != nil {
+		return err
+	}
+	return json.NewDecoder(response.Body).Decode(data)
+}
+
+// DaySummary returns the aggregated weather of the given date, which can
+// be any day from 1979-01-02 up to a year and a half ahead. The day is
+// taken in the tz time zone, e.g. "+03:00", or in the one of the location
+// when tz is empty.
+func (w *OneCallData) DaySummary(location *Coordinates, date time.Time, tz string) (*OneCallDaySummary, error) {
+	v, err := w.summaryValues(location, date, tz)
+	if err != nil {
+		return nil, err
+	}
+
+	var s OneCallDaySummary
+	if err := w.getSummary("/day_summary", v, &s); err != nil {
+		return nil, err
+	}
+	w.applyUnitPreferences(&s)
+
+	return &s, nil
+}
+
+// Overview returns a human readable summary of the weather for today or
+// tomorrow. The date is taken in the tz time zone, e.g. "+03:00", or in
+// the one of the location when tz is empty.
+func (w *OneCallData) Overview(location *Coordinates, date time.Time, tz string) (*OneCallOverview, error) {
+	v, err := w.summaryValues(location, date, tz)
+	if err != nil {
+		return nil, err
+	}
+
+	var o OneCallOverview
+	if err := w.getSummary("/overview", v, &o); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
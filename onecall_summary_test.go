@@ -0,0 +1,162 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/briandowns/openweathermap/units"
+)
+
+// fixtureHandler serves the fixture after checking the request path and
+// query parameters.
+func fixtureHandler(t *testing.T, path, fixture string, query map[string]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			t.Errorf("expected path %s, got %s", path, r.URL.Path)
+		}
+		for k, v := range query {
+			if got := r.URL.Query().Get(k); got != v {
+				t.Errorf("expected %s=%q, got %q", k, v, got)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		http.ServeFile(w, r, "testdata/"+fixture)
+	})
+}
+
+// TestDaySummary will verify that the day summary is requested with the
+// date and tz and decoded
+func TestDaySummary(t *testing.T) {
+	t.Parallel()
+
+	srv, opt := newTestServer(t, fixtureHandler(t, "/data/3.0/onecall/day_summary", "onecall_day_summary.json", map[string]string{
+		"lat":   "33",
+		"lon":   "35",
+		"date":  "2020-03-04",
+		"tz":    "+02:00",
+		"units": "internal",
+		"lang":  "EN",
+	}))
+	defer srv.Close()
+
+	w, err := NewOneCall("K", "EN", "key", []string{}, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := w.DaySummary(&Coordinates{Latitude: 33, Longitude: 35}, time.Date(2020, 3, 4, 0, 0, 0, 0, time.UTC), "+02:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Date != "2020-03-04" || s.TZ != "+02:00" || s.Units != "standard" {
+		t.Errorf("unexpected summary %+v", s)
+	}
+	if s.Temperature.Min != 286.48 || s.Temperature.Max != 299.24 || s.Temperature.Morning != 287.59 {
+		t.Errorf("unexpected temperature %+v", s.Temperature)
+	}
+	if s.Humidity.Afternoon != 33 || s.Pressure.Afternoon != 1015 || s.Wind.Max.Speed != 8.7 || s.Wind.Max.Direction != 120 {
+		t.Errorf("unexpected summary %+v", s)
+	}
+	if s.AppliedUnits != nil {
+		t.Errorf("expected no applied units, got %v", s.AppliedUnits)
+	}
+}
+
+// TestDaySummaryUnitPreferences will verify that the day summary follows
+// the unit preferences
+func TestDaySummaryUnitPreferences(t *testing.T) {
+	t.Parallel()
+
+	srv, opt := newTestServer(t, fixtureHandler(t, "/data/3.0/onecall/day_summary", "onecall_day_summary.json", map[string]string{
+		"units": "metric",
+	}))
+	defer srv.Close()
+
+	w, err := NewOneCall("F", "EN", "key", []string{}, opt, WithUnitPreferences(units.System{
+		Temperature:   units.Celsius,
+		Speed:         units.Knots,
+		Pressure:      units.InchesOfMercury,
+		Distance:      units.Miles,
+		Precipitation: units.Inches,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := w.DaySummary(&Coordinates{Latitude: 33, Longitude: 35}, time.Now(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.AppliedUnits == nil || math.Abs(s.Wind.Max.Speed-16.912) > 0.001 || math.Abs(s.Pressure.Afternoon-29.973) > 0.001 {
+		t.Errorf("unexpected converted summary %+v", s)
+	}
+}
+
+// TestOverview will verify that the overview is requested and decoded
+func TestOverview(t *testing.T) {
+	t.Parallel()
+
+	srv, opt := newTestServer(t, fixtureHandler(t, "/data/3.0/onecall/overview", "onecall_overview.json", map[string]string{
+		"lat":   "51.509865",
+		"lon":   "-0.118092",
+		"date":  "2024-05-13",
+		"units": "metric",
+	}))
+	defer srv.Close()
+
+	w, err := NewOneCall("C", "EN", "key", []string{}, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o, err := w.Overview(&Coordinates{Latitude: 51.509865, Longitude: -0.118092}, time.Date(2024, 5, 13, 9, 0, 0, 0, time.UTC), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Date != "2024-05-13" || o.Units != "metric" || !strings.HasPrefix(o.WeatherOverview, "The current weather is overcast") {
+		t.Errorf("unexpected overview %+v", o)
+	}
+}
+
+// TestSummaryErrors will verify that invalid time zones and API errors
+// are returned
+func TestSummaryErrors(t *testing.T) {
+	t.Parallel()
+
+	srv, opt := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"cod":400,"message":"date is out of range"}`))
+	}))
+	defer srv.Close()
+
+	w, err := NewOneCall("C", "EN", "key", []string{}, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.DaySummary(&Coordinates{}, time.Now(), "3:00"); err != errInvalidTZ {
+		t.Errorf("expected %v, got %v", errInvalidTZ, err)
+	}
+	if _, err := w.Overview(&Coordinates{}, time.Now(), "+3"); err != errInvalidTZ {
+		t.Errorf("expected %v, got %v", errInvalidTZ, err)
+	}
+
+	_, err = w.DaySummary(&Coordinates{}, time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC), "")
+	if apiErr, ok := err.(*APIError); !ok || apiErr.StatusCode != http.StatusBadRequest || apiErr.Message != "date is out of range" {
+		t.Errorf("expected an API error, got %v", err)
+	}
+}
@@ -16,7 +16,6 @@ package openweathermap
 
 import (
 	"net/http"
-	"os"
 	"reflect"
 	"testing"
 	"time"
@@ -30,12 +29,12 @@ func TestNewOneCall(t *testing.T) {
 		t.Logf("Data unit: %s", d)
 
 		if ValidDataUnit(d) {
-			c, err := NewOneCall(d, "en", os.Getenv("OWM_API_KEY"), []string{})
+			c, err := NewOneCall(d, "en", "key", []string{})
 			if err != nil {
 				t.Error(err)
 			}
 
-			if _, err := NewOneCall(d, "blah", os.Getenv("OWM_API_KEY"), []string{}); err != nil {
+			if _, err := NewOneCall(d, "blah", "key", []string{}); err != nil {
 				t.Log("received expected bad language code error")
 			}
 
@@ -53,7 +52,7 @@ func TestNewOneCall(t *testing.T) {
 func TestNewOneCallWithCustomHttpClient(t *testing.T) {
 	hc := http.DefaultClient
 	hc.Timeout = time.Duration(1) * time.Second
-	c, err := NewOneCall("c", "en", os.Getenv("OWM_API_KEY"), []string{}, WithHttpClient(hc))
+	c, err := NewOneCall("c", "en", "key", []string{}, WithHttpClient(hc))
 	if err != nil {
 		t.Error(err)
 	}
@@ -79,7 +78,7 @@ func TestNewOneCallWithInvalidOptions(t *testing.T) {
 	}
 
 	for _, options := range optionsPattern {
-		c, err := NewOneCall("c", "en", os.Getenv("OWM_API_KEY"), []string{}, options...)
+		c, err := NewOneCall("c", "en", "key", []string{}, options...)
 		if err == errInvalidOption {
 			t.Logf("Received expected invalid option error. message: %s", err.Error())
 		} else if err != nil {
@@ -95,7 +94,7 @@ func TestNewOneCallWithInvalidOptions(t *testing.T) {
 // invalid http client
 func TestNewOneCallWithInvalidHttpClient(t *testing.T) {
 
-	c, err := NewOneCall("c", "en", os.Getenv("OWM_API_KEY"), []string{}, WithHttpClient(nil))
+	c, err := NewOneCall("c", "en", "key", []string{}, WithHttpClient(nil))
 	if err == errInvalidHttpClient {
 		t.Logf("Received expected bad client error. message: %s", err.Error())
 	} else if err != nil {
@@ -110,9 +109,13 @@ func TestNewOneCallWithInvalidHttpClient(t *testing.T) {
 // given set of coordinates
 func TestOneCallByCoordinates(t *testing.T) {
 	t.Parallel()
-	c, err := NewOneCall("f", "DE", os.Getenv("OWM_API_KEY"), []string{})
+
+	srv, opt := newFakeServer()
+	defer srv.Close()
+
+	c, err := NewOneCall("f", "DE", "key", []string{}, opt)
 	if err != nil {
-		t.Error("Error creating instance of OneCallData")
+		t.Fatal("Error creating instance of OneCallData")
 	}
 	err = c.OneCallByCoordinates(
 		&Coordinates{
@@ -123,12 +126,18 @@ func TestOneCallByCoordinates(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
+	if c.Timezone != "America/Phoenix" || len(c.Hourly) != 48 || len(c.Alerts) == 0 {
+		t.Errorf("unexpected one call data for %s with %d hours and %d alerts", c.Timezone, len(c.Hourly), len(c.Alerts))
+	}
 }
 
 func TestNewOneCallWithOneExclude(t *testing.T) {
-	c, err := NewOneCall("f", "en", os.Getenv("OWM_API_KEY"), []string{ExcludeAlerts})
+	srv, opt := newFakeServer()
+	defer srv.Close()
+
+	c, err := NewOneCall("f", "en", "key", []string{ExcludeAlerts}, opt)
 	if err != nil {
-		t.Error(err)
+		t.Fatal(err)
 	}
 
 	err = c.OneCallByCoordinates(
@@ -147,9 +156,12 @@ func TestNewOneCallWithOneExclude(t *testing.T) {
 }
 
 func TestNewOneCallWithTwoExcludes(t *testing.T) {
-	c, err := NewOneCall("f", "en", os.Getenv("OWM_API_KEY"), []string{ExcludeAlerts, ExcludeDaily})
+	srv, opt := newFakeServer()
+	defer srv.Close()
+
+	c, err := NewOneCall("f", "en", "key", []string{ExcludeAlerts, ExcludeDaily}, opt)
 	if err != nil {
-		t.Error(err)
+		t.Fatal(err)
 	}
 
 	err = c.OneCallByCoordinates(
@@ -162,7 +174,7 @@ func TestNewOneCallWithTwoExcludes(t *testing.T) {
 		t.Error(err)
 	}
 
-	if len(c.Alerts) > 0 && len(c.Daily) > 0 {
+	if len(c.Alerts) > 0 || len(c.Daily) > 0 {
 		t.Error("exclude alerts and daily fails")
 	}
 }
@@ -171,9 +183,13 @@ func TestNewOneCallWithTwoExcludes(t *testing.T) {
 // given set of coordinates and a time
 func TestOneCallTimeMachine(t *testing.T) {
 	t.Parallel()
-	c, err := NewOneCall("f", "DE", os.Getenv("OWM_API_KEY"), []string{})
+
+	srv, opt := newFakeServer()
+	defer srv.Close()
+
+	c, err := NewOneCall("f", "DE", "key", []string{}, opt)
 	if err != nil {
-		t.Error("Error creating instance of OneCallData")
+		t.Fatal("Error creating instance of OneCallData")
 	}
 	err = c.OneCallTimeMachine(
 		&Coordinates{
@@ -15,9 +15,13 @@
 package openweathermap
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"strings"
+
+	"github.com/briandowns/openweathermap/units"
 )
 
 var (
@@ -29,6 +33,8 @@ var (
 	errForecastUnavailable = errors.New("forecast unavailable")
 	errExcludesUnavailable = errors.New("onecall excludes unavailable")
 	errCountOfCityIDs      = errors.New("count of ids should not be more than 20 per request")
+	errInvalidConcurrency  = errors.New("concurrency should be at least 1")
+	errInvalidLimit        = errors.New("limit should be between 1 and 5")
 )
 
 // DataUnits represents the character chosen to represent the temperature notation
@@ -37,6 +43,7 @@ var (
 	baseURL        = "https://api.openweathermap.org/data/2.5/weather?%s"
 	onecallURL     = "https://api.openweathermap.org/data/3.0/onecall%s"
 	iconURL        = "https://openweathermap.org/img/w/%s"
+	iconWNURL      = "https://openweathermap.org/img/wn/%s%s.png"
 	groupURL       = "http://api.openweathermap.org/data/2.5/group?%s"
 	stationURL     = "https://api.openweathermap.org/data/2.5/station?id=%d"
 	forecast5Base  = "https://api.openweathermap.org/data/2.5/forecast?appid=%s&%s&mode=json&units=%s&lang=%s&cnt=%d"
@@ -45,6 +52,8 @@ var (
 	pollutionURL   = "https://api.openweathermap.org/data/2.5/air_pollution?appid=%s&lat=%s&lon=%s"
 	uvURL          = "https://api.openweathermap.org/data/2.5/"
 	dataPostURL    = "https://openweathermap.org/data/post"
+	measurementURL = "https://api.openweathermap.org/data/3.0/measurements?%s"
+	geoURL         = "https://api.openweathermap.org/geo/1.0/%s?%s"
 )
 
 // LangCodes holds all supported languages to be used
@@ -121,18 +130,59 @@ var Excludes []string = []string{
 // Config will hold default settings to be passed into the
 // "NewCurrent, NewForecast, etc}" functions.
 type Config struct {
-	Mode     string // user choice of JSON or XML
-	Unit     string // measurement for results to be displayed.  F, C, or K
-	Lang     string // should reference a key in the LangCodes map
-	APIKey   string // API Key for connecting to the OWM
-	Username string // Username for posting data
-	Password string // Pasword for posting data
+	Mode     string `json:"mode,omitempty" yaml:"mode,omitempty"`         // user choice of JSON or XML
+	Unit     string `json:"unit,omitempty" yaml:"unit,omitempty"`         // measurement for results to be displayed.  F, C, or K
+	Lang     string `json:"lang,omitempty" yaml:"lang,omitempty"`         // should reference a key in the LangCodes map
+	APIKey   string `json:"api_key,omitempty" yaml:"api_key,omitempty"`   // API Key for connecting to the OWM
+	Username string `json:"username,omitempty" yaml:"username,omitempty"` // Username for posting data
+	Password string `json:"password,omitempty" yaml:"password,omitempty"` // Pasword for posting data
 }
 
 // APIError returned on failed API calls.
 type APIError struct {
-	Message string `json:"message"`
-	COD     string `json:"cod"`
+	Message    string `json:"message"`
+	COD        string `json:"cod"`
+	StatusCode int    `json:"-"`
+}
+
+// Error satisfies the error interface.
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("openweathermap: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("openweathermap: %d %s", e.StatusCode, e.Message)
+}
+
+// IsInvalidKey reports whether the error is the API rejecting the key.
+func IsInvalidKey(err error) bool { return errors.Is(err, errInvalidKey) }
+
+// checkResponse makes sure the API call was successful. A 401 is reported
+// as an invalid key, any other non 2xx status is returned as an *APIError
+// populated from the response body when the API provided one.
+func checkResponse(response *http.Response) error {
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return nil
+	}
+	if response.StatusCode == http.StatusUnauthorized {
+		return errInvalidKey
+	}
+
+	// the API isn't consistent about "cod" being a string or a number
+	var body struct {
+		Message string          `json:"message"`
+		COD     json.RawMessage `json:"cod"`
+	}
+	apiErr := &APIError{
+		COD:        fmt.Sprint(response.StatusCode),
+		StatusCode: response.StatusCode,
+	}
+	if err := json.NewDecoder(response.Body).Decode(&body); err == nil {
+		apiErr.Message = body.Message
+		if cod := strings.Trim(string(body.COD), `"`); cod != "" {
+			apiErr.COD = cod
+		}
+	}
+	return apiErr
 }
 
 // Coordinates struct holds longitude and latitude data in returned
@@ -153,10 +203,11 @@ type Sys struct {
 	Sunset  int     `json:"sunset"`
 }
 
-// Wind struct contains the speed and degree of the wind.
+// Wind struct contains the speed, degree and gust of the wind.
 type Wind struct {
 	Speed float64 `json:"speed"`
 	Deg   float64 `json:"deg"`
+	Gust  float64 `json:"gust,omitempty"`
 }
 
 // Weather struct holds high-level, basic info on the returned
@@ -168,6 +219,17 @@ type Weather struct {
 	Icon        string `json:"icon"`
 }
 
+// Condition returns the condition data for the weather's ID. The bool is
+// false when the ID isn't a known condition code.
+func (w Weather) Condition() (ConditionData, bool) {
+	return LookupCondition(w.ID)
+}
+
+// Group returns the condition group of the weather's ID.
+func (w Weather) Group() ConditionGroup {
+	return ConditionGroupOf(w.ID)
+}
+
 // Main struct contains the temperates, humidity, pressure for the request.
 type Main struct {
 	Temp      float64 `json:"temp"`
@@ -263,13 +325,19 @@ func (c *Config) CheckAPIKeyExists() bool { return len(c.APIKey) > 1 }
 
 // Settings holds the client settings
 type Settings struct {
-	client *http.Client
+	client          *http.Client
+	unitPreferences *units.System
+	concurrency     int
+	maxRangeCalls   int
+	limiter         RateLimiter
 }
 
 // NewSettings returns a new Setting pointer with default http client.
 func NewSettings() *Settings {
 	return &Settings{
-		client: http.DefaultClient,
+		client:        http.DefaultClient,
+		concurrency:   DefaultConcurrency,
+		maxRangeCalls: DefaultMaxRangeCalls,
 	}
 }
 
@@ -287,6 +355,22 @@ func WithHttpClient(c *http.Client) Option {
 	}
 }
 
+// DefaultConcurrency is the number of requests a client makes at once
+// when a call needs several of them.
+const DefaultConcurrency = 4
+
+// WithConcurrency sets the number of requests made at once when a call
+// needs several of them, e.g. TimeMachineRange.
+func WithConcurrency(n int) Option {
+	return func(s *Settings) error {
+		if n < 1 {
+			return errInvalidConcurrency
+		}
+		s.concurrency = n
+		return nil
+	}
+}
+
 // setOptions sets Optional client settings to the Settings pointer
 func setOptions(settings *Settings, options []Option) error {
 	for _, option := range options {
@@ -15,7 +15,14 @@
 package openweathermap
 
 import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
 	"testing"
+
+	"github.com/briandowns/openweathermap/owmtest"
+	"gopkg.in/yaml.v3"
 )
 
 // TestValidDataUnit tests whether or not ValidDataUnit provides
@@ -63,3 +70,97 @@ func TestSetOptionsWithEmpty(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+// TestAPIErrors will verify that every endpoint reports an error status
+// instead of decoding the error body
+func TestAPIErrors(t *testing.T) {
+	t.Parallel()
+
+	srv, opt := newFakeServer()
+	defer srv.Close()
+
+	current, _ := NewCurrent("C", "EN", "key", opt)
+	group, _ := NewCurrentGroup("C", "EN", "key", opt)
+	forecast5, _ := NewForecast("5", "C", "EN", "key", opt)
+	forecast16, _ := NewForecast("16", "C", "EN", "key", opt)
+	history, _ := NewHistorical("C", "key", opt)
+	oneCall, _ := NewOneCall("C", "EN", "key", nil, opt)
+	pollution, _ := NewPollution("key", opt)
+	uv, _ := NewUV("key", opt)
+	coord := &Coordinates{Latitude: 33.45, Longitude: -112.07}
+
+	calls := map[string]func() error{
+		"CurrentByName":      func() error { return current.CurrentByName("Phoenix") },
+		"CurrentByZip":       func() error { return current.CurrentByZip(85004, "US") },
+		"CurrentByIDs":       func() error { return group.CurrentByIDs(5308655) },
+		"Forecast5":          func() error { return forecast5.DailyByID(5308655, 5) },
+		"Forecast16":         func() error { return forecast16.DailyByCoordinates(coord, 5) },
+		"HistoryByName":      func() error { return history.HistoryByName("Phoenix") },
+		"OneCallByCoord":     func() error { return oneCall.OneCallByCoordinates(coord) },
+		"OneCallTimeMachine": func() error { return oneCall.OneCallTimeMachine(coord, owmtest.DefaultTime) },
+		"PollutionByParams":  func() error { return pollution.PollutionByParams(&PollutionParameters{Location: *coord}) },
+		"UVCurrent":          func() error { return uv.Current(coord) },
+	}
+	for name, call := range calls {
+		if err := call(); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		for _, status := range []int{http.StatusNotFound, http.StatusTooManyRequests, http.StatusServiceUnavailable} {
+			srv.Inject("", owmtest.Fault{Status: status, Times: 1})
+			var apiErr *APIError
+			if err := call(); !errors.As(err, &apiErr) || apiErr.StatusCode != status || apiErr.Message == "" {
+				t.Errorf("%s: expected a %d API error, got %v", name, status, err)
+			}
+		}
+		srv.Inject("", owmtest.Fault{Status: http.StatusUnauthorized, Times: 1})
+		if err := call(); !IsInvalidKey(err) {
+			t.Errorf("%s: expected an invalid key, got %v", name, err)
+		}
+	}
+}
+
+// TestKeyNotMarshaled will verify that the API key is left out of the
+// JSON of the results
+func TestKeyNotMarshaled(t *testing.T) {
+	t.Parallel()
+
+	const key = "0123456789abcdef"
+	for _, v := range []interface{}{
+		&CurrentWeatherData{Key: key},
+		&CurrentWeatherGroup{Key: key},
+		&ForecastWeatherData{Key: key},
+		&HistoricalWeatherData{Key: key},
+		&OneCallData{Key: key},
+		&Pollution{Key: key},
+		&UV{Key: key},
+	} {
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(b), key) {
+			t.Errorf("expected no key in %s", b)
+		}
+	}
+}
+
+// TestConfigTags will verify the names of the Config fields in JSON and
+// YAML
+func TestConfigTags(t *testing.T) {
+	t.Parallel()
+
+	want := Config{Unit: "F", Lang: "DE", APIKey: "0123456789abcdef"}
+
+	var c Config
+	if err := yaml.Unmarshal([]byte("unit: F\nlang: DE\napi_key: 0123456789abcdef\n"), &c); err != nil || c != want {
+		t.Errorf("expected %+v from YAML, got %+v (%v)", want, c, err)
+	}
+
+	b, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"unit":"F","lang":"DE","api_key":"0123456789abcdef"}` {
+		t.Errorf("unexpected JSON %s", b)
+	}
+}
@@ -0,0 +1,578 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package owmtest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// City is a location known to the fake server along with the conditions
+// reported for it. Temperatures are in Kelvin and speeds in m/s, as the
+// API's standard units.
+type City struct {
+	ID       int
+	Name     string
+	Country  string
+	State    string
+	Zip      string
+	Lat      float64
+	Lon      float64
+	TimeZone string // IANA name, e.g. "America/Phoenix"
+	Offset   int    // offset from UTC in seconds
+
+	Temp      float64
+	Humidity  int
+	Pressure  float64
+	WindSpeed float64
+	WindDeg   int
+	Clouds    int
+	WeatherID int
+	UVI       float64
+	AQI       int
+}
+
+// Cities are the locations known to the fake server.
+var Cities = []City{
+	{ID: 4560349, Name: "Philadelphia", Country: "US", State: "Pennsylvania", Zip: "19125", Lat: 39.9523, Lon: -75.1638, TimeZone: "America/New_York", Offset: -14400, Temp: 275.15, Humidity: 65, Pressure: 1021, WindSpeed: 4.63, WindDeg: 300, Clouds: 20, WeatherID: 801, UVI: 2.1, AQI: 2},
+	{ID: 4930956, Name: "Boston", Country: "US", State: "Massachusetts", Zip: "02134", Lat: 42.3584, Lon: -71.0598, TimeZone: "America/New_York", Offset: -14400, Temp: 280.37, Humidity: 58, Pressure: 1019, WindSpeed: 6.17, WindDeg: 320, Clouds: 20, WeatherID: 801, UVI: 2.6, AQI: 1},
+	{ID: 5101798, Name: "Newark", Country: "US", State: "New Jersey", Zip: "07102", Lat: 40.7357, Lon: -74.1724, TimeZone: "America/New_York", Offset: -14400, Temp: 275.572, Humidity: 70, Pressure: 1020, WindSpeed: 5.14, WindDeg: 290, Clouds: 75, WeatherID: 803, UVI: 1.8, AQI: 2},
+	{ID: 5656882, Name: "Helena", Country: "US", State: "Montana", Zip: "59601", Lat: 46.5927, Lon: -112.0361, TimeZone: "America/Denver", Offset: -21600, Temp: 279.15, Humidity: 45, Pressure: 1015, WindSpeed: 3.6, WindDeg: 240, Clouds: 0, WeatherID: 800, UVI: 4.2, AQI: 1},
+	{ID: 5391811, Name: "San Diego", Country: "US", State: "California", Zip: "92101", Lat: 32.7153, Lon: -117.1573, TimeZone: "America/Los_Angeles", Offset: -25200, Temp: 286.778, Humidity: 72, Pressure: 1014, WindSpeed: 2.57, WindDeg: 270, Clouds: 40, WeatherID: 802, UVI: 6.4, AQI: 2},
+	{ID: 5368361, Name: "Los Angeles", Country: "US", State: "California", Zip: "90012", Lat: 34.0522, Lon: -118.2437, TimeZone: "America/Los_Angeles", Offset: -25200, Temp: 291.48, Humidity: 60, Pressure: 1013, WindSpeed: 3.09, WindDeg: 250, Clouds: 0, WeatherID: 721, UVI: 7.3, AQI: 3},
+	{ID: 5308655, Name: "Phoenix", Country: "US", State: "Arizona", Zip: "85004", Lat: 33.4484, Lon: -112.074, TimeZone: "America/Phoenix", Offset: -25200, Temp: 310.93, Humidity: 8, Pressure: 1008, WindSpeed: 4.12, WindDeg: 210, Clouds: 0, WeatherID: 800, UVI: 10.2, AQI: 2},
+	{ID: 6173331, Name: "Vancouver", Country: "CA", State: "British Columbia", Zip: "V6B", Lat: 49.2497, Lon: -123.1193, TimeZone: "America/Vancouver", Offset: -25200, Temp: 284.26, Humidity: 88, Pressure: 1009, WindSpeed: 2.06, WindDeg: 110, Clouds: 100, WeatherID: 500, UVI: 1.2, AQI: 1},
+	{ID: 292223, Name: "Dubai", Country: "AE", Lat: 25.2582, Lon: 55.3047, TimeZone: "Asia/Dubai", Offset: 14400, Temp: 308.15, Humidity: 40, Pressure: 1006, WindSpeed: 5.66, WindDeg: 330, Clouds: 0, WeatherID: 800, UVI: 11.3, AQI: 3},
+	{ID: 524901, Name: "Moscow", Country: "RU", Lat: 55.7522, Lon: 37.6156, TimeZone: "Europe/Moscow", Offset: 10800, Temp: 281.82, Humidity: 54, Pressure: 1012, WindSpeed: 3.02, WindDeg: 180, Clouds: 90, WeatherID: 804, UVI: 3.4, AQI: 2},
+	{ID: 2950159, Name: "Berlin", Country: "DE", Zip: "10117", Lat: 52.5244, Lon: 13.4105, TimeZone: "Europe/Berlin", Offset: 7200, Temp: 289.51, Humidity: 62, Pressure: 1018, WindSpeed: 4.12, WindDeg: 260, Clouds: 75, WeatherID: 803, UVI: 4.8, AQI: 2},
+	{ID: 2643743, Name: "London", Country: "GB", State: "England", Lat: 51.5085, Lon: -0.1257, TimeZone: "Europe/London", Offset: 3600, Temp: 288.97, Humidity: 77, Pressure: 1011, WindSpeed: 5.66, WindDeg: 230, Clouds: 100, WeatherID: 501, UVI: 3.1, AQI: 2},
+}
+
+// weatherDescriptions holds the main group, description and icon of the
+// conditions used by the cities.
+var weatherDescriptions = map[int][3]string{
+	500: {"Rain", "light rain", "10"},
+	501: {"Rain", "moderate rain", "10"},
+	721: {"Haze", "haze", "50"},
+	800: {"Clear", "clear sky", "01"},
+	801: {"Clouds", "few clouds", "02"},
+	802: {"Clouds", "scattered clouds", "03"},
+	803: {"Clouds", "broken clouds", "04"},
+	804: {"Clouds", "overcast clouds", "04"},
+}
+
+// findCity looks up the city by ID.
+func findCity(id int) (City, bool) {
+	for _, c := range Cities {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return City{}, false
+}
+
+// findCityByName looks up the city by name, ignoring case and anything
+// after a comma, e.g. "San Diego, CA".
+func findCityByName(q string) (City, bool) {
+	name := strings.TrimSpace(strings.Split(q, ",")[0])
+	for _, c := range Cities {
+		if strings.EqualFold(c.Name, name) {
+			return c, true
+		}
+	}
+	return City{}, false
+}
+
+// findCityByZip looks up the city by zip code, e.g. "19125,US".
+func findCityByZip(zip string) (City, bool) {
+	parts := strings.Split(zip, ",")
+	for _, c := range Cities {
+		if c.Zip != "" && c.Zip == strings.TrimSpace(parts[0]) {
+			return c, true
+		}
+	}
+	return City{}, false
+}
+
+// nearestCity returns the city closest to the coordinates.
+func nearestCity(lat, lon float64) City {
+	return nearestCities(lat, lon, 1)[0]
+}
+
+// nearestCities returns the n cities closest to the coordinates, closest
+// first.
+func nearestCities(lat, lon float64, n int) []City {
+	cities := append([]City(nil), Cities...)
+	sort.SliceStable(cities, func(i, j int) bool {
+		return math.Hypot(cities[i].Lat-lat, cities[i].Lon-lon) < math.Hypot(cities[j].Lat-lat, cities[j].Lon-lon)
+	})
+	if n > len(cities) {
+		n = len(cities)
+	}
+	return cities[:n]
+}
+
+// units converts the standard values into the requested units.
+type units string
+
+func (u units) temp(k float64) float64 {
+	switch u {
+	case "metric":
+		return round(k - 273.15)
+	case "imperial":
+		return round((k-273.15)*1.8 + 32)
+	}
+	return round(k)
+}
+
+func (u units) speed(ms float64) float64 {
+	if u == "imperial" {
+		return round(ms * 2.236936)
+	}
+	return round(ms)
+}
+
+func (u units) name() string {
+	switch u {
+	case "metric", "imperial":
+		return string(u)
+	}
+	return "standard"
+}
+
+func round(v float64) float64 { return math.Round(v*100) / 100 }
+
+// object is a JSON object of a response.
+type object = map[string]interface{}
+
+// weather returns the weather list of the condition.
+func weather(id int, day bool) []object {
+	d, ok := weatherDescriptions[id]
+	if !ok {
+		d = weatherDescriptions[800]
+	}
+	icon := d[2] + "n"
+	if day {
+		icon = d[2] + "d"
+	}
+	return []object{{"id": id, "main": d[0], "description": d[1], "icon": icon}}
+}
+
+// variation returns the change of temperature at the hour of the day, a
+// few degrees warmer in the afternoon than at night.
+func variation(t time.Time, c City) float64 {
+	local := t.UTC().Add(time.Duration(c.Offset) * time.Second)
+	hour := float64(local.Hour()) + float64(local.Minute())/60
+	return 4 * math.Cos((hour-15)/24*2*math.Pi)
+}
+
+// sunTimes returns rough sunrise and sunset times of the day, 6am and
+// 8pm local time.
+func sunTimes(t time.Time, c City) (int64, int64) {
+	local := t.Add(time.Duration(c.Offset) * time.Second).UTC()
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC).Unix() - int64(c.Offset)
+	return midnight + 6*3600, midnight + 20*3600
+}
+
+// daytime reports whether the sun is up at the time.
+func daytime(t time.Time, c City) bool {
+	rise, set := sunTimes(t, c)
+	return t.Unix() >= rise && t.Unix() < set
+}
+
+// rain returns the rain rate in mm/h of the city at the time, when the
+// condition is rain.
+func rain(t time.Time, c City) float64 {
+	if c.WeatherID < 500 || c.WeatherID >= 600 {
+		return 0
+	}
+	return round(0.6 + 0.4*math.Sin(float64(t.Unix())/3600))
+}
+
+// current returns the current weather of the city at the time.
+func current(c City, t time.Time, u units) object {
+	temp := c.Temp
+	rise, set := sunTimes(t, c)
+	o := object{
+		"coord":      object{"lon": c.Lon, "lat": c.Lat},
+		"weather":    weather(c.WeatherID, daytime(t, c)),
+		"base":       "stations",
+		"main":       mainObject(c, temp, u),
+		"visibility": 10000,
+		"wind":       object{"speed": u.speed(c.WindSpeed), "deg": c.WindDeg, "gust": u.speed(c.WindSpeed * 1.6)},
+		"clouds":     object{"all": c.Clouds},
+		"dt":         t.Unix(),
+		"sys":        object{"type": 2, "id": 2000000 + c.ID%1000, "country": c.Country, "sunrise": rise, "sunset": set},
+		"timezone":   c.Offset,
+		"id":         c.ID,
+		"name":       c.Name,
+		"cod":        200,
+	}
+	if r := rain(t, c); r > 0 {
+		o["rain"] = object{"1h": r}
+	}
+	return o
+}
+
+// mainObject returns the main values of the weather.
+func mainObject(c City, temp float64, u units) object {
+	return object{
+		"temp":       u.temp(temp),
+		"feels_like": u.temp(temp - 1.2),
+		"temp_min":   u.temp(temp - 1.5),
+		"temp_max":   u.temp(temp + 1.5),
+		"pressure":   c.Pressure,
+		"humidity":   c.Humidity,
+		"sea_level":  c.Pressure,
+		"grnd_level": c.Pressure - 6,
+	}
+}
+
+// cityObject returns the city of the forecasts.
+func cityObject(c City, t time.Time) object {
+	rise, set := sunTimes(t, c)
+	return object{
+		"id":         c.ID,
+		"name":       c.Name,
+		"coord":      object{"lat": c.Lat, "lon": c.Lon},
+		"country":    c.Country,
+		"population": 1000000,
+		"timezone":   c.Offset,
+		"sunrise":    rise,
+		"sunset":     set,
+	}
+}
+
+// forecast returns the 3 hour forecast of the city from the time.
+func forecast(c City, t time.Time, u units, cnt int) object {
+	start := t.Truncate(3 * time.Hour).Add(3 * time.Hour)
+	list := make([]object, cnt)
+	for i := range list {
+		at := start.Add(time.Duration(i) * 3 * time.Hour)
+		pod := "n"
+		if daytime(at, c) {
+			pod = "d"
+		}
+		e := object{
+			"dt":         at.Unix(),
+			"main":       mainObject(c, c.Temp+variation(at, c)-variation(t, c), u),
+			"weather":    weather(c.WeatherID, pod == "d"),
+			"clouds":     object{"all": c.Clouds},
+			"wind":       object{"speed": u.speed(c.WindSpeed), "deg": c.WindDeg, "gust": u.speed(c.WindSpeed * 1.6)},
+			"visibility": 10000,
+			"pop":        0,
+			"sys":        object{"pod": pod},
+			"dt_txt":     at.UTC().Format("2006-01-02 15:04:05"),
+		}
+		if r := rain(at, c); r > 0 {
+			e["rain"] = object{"3h": round(3 * r)}
+			e["pop"] = 0.8
+		}
+		list[i] = e
+	}
+	return object{"cod": "200", "message": 0, "cnt": cnt, "list": list, "city": cityObject(c, t)}
+}
+
+// daily returns the daily forecast of the city from the day of the time.
+func daily(c City, t time.Time, u units, cnt int) object {
+	list := make([]object, cnt)
+	for i := range list {
+		day := t.AddDate(0, 0, i)
+		rise, set := sunTimes(day, c)
+		noon := time.Unix((rise+set)/2, 0)
+		temp := func(hour int) float64 {
+			return u.temp(c.Temp + variation(noon.Add(time.Duration(hour-13)*time.Hour), c) - variation(t, c))
+		}
+		e := object{
+			"dt":         noon.Unix(),
+			"sunrise":    rise,
+			"sunset":     set,
+			"temp":       object{"day": temp(13), "min": temp(3), "max": temp(15), "night": temp(1), "eve": temp(19), "morn": temp(7)},
+			"feels_like": object{"day": temp(13) - 1, "night": temp(1) - 1, "eve": temp(19) - 1, "morn": temp(7) - 1},
+			"pressure":   c.Pressure,
+			"humidity":   c.Humidity,
+			"weather":    weather(c.WeatherID, true),
+			"speed":      u.speed(c.WindSpeed),
+			"deg":        c.WindDeg,
+			"gust":       u.speed(c.WindSpeed * 1.6),
+			"clouds":     c.Clouds,
+			"pop":        0,
+		}
+		if r := rain(noon, c); r > 0 {
+			e["rain"] = round(8 * r)
+			e["pop"] = 0.9
+		}
+		list[i] = e
+	}
+	return object{"city": cityObject(c, t), "cnt": cnt, "list": list}
+}
+
+// hourlyObject returns the one call data of an hour.
+func hourlyObject(c City, at, t time.Time, u units) object {
+	temp := c.Temp + variation(at, c) - variation(t, c)
+	o := object{
+		"dt":         at.Unix(),
+		"temp":       u.temp(temp),
+		"feels_like": u.temp(temp - 1.2),
+		"pressure":   c.Pressure,
+		"humidity":   c.Humidity,
+		"dew_point":  u.temp(temp - float64(100-c.Humidity)/5),
+		"uvi":        c.UVI,
+		"clouds":     c.Clouds,
+		"visibility": 10000,
+		"wind_speed": u.speed(c.WindSpeed),
+		"wind_deg":   c.WindDeg,
+		"wind_gust":  u.speed(c.WindSpeed * 1.6),
+		"weather":    weather(c.WeatherID, daytime(at, c)),
+	}
+	if r := rain(at, c); r > 0 {
+		o["rain"] = object{"1h": r}
+	}
+	return o
+}
+
+// oneCall returns the one call data of the city at the time, without the
+// excluded parts.
+func oneCall(c City, t time.Time, u units, exclude []string) object {
+	o := object{
+		"lat":             c.Lat,
+		"lon":             c.Lon,
+		"timezone":        c.TimeZone,
+		"timezone_offset": c.Offset,
+	}
+	excluded := func(part string) bool {
+		for _, e := range exclude {
+			if strings.EqualFold(strings.TrimSpace(e), part) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if !excluded("current") {
+		cur := hourlyObject(c, t, t, u)
+		cur["sunrise"], cur["sunset"] = sunTimes(t, c)
+		o["current"] = cur
+	}
+	if !excluded("minutely") {
+		start := t.Truncate(time.Minute)
+		minutely := make([]object, 61)
+		for i := range minutely {
+			at := start.Add(time.Duration(i) * time.Minute)
+			minutely[i] = object{"dt": at.Unix(), "precipitation": rain(at, c)}
+		}
+		o["minutely"] = minutely
+	}
+	if !excluded("hourly") {
+		start := t.Truncate(time.Hour)
+		hourly := make([]object, 48)
+		for i := range hourly {
+			h := hourlyObject(c, start.Add(time.Duration(i)*time.Hour), t, u)
+			h["pop"] = 0
+			if _, ok := h["rain"]; ok {
+				h["pop"] = 0.8
+			}
+			hourly[i] = h
+		}
+		o["hourly"] = hourly
+	}
+	if !excluded("daily") {
+		days := daily(c, t, u, 8)["list"].([]object)
+		for _, d := range days {
+			rise := d["sunrise"].(int64)
+			d["moonrise"], d["moonset"], d["moon_phase"] = rise+10*3600, rise+22*3600, 0.25
+			d["wind_speed"], d["wind_deg"], d["wind_gust"] = d["speed"], d["deg"], d["gust"]
+			d["dew_point"] = u.temp(c.Temp - float64(100-c.Humidity)/5)
+			d["uvi"] = c.UVI
+			delete(d, "speed")
+			delete(d, "deg")
+			delete(d, "gust")
+		}
+		o["daily"] = days
+	}
+	if !excluded("alerts") && c.Temp > 305 {
+		o["alerts"] = []object{{
+			"sender_name": "NWS " + c.Name,
+			"event":       "Excessive Heat Warning",
+			"start":       t.Truncate(24 * time.Hour).Add(17 * time.Hour).Unix(),
+			"end":         t.Truncate(24 * time.Hour).Add(27 * time.Hour).Unix(),
+			"description": "Dangerously hot conditions with temperatures up to 115 expected.",
+			"tags":        []string{"Extreme temperature value"},
+		}}
+	}
+	return o
+}
+
+// timeMachine returns the one call data of the city at a past time.
+func timeMachine(c City, at, t time.Time, u units) object {
+	d := hourlyObject(c, at, t, u)
+	d["sunrise"], d["sunset"] = sunTimes(at, c)
+	return object{
+		"lat":             c.Lat,
+		"lon":             c.Lon,
+		"timezone":        c.TimeZone,
+		"timezone_offset": c.Offset,
+		"data":            []object{d},
+	}
+}
+
+// daySummary returns the aggregated weather of the city on the date.
+func daySummary(c City, date, tz string, u units) object {
+	day, _ := time.Parse("2006-01-02", date)
+	temp := func(hour int) float64 {
+		return u.temp(c.Temp + variation(day.Add(time.Duration(hour)*time.Hour-time.Duration(c.Offset)*time.Second), c))
+	}
+	if tz == "" {
+		tz = offsetString(c.Offset)
+	}
+	precipitation := 0.0
+	if r := rain(day, c); r > 0 {
+		precipitation = round(24 * r)
+	}
+	return object{
+		"lat":           c.Lat,
+		"lon":           c.Lon,
+		"tz":            tz,
+		"date":          date,
+		"units":         u.name(),
+		"cloud_cover":   object{"afternoon": c.Clouds},
+		"humidity":      object{"afternoon": c.Humidity},
+		"precipitation": object{"total": precipitation},
+		"temperature":   object{"min": temp(4), "max": temp(15), "afternoon": temp(12), "night": temp(0), "evening": temp(18), "morning": temp(6)},
+		"pressure":      object{"afternoon": c.Pressure},
+		"wind":          object{"max": object{"speed": u.speed(c.WindSpeed * 1.6), "direction": c.WindDeg}},
+	}
+}
+
+// overview returns the weather overview of the city on the date.
+func overview(c City, date, tz string, u units) object {
+	if tz == "" {
+		tz = offsetString(c.Offset)
+	}
+	d := weatherDescriptions[c.WeatherID]
+	symbol := "K"
+	switch u {
+	case "metric":
+		symbol = "°C"
+	case "imperial":
+		symbol = "°F"
+	}
+	return object{
+		"lat":   c.Lat,
+		"lon":   c.Lon,
+		"tz":    tz,
+		"date":  date,
+		"units": u.name(),
+		"weather_overview": "The current weather in " + c.Name + " is " + d[1] + " with a temperature of " +
+			formatFloat(u.temp(c.Temp)) + symbol + " and a humidity of " + formatFloat(float64(c.Humidity)) + "%.",
+	}
+}
+
+// airPollution returns the air quality of the city at the times.
+func airPollution(c City, times []time.Time) object {
+	list := make([]object, len(times))
+	for i, at := range times {
+		f := float64(c.AQI)
+		list[i] = object{
+			"dt":   at.Unix(),
+			"main": object{"aqi": c.AQI},
+			"components": object{
+				"co": round(200 + 50*f), "no": round(0.1 * f), "no2": round(5 * f), "o3": round(30 + 10*f),
+				"so2": round(1.5 * f), "pm2_5": round(4 * f), "pm10": round(6 * f), "nh3": round(0.8 * f),
+			},
+		}
+	}
+	return object{"coord": object{"lon": c.Lon, "lat": c.Lat}, "list": list}
+}
+
+// uvi returns the UV index of the city at the time.
+func uvi(c City, at time.Time) object {
+	return object{
+		"lat":      c.Lat,
+		"lon":      c.Lon,
+		"date_iso": at.UTC().Format(time.RFC3339),
+		"date":     at.Unix(),
+		"value":    c.UVI,
+	}
+}
+
+// history returns the hourly history of the city over the times.
+func history(c City, times []time.Time, t time.Time, u units) object {
+	list := make([]object, len(times))
+	for i, at := range times {
+		e := object{
+			"dt":      at.Unix(),
+			"main":    mainObject(c, c.Temp+variation(at, c)-variation(t, c), u),
+			"wind":    object{"speed": u.speed(c.WindSpeed), "deg": c.WindDeg},
+			"clouds":  object{"all": c.Clouds},
+			"weather": weather(c.WeatherID, daytime(at, c)),
+		}
+		if r := rain(at, c); r > 0 {
+			e["rain"] = object{"1h": r}
+		}
+		list[i] = e
+	}
+	return object{"message": "Count: " + formatFloat(float64(len(list))), "city_id": c.ID, "calctime": 0.0123, "cnt": len(list), "list": list}
+}
+
+// geocode is the entry of the city in the geocoding API.
+func geocode(c City) object {
+	o := object{
+		"name":        c.Name,
+		"local_names": object{"en": c.Name},
+		"lat":         c.Lat,
+		"lon":         c.Lon,
+		"country":     c.Country,
+	}
+	if c.State != "" {
+		o["state"] = c.State
+	}
+	return o
+}
+
+// aggregated is a station measurement aggregated over the period starting
+// at the time. Stations report the conditions of Berlin in metric units.
+func aggregated(station, typ string, t time.Time) object {
+	c, _ := findCity(2950159)
+	temp := c.Temp - 273.15 + variation(t, c)
+	value := func(v, spread float64) object {
+		return object{"min": round(v - spread), "max": round(v + spread), "average": round(v), "weight": 1}
+	}
+	return object{
+		"type":          typ,
+		"date":          t.Unix(),
+		"station_id":    station,
+		"temp":          value(temp, 0.5),
+		"humidity":      value(float64(c.Humidity), 2),
+		"pressure":      value(c.Pressure, 1),
+		"wind":          object{"deg": c.WindDeg, "speed": c.WindSpeed},
+		"precipitation": object{"rain": rain(t, c), "snow": 0},
+	}
+}
+
+// offsetString formats the offset as the API's tz, e.g. "+02:00".
+func offsetString(offset int) string {
+	sign := '+'
+	if offset < 0 {
+		sign, offset = '-', -offset
+	}
+	return fmt.Sprintf("%c%02d:%02d", sign, offset/3600, offset%3600/60)
+}
+
+func formatFloat(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
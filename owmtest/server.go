@@ -0,0 +1,508 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package owmtest provides a fake OpenWeatherMap server for tests. It
+// answers the current weather, group, forecast, one call, air pollution,
+// UV index, history, geocoding and station measurement endpoints with
+// canned data for a set of cities, and can inject faults such as error
+// statuses, slow responses and malformed JSON.
+//
+//	srv := owmtest.NewServer()
+//	defer srv.Close()
+//
+//	w, err := openweathermap.NewCurrent("C", "EN", "key", openweathermap.WithHttpClient(srv.Client()))
+package owmtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTime is the time the fake server reports the current weather
+// for, unless set with SetTime.
+var DefaultTime = time.Date(2024, 5, 13, 12, 0, 0, 0, time.UTC)
+
+// Error bodies of the API.
+const (
+	unauthorizedMessage = "Invalid API key. Please see https://openweathermap.org/faq#error401 for more info."
+	notFoundMessage     = "city not found"
+	rateLimitedMessage  = "Your account is temporary blocked due to exceeding of requests limitation of your subscription type. Please choose the proper subscription https://openweathermap.org/price"
+	serverErrorMessage  = "Internal error"
+)
+
+// Fault changes the response of the endpoints it's injected on.
+type Fault struct {
+	Status    int           // status returned with the API's error body, when not zero
+	Delay     time.Duration // delay before responding
+	Malformed bool          // truncate the JSON of the response
+	Times     int           // number of requests affected, zero for all
+}
+
+// Unauthorized returns the response to an invalid API key.
+func Unauthorized() Fault { return Fault{Status: http.StatusUnauthorized} }
+
+// NotFound returns the response to an unknown city.
+func NotFound() Fault { return Fault{Status: http.StatusNotFound} }
+
+// RateLimited returns the response of an account over its limit.
+func RateLimited() Fault { return Fault{Status: http.StatusTooManyRequests} }
+
+// ServerError returns an internal error with the given 5xx status.
+func ServerError(status int) Fault { return Fault{Status: status} }
+
+// Slow delays the response by d.
+func Slow(d time.Duration) Fault { return Fault{Delay: d} }
+
+// Malformed truncates the JSON of the response.
+func Malformed() Fault { return Fault{Malformed: true} }
+
+// Request is a request received by the fake server.
+type Request struct {
+	Endpoint string // path without the API version, e.g. "/weather"
+	Query    url.Values
+}
+
+// Server is a fake OpenWeatherMap server.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	now      time.Time
+	key      string
+	faults   map[string][]*Fault
+	requests []Request
+}
+
+// NewServer starts and returns a new fake server. It should be closed
+// when done.
+func NewServer() *Server {
+	s := &Server{
+		now:    DefaultTime,
+		faults: make(map[string][]*Fault),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// rewriteTransport sends every request to the target whatever host it
+// was built for.
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (rt *rewriteTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.URL.Scheme = rt.target.Scheme
+	r.URL.Host = rt.target.Host
+	r.Host = rt.target.Host
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+// Client returns an HTTP client sending every request to the fake
+// server, so the client's URLs don't need to change.
+func (s *Server) Client() *http.Client {
+	return NewClient(s.URL)
+}
+
+// NewClient returns an HTTP client sending every request to the server
+// at the URL, e.g. an httptest.Server with a custom handler, whatever
+// host the request was built for.
+func NewClient(serverURL string) *http.Client {
+	target, _ := url.Parse(serverURL)
+	return &http.Client{Transport: &rewriteTransport{target: target}}
+}
+
+// SetTime sets the time the current weather is reported for.
+func (s *Server) SetTime(t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = t
+}
+
+// RequireKey makes the server answer with a 401 to requests without the
+// key. Any key is accepted by default.
+func (s *Server) RequireKey(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.key = key
+}
+
+// Inject adds a fault to the endpoint, e.g. "/weather" or
+// "/onecall/timemachine", or to every endpoint when empty. Faults apply
+// in the order they're injected.
+func (s *Server) Inject(endpoint string, f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[endpoint] = append(s.faults[endpoint], &f)
+}
+
+// Reset removes the faults and forgets the requests.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = make(map[string][]*Fault)
+	s.requests = nil
+}
+
+// Requests returns the requests received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Count returns the number of requests received by the endpoint.
+func (s *Server) Count(endpoint string) int {
+	n := 0
+	for _, r := range s.Requests() {
+		if r.Endpoint == endpoint {
+			n++
+		}
+	}
+	return n
+}
+
+// fault returns the fault to apply to the request, using it up.
+func (s *Server) fault(endpoint string) *Fault {
+	for _, key := range []string{endpoint, ""} {
+		for i, f := range s.faults[key] {
+			if f.Times > 0 {
+				f.Times--
+				if f.Times == 0 {
+					s.faults[key] = append(s.faults[key][:i], s.faults[key][i+1:]...)
+				}
+			}
+			return f
+		}
+	}
+	return nil
+}
+
+// endpoint strips the API version from the path.
+func endpoint(path string) string {
+	for _, prefix := range []string{"/data/2.5", "/data/3.0", "/geo/1.0"} {
+		if strings.HasPrefix(path, prefix+"/") {
+			return strings.TrimPrefix(path, prefix)
+		}
+	}
+	return path
+}
+
+// writeError writes the API's error body.
+func writeError(w http.ResponseWriter, status int) {
+	message := serverErrorMessage
+	switch status {
+	case http.StatusUnauthorized:
+		message = unauthorizedMessage
+	case http.StatusNotFound:
+		message = notFoundMessage
+	case http.StatusTooManyRequests:
+		message = rateLimitedMessage
+	case http.StatusBadRequest:
+		message = "Nothing to geocode"
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(object{"cod": strconv.Itoa(status), "message": message})
+}
+
+// serve answers a request, applying the faults.
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	e := endpoint(r.URL.Path)
+	q := r.URL.Query()
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Endpoint: e, Query: q})
+	var f Fault
+	if p := s.fault(e); p != nil {
+		f = *p
+	}
+	now, key := s.now, s.key
+	s.mu.Unlock()
+
+	if f.Delay > 0 {
+		select {
+		case <-time.After(f.Delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if f.Status != 0 {
+		writeError(w, f.Status)
+		return
+	}
+	if strings.HasPrefix(e, "/img/") {
+		serveIcon(w, e)
+		return
+	}
+	if key != "" && q.Get("appid") != key {
+		writeError(w, http.StatusUnauthorized)
+		return
+	}
+
+	if e == "/measurements" {
+		serveMeasurements(w, r, q, now)
+		return
+	}
+
+	body, status := s.respond(e, q, now)
+	if status != http.StatusOK {
+		writeError(w, status)
+		return
+	}
+
+	b, err := json.Marshal(body)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError)
+		return
+	}
+	if f.Malformed {
+		b = b[:len(b)/2]
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Write(b)
+}
+
+// locate returns the city the query asks for.
+func locate(q url.Values) (City, bool) {
+	switch {
+	case q.Get("id") != "":
+		id, err := strconv.Atoi(q.Get("id"))
+		if err != nil {
+			return City{}, false
+		}
+		return findCity(id)
+	case q.Get("q") != "":
+		return findCityByName(q.Get("q"))
+	case q.Get("zip") != "":
+		return findCityByZip(q.Get("zip"))
+	case q.Get("lat") != "" && q.Get("lon") != "":
+		lat, err1 := strconv.ParseFloat(q.Get("lat"), 64)
+		lon, err2 := strconv.ParseFloat(q.Get("lon"), 64)
+		if err1 != nil || err2 != nil {
+			return City{}, false
+		}
+		return nearestCity(lat, lon), true
+	}
+	return City{}, false
+}
+
+// count returns the cnt parameter, or def when missing.
+func count(q url.Values, def int) int {
+	if n, err := strconv.Atoi(q.Get("cnt")); err == nil && n > 0 {
+		return n
+	}
+	return def
+}
+
+// unixParam returns the unix time parameter, or def when missing.
+func unixParam(q url.Values, name string, def time.Time) time.Time {
+	if v, err := strconv.ParseInt(q.Get(name), 10, 64); err == nil {
+		return time.Unix(v, 0).UTC()
+	}
+	return def
+}
+
+// hours returns the hours from start to end, at most n of them.
+func hours(start, end time.Time, n int) []time.Time {
+	var times []time.Time
+	for t := start.Truncate(time.Hour); !t.After(end) && len(times) < n; t = t.Add(time.Hour) {
+		times = append(times, t)
+	}
+	return times
+}
+
+// respond returns the body of the endpoint along with the status.
+func (s *Server) respond(e string, q url.Values, now time.Time) (interface{}, int) {
+	u := units(q.Get("units"))
+
+	if e == "/group" {
+		var list []object
+		for _, id := range strings.Split(q.Get("id"), ",") {
+			n, err := strconv.Atoi(id)
+			if err != nil {
+				return nil, http.StatusBadRequest
+			}
+			if c, ok := findCity(n); ok {
+				list = append(list, current(c, now, u))
+			}
+		}
+		return object{"cnt": len(list), "list": list}, http.StatusOK
+	}
+
+	switch e {
+	case "/direct":
+		if q.Get("q") == "" {
+			return nil, http.StatusBadRequest
+		}
+		list := []object{}
+		if c, ok := findCityByName(q.Get("q")); ok {
+			list = append(list, geocode(c))
+		}
+		return list, http.StatusOK
+	case "/zip":
+		c, ok := findCityByZip(q.Get("zip"))
+		if !ok {
+			return nil, http.StatusNotFound
+		}
+		return object{"zip": c.Zip, "name": c.Name, "lat": c.Lat, "lon": c.Lon, "country": c.Country}, http.StatusOK
+	case "/reverse":
+		lat, err1 := strconv.ParseFloat(q.Get("lat"), 64)
+		lon, err2 := strconv.ParseFloat(q.Get("lon"), 64)
+		if err1 != nil || err2 != nil {
+			return nil, http.StatusBadRequest
+		}
+		limit, err := strconv.Atoi(q.Get("limit"))
+		if err != nil || limit < 1 {
+			limit = 1
+		}
+		var list []object
+		for _, c := range nearestCities(lat, lon, limit) {
+			list = append(list, geocode(c))
+		}
+		return list, http.StatusOK
+	}
+
+	c, ok := locate(q)
+	if !ok {
+		if q.Get("lat") == "" && q.Get("id") == "" && q.Get("q") == "" && q.Get("zip") == "" {
+			return nil, http.StatusBadRequest
+		}
+		return nil, http.StatusNotFound
+	}
+
+	switch e {
+	case "/weather":
+		return current(c, now, u), http.StatusOK
+	case "/forecast":
+		return forecast(c, now, u, count(q, 40)), http.StatusOK
+	case "/forecast/daily":
+		return daily(c, now, u, count(q, 7)), http.StatusOK
+	case "/onecall":
+		return oneCall(c, now, u, strings.Split(q.Get("exclude"), ",")), http.StatusOK
+	case "/onecall/timemachine":
+		return timeMachine(c, unixParam(q, "dt", now), now, u), http.StatusOK
+	case "/onecall/day_summary":
+		return daySummary(c, q.Get("date"), q.Get("tz"), u), http.StatusOK
+	case "/onecall/overview":
+		return overview(c, q.Get("date"), q.Get("tz"), u), http.StatusOK
+	case "/air_pollution":
+		return airPollution(c, []time.Time{now.Truncate(time.Hour)}), http.StatusOK
+	case "/air_pollution/forecast":
+		return airPollution(c, hours(now, now.Add(96*time.Hour), 96)), http.StatusOK
+	case "/air_pollution/history":
+		return airPollution(c, hours(unixParam(q, "start", now.Add(-24*time.Hour)), unixParam(q, "end", now), 24*365)), http.StatusOK
+	case "/uvi":
+		return uvi(c, now.Truncate(24*time.Hour).Add(12*time.Hour)), http.StatusOK
+	case "/uvi/forecast", "/uvi/history":
+		start := now.Truncate(24 * time.Hour).Add(12 * time.Hour)
+		days := count(q, 8)
+		if e == "/uvi/history" {
+			start = unixParam(q, "start", start.AddDate(0, 0, -days))
+			days = int(unixParam(q, "end", now).Sub(start).Hours()/24) + 1
+		}
+		list := make([]object, 0, days)
+		for i := 0; i < days; i++ {
+			list = append(list, uvi(c, start.AddDate(0, 0, i)))
+		}
+		return list, http.StatusOK
+	case "/history/city":
+		end := unixParam(q, "end", now)
+		start := unixParam(q, "start", end.Add(-23*time.Hour))
+		return history(c, hours(start, end, count(q, 24)), now, u), http.StatusOK
+	}
+	return nil, http.StatusNotFound
+}
+
+// aggregations are the periods of the aggregated measurements.
+var aggregations = map[string]time.Duration{"m": time.Minute, "h": time.Hour, "d": 24 * time.Hour}
+
+// serveMeasurements accepts the measurements posted by stations and
+// answers the aggregated measurements of a station.
+func serveMeasurements(w http.ResponseWriter, r *http.Request, q url.Values, now time.Time) {
+	if r.Method == http.MethodPost {
+		var measurements []map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&measurements); err != nil || len(measurements) == 0 {
+			writeError(w, http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	station, typ := q.Get("station_id"), q.Get("type")
+	step, ok := aggregations[typ]
+	if station == "" || !ok {
+		writeError(w, http.StatusBadRequest)
+		return
+	}
+	limit, err := strconv.Atoi(q.Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+	to := unixParam(q, "to", now)
+	from := unixParam(q, "from", to.Add(-time.Duration(limit)*step)).Truncate(step)
+
+	list := []object{}
+	for t := from; t.Before(to) && len(list) < limit; t = t.Add(step) {
+		list = append(list, aggregated(station, typ, t))
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(list)
+}
+
+// iconPNG is the image served for every icon.
+var iconPNG = func() []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.RGBA{R: 255, G: 200, A: 255})
+	var b bytes.Buffer
+	png.Encode(&b, img)
+	return b.Bytes()
+}()
+
+// serveIcon serves the icons of the known conditions, e.g.
+// "/img/w/01d.png" or "/img/wn/10n@2x.png".
+func serveIcon(w http.ResponseWriter, path string) {
+	name := path[strings.LastIndex(path, "/")+1:]
+	code := strings.TrimSuffix(strings.Split(name, "@")[0], ".png")
+	known := false
+	for _, d := range weatherDescriptions {
+		if code == d[2]+"d" || code == d[2]+"n" {
+			known = true
+		}
+	}
+	for _, prefix := range []string{"09", "11", "13"} {
+		if code == prefix+"d" || code == prefix+"n" {
+			known = true
+		}
+	}
+	if !known || !strings.HasSuffix(name, ".png") {
+		http.NotFound(w, nil)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Content-Length", fmt.Sprint(len(iconPNG)))
+	w.Write(iconPNG)
+}
@@ -0,0 +1,275 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package owmtest
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// get requests the URL through the server's client and decodes the JSON
+// response into v when given.
+func get(t *testing.T, s *Server, u string, v interface{}) int {
+	t.Helper()
+
+	response, err := s.Client().Get(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	if v != nil {
+		if err := json.NewDecoder(response.Body).Decode(v); err != nil {
+			t.Fatalf("%s: %v", u, err)
+		}
+	}
+	return response.StatusCode
+}
+
+// TestEndpoints will verify that every endpoint answers for a known city
+func TestEndpoints(t *testing.T) {
+	t.Parallel()
+
+	s := NewServer()
+	defer s.Close()
+
+	endpoints := []string{
+		"https://api.openweathermap.org/data/2.5/weather?q=Berlin&units=metric",
+		"https://api.openweathermap.org/data/2.5/weather?id=2950159",
+		"https://api.openweathermap.org/data/2.5/weather?zip=19125,US",
+		"https://api.openweathermap.org/data/2.5/weather?lat=52.5&lon=13.4",
+		"https://api.openweathermap.org/data/2.5/group?id=2950159,2643743",
+		"https://api.openweathermap.org/data/2.5/forecast?id=2950159&cnt=3",
+		"https://api.openweathermap.org/data/2.5/forecast/daily?id=2950159&cnt=3",
+		"https://api.openweathermap.org/data/3.0/onecall?lat=33.45&lon=-112.07",
+		"https://api.openweathermap.org/data/3.0/onecall/timemachine?lat=33.45&lon=-112.07&dt=1715000000",
+		"https://api.openweathermap.org/data/3.0/onecall/day_summary?lat=33.45&lon=-112.07&date=2024-05-01",
+		"https://api.openweathermap.org/data/3.0/onecall/overview?lat=33.45&lon=-112.07",
+		"https://api.openweathermap.org/data/2.5/air_pollution?lat=0&lon=10",
+		"https://api.openweathermap.org/data/2.5/air_pollution/forecast?lat=0&lon=10",
+		"https://api.openweathermap.org/data/2.5/air_pollution/history?lat=0&lon=10&start=1715000000&end=1715036000",
+		"https://api.openweathermap.org/data/2.5/uvi?lat=33.45&lon=-112.07",
+		"https://api.openweathermap.org/data/2.5/uvi/forecast?lat=33.45&lon=-112.07",
+		"https://api.openweathermap.org/data/2.5/history/city?q=Vancouver",
+		"https://api.openweathermap.org/geo/1.0/direct?q=Berlin&limit=5",
+		"https://api.openweathermap.org/geo/1.0/zip?zip=19125,US",
+		"https://api.openweathermap.org/geo/1.0/reverse?lat=52.5&lon=13.4&limit=3",
+		"https://api.openweathermap.org/data/3.0/measurements?station_id=abc&type=h&limit=3",
+	}
+	for _, u := range endpoints {
+		var v interface{}
+		if status := get(t, s, u, &v); status != http.StatusOK {
+			t.Errorf("%s: expected 200, got %d", u, status)
+		}
+	}
+	if n := s.Count("/weather"); n != 4 {
+		t.Errorf("expected 4 requests of the current weather, got %d", n)
+	}
+}
+
+// TestCurrent will verify the current weather of a city in each unit
+func TestCurrent(t *testing.T) {
+	t.Parallel()
+
+	s := NewServer()
+	defer s.Close()
+
+	tests := []struct {
+		units string
+		temp  float64
+	}{
+		{"metric", 2},
+		{"imperial", 35.6},
+		{"", 275.15},
+	}
+	for _, tt := range tests {
+		var w struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+			Dt   int64  `json:"dt"`
+			Main struct {
+				Temp float64 `json:"temp"`
+			} `json:"main"`
+		}
+		get(t, s, "http://api.openweathermap.org/data/2.5/weather?q=philadelphia&units="+tt.units, &w)
+		if w.ID != 4560349 || w.Name != "Philadelphia" || w.Main.Temp != tt.temp || w.Dt != DefaultTime.Unix() {
+			t.Errorf("%q: unexpected weather %+v", tt.units, w)
+		}
+	}
+
+	at := time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)
+	s.SetTime(at)
+	var w struct {
+		Dt int64 `json:"dt"`
+	}
+	get(t, s, "http://api.openweathermap.org/data/2.5/weather?id=4560349", &w)
+	if w.Dt != at.Unix() {
+		t.Errorf("expected dt %d, got %d", at.Unix(), w.Dt)
+	}
+}
+
+// TestOneCallExclude will verify that excluded parts are left out
+func TestOneCallExclude(t *testing.T) {
+	t.Parallel()
+
+	s := NewServer()
+	defer s.Close()
+
+	var all map[string]json.RawMessage
+	get(t, s, "http://api.openweathermap.org/data/3.0/onecall?lat=33.45&lon=-112.07", &all)
+	for _, part := range []string{"current", "minutely", "hourly", "daily", "alerts"} {
+		if _, ok := all[part]; !ok {
+			t.Errorf("expected %s", part)
+		}
+	}
+
+	var some map[string]json.RawMessage
+	get(t, s, "http://api.openweathermap.org/data/3.0/onecall?lat=33.45&lon=-112.07&exclude=alerts,daily", &some)
+	if _, ok := some["alerts"]; ok {
+		t.Error("expected alerts to be excluded")
+	}
+	if _, ok := some["daily"]; ok {
+		t.Error("expected daily to be excluded")
+	}
+}
+
+// TestFaults will verify the injected faults and that they wear off
+func TestFaults(t *testing.T) {
+	t.Parallel()
+
+	s := NewServer()
+	defer s.Close()
+	const u = "http://api.openweathermap.org/data/2.5/weather?id=2950159"
+
+	tests := []struct {
+		fault  Fault
+		status int
+	}{
+		{Unauthorized(), http.StatusUnauthorized},
+		{NotFound(), http.StatusNotFound},
+		{RateLimited(), http.StatusTooManyRequests},
+		{ServerError(http.StatusBadGateway), http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		f := tt.fault
+		f.Times = 1
+		s.Inject("/weather", f)
+
+		var body struct {
+			Cod     string `json:"cod"`
+			Message string `json:"message"`
+		}
+		if status := get(t, s, u, &body); status != tt.status || body.Message == "" {
+			t.Errorf("expected %d with a message, got %d %+v", tt.status, status, body)
+		}
+		if status := get(t, s, u, nil); status != http.StatusOK {
+			t.Errorf("expected the fault to wear off, got %d", status)
+		}
+	}
+
+	s.Inject("", Malformed())
+	response, err := s.Client().Get(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	if json.Valid(b) {
+		t.Errorf("expected malformed JSON, got %s", b)
+	}
+
+	s.Reset()
+	s.Inject("/weather", Slow(50*time.Millisecond))
+	start := time.Now()
+	get(t, s, u, nil)
+	if d := time.Since(start); d < 50*time.Millisecond {
+		t.Errorf("expected a slow response, took %v", d)
+	}
+	if n := len(s.Requests()); n != 1 {
+		t.Errorf("expected the requests to be reset, got %d", n)
+	}
+}
+
+// TestRequireKey will verify that requests without the key are rejected
+func TestRequireKey(t *testing.T) {
+	t.Parallel()
+
+	s := NewServer()
+	defer s.Close()
+	s.RequireKey("secret")
+
+	if status := get(t, s, "http://api.openweathermap.org/data/2.5/weather?id=2950159&appid=nope", nil); status != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", status)
+	}
+	if status := get(t, s, "http://api.openweathermap.org/data/2.5/weather?id=2950159&appid=secret", nil); status != http.StatusOK {
+		t.Errorf("expected 200, got %d", status)
+	}
+	if status := get(t, s, "http://api.openweathermap.org/data/2.5/weather?q=nowhere_&appid=secret", nil); status != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", status)
+	}
+}
+
+// TestNewClient will verify that the client sends every request to the
+// given server
+func TestNewClient(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.Host+r.URL.RequestURI())
+	}))
+	defer srv.Close()
+
+	response, err := NewClient(srv.URL).Get("https://api.openweathermap.org/data/2.5/weather?q=Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	b, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := strings.TrimPrefix(srv.URL, "http://") + "/data/2.5/weather?q=Berlin"; string(b) != want {
+		t.Errorf("expected %s, got %s", want, b)
+	}
+}
+
+// TestIcons will verify that the icons of known conditions are served
+func TestIcons(t *testing.T) {
+	t.Parallel()
+
+	s := NewServer()
+	defer s.Close()
+
+	for u, want := range map[string]int{
+		"https://openweathermap.org/img/w/01d.png":     http.StatusOK,
+		"https://openweathermap.org/img/wn/10n@2x.png": http.StatusOK,
+		"https://openweathermap.org/img/w/n7m.png":     http.StatusNotFound,
+	} {
+		response, err := s.Client().Get(u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+		if response.StatusCode != want {
+			t.Errorf("%s: expected %d, got %d", u, want, response.StatusCode)
+		}
+	}
+}
@@ -3,7 +3,6 @@ package openweathermap
 import (
 	"encoding/json"
 	"fmt"
-	"net/http"
 	"strconv"
 )
 
@@ -33,7 +32,7 @@ type Pollution struct {
 	Dt       string          `json:"dt"`
 	Location Coordinates     `json:"coord"`
 	List     []PollutionData `json:"list"`
-	Key      string
+	Key      string          `json:"-"`
 	*Settings
 }
 
@@ -88,8 +87,8 @@ func (p *Pollution) PollutionByParams(params *PollutionParameters) error {
 	}
 	defer response.Body.Close()
 
-	if response.StatusCode == http.StatusUnauthorized {
-		return errInvalidKey
+	if err := checkResponse(response); err != nil {
+		return err
 	}
 
 	if err = json.NewDecoder(response.Body).Decode(&p); err != nil {
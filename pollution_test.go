@@ -2,7 +2,6 @@ package openweathermap
 
 import (
 	"net/http"
-	"os"
 	"reflect"
 	"testing"
 	"time"
@@ -11,7 +10,7 @@ import (
 // TestNewPollution
 func TestNewPollution(t *testing.T) {
 
-	p, err := NewPollution(os.Getenv("OWM_API_KEY"))
+	p, err := NewPollution("key")
 	if err != nil {
 		t.Error(err)
 	}
@@ -26,7 +25,7 @@ func TestNewPollutionWithCustomHttpClient(t *testing.T) {
 
 	hc := http.DefaultClient
 	hc.Timeout = time.Duration(1) * time.Second
-	p, err := NewPollution(os.Getenv("OWM_API_KEY"), WithHttpClient(hc))
+	p, err := NewPollution("key", WithHttpClient(hc))
 	if err != nil {
 		t.Error(err)
 	}
@@ -53,7 +52,7 @@ func TestNewPollutionWithInvalidOptions(t *testing.T) {
 	}
 
 	for _, options := range optionsPattern {
-		c, err := NewPollution(os.Getenv("OWM_API_KEY"), options...)
+		c, err := NewPollution("key", options...)
 		if err == errInvalidOption {
 			t.Logf("Received expected invalid option error. message: %s", err.Error())
 		} else if err != nil {
@@ -69,7 +68,7 @@ func TestNewPollutionWithInvalidOptions(t *testing.T) {
 // invalid http client
 func TestNewPollutionWithInvalidHttpClient(t *testing.T) {
 
-	p, err := NewPollution(os.Getenv("OWM_API_KEY"), WithHttpClient(nil))
+	p, err := NewPollution("key", WithHttpClient(nil))
 	if err == errInvalidHttpClient {
 		t.Logf("Received expected bad client error. message: %s", err.Error())
 	} else if err != nil {
@@ -93,9 +92,13 @@ func TestValidAlias(t *testing.T) {
 // TestPollutionByParams tests the call to the pollution API
 func TestPollutionByParams(t *testing.T) {
 	t.Parallel()
-	p, err := NewPollution(os.Getenv("OWM_API_KEY"))
+
+	srv, opt := newFakeServer()
+	defer srv.Close()
+
+	p, err := NewPollution("key", opt)
 	if err != nil {
-		t.Error(err)
+		t.Fatal(err)
 	}
 	params := &PollutionParameters{
 		Location: Coordinates{
@@ -107,4 +110,7 @@ func TestPollutionByParams(t *testing.T) {
 	if err := p.PollutionByParams(params); err != nil {
 		t.Error(err)
 	}
+	if len(p.List) != 1 {
+		t.Errorf("expected the current pollution, got %d entries", len(p.List))
+	}
 }
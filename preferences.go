@@ -0,0 +1,209 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"github.com/briandowns/openweathermap/units"
+)
+
+// UnitPreferences holds the unit wanted for each quantity, e.g. Celsius
+// with wind in knots, pressure in inHg and visibility in miles.
+type UnitPreferences = units.System
+
+// WithUnitPreferences makes the client request metric data and convert
+// every result into the given units. The units applied are recorded in
+// the AppliedUnits field of the results and the unit given to the
+// constructor is only used when no preferences are set.
+func WithUnitPreferences(p UnitPreferences) Option {
+	return func(s *Settings) error {
+		if err := p.Validate(); err != nil {
+			return err
+		}
+		s.unitPreferences = &p
+		return nil
+	}
+}
+
+// requestUnit returns the units parameter to send to the API. Data is
+// always requested in metric when preferences are set.
+func (s *Settings) requestUnit(unit string) string {
+	if s.unitPreferences != nil {
+		return "metric"
+	}
+	return unit
+}
+
+// unitNormalizer is implemented by the results whose values can be
+// converted into the preferred units.
+type unitNormalizer interface {
+	normalizeUnits(c unitConverter)
+}
+
+// applyUnitPreferences converts the result from metric into the
+// preferred units. Nothing is done when no preferences are set.
+func (s *Settings) applyUnitPreferences(n unitNormalizer) {
+	if s.unitPreferences == nil {
+		return
+	}
+	n.normalizeUnits(unitConverter{from: units.Metric, to: *s.unitPreferences})
+}
+
+// unitConverter converts values in place between two unit systems.
+type unitConverter struct {
+	from, to units.System
+}
+
+func (c unitConverter) temperature(vs ...*float64) {
+	for _, v := range vs {
+		*v = units.Temperature{Value: *v, Unit: c.from.Temperature}.In(c.to.Temperature).Value
+	}
+}
+
+func (c unitConverter) speed(vs ...*float64) {
+	for _, v := range vs {
+		*v = units.Speed{Value: *v, Unit: c.from.Speed}.In(c.to.Speed).Value
+	}
+}
+
+func (c unitConverter) pressure(vs ...*float64) {
+	for _, v := range vs {
+		*v = units.Pressure{Value: *v, Unit: c.from.Pressure}.In(c.to.Pressure).Value
+	}
+}
+
+func (c unitConverter) distance(vs ...*float64) {
+	for _, v := range vs {
+		*v = units.Distance{Value: *v, Unit: c.from.Distance}.In(c.to.Distance).Value
+	}
+}
+
+func (c unitConverter) precipitation(vs ...*float64) {
+	for _, v := range vs {
+		*v = units.Precipitation{Value: *v, Unit: c.from.Precipitation}.In(c.to.Precipitation).Value
+	}
+}
+
+func (m *Main) normalizeUnits(c unitConverter) {
+	c.temperature(&m.Temp, &m.TempMin, &m.TempMax, &m.FeelsLike)
+	c.pressure(&m.Pressure, &m.SeaLevel, &m.GrndLevel)
+}
+
+func (w *Wind) normalizeUnits(c unitConverter) {
+	c.speed(&w.Speed, &w.Gust)
+}
+
+func (r *Rain) normalizeUnits(c unitConverter) {
+	c.precipitation(&r.OneH, &r.ThreeH)
+}
+
+func (s *Snow) normalizeUnits(c unitConverter) {
+	c.precipitation(&s.OneH, &s.ThreeH)
+}
+
+func (t *Temperature) normalizeUnits(c unitConverter) {
+	c.temperature(&t.Day, &t.Min, &t.Max, &t.Night, &t.Eve, &t.Morn)
+}
+
+func (w *CurrentWeatherData) normalizeUnits(c unitConverter) {
+	w.Main.normalizeUnits(c)
+	w.Wind.normalizeUnits(c)
+	w.Rain.normalizeUnits(c)
+	w.Snow.normalizeUnits(c)
+	c.distance(&w.Visibility)
+	w.AppliedUnits = &c.to
+}
+
+func (f *Forecast5WeatherData) normalizeUnits(c unitConverter) {
+	for i := range f.List {
+		l := &f.List[i]
+		l.Main.normalizeUnits(c)
+		l.Wind.normalizeUnits(c)
+		l.Rain.normalizeUnits(c)
+		l.Snow.normalizeUnits(c)
+		c.distance(&l.Visibility)
+	}
+	f.AppliedUnits = &c.to
+}
+
+func (f *Forecast16WeatherData) normalizeUnits(c unitConverter) {
+	for i := range f.List {
+		l := &f.List[i]
+		l.Temp.normalizeUnits(c)
+		c.pressure(&l.Pressure)
+		c.speed(&l.Speed, &l.Gust)
+		c.precipitation(&l.Rain, &l.Snow)
+	}
+	f.AppliedUnits = &c.to
+}
+
+func (h *HistoricalWeatherData) normalizeUnits(c unitConverter) {
+	for i := range h.List {
+		l := &h.List[i]
+		l.Main.normalizeUnits(c)
+		l.Wind.normalizeUnits(c)
+		l.Rain.normalizeUnits(c)
+	}
+	h.AppliedUnits = &c.to
+}
+
+func (w *OneCallData) normalizeUnits(c unitConverter) {
+	cur := &w.Current
+	c.temperature(&cur.Temp, &cur.FeelsLike, &cur.DewPoint)
+	c.pressure(&cur.Pressure)
+	c.distance(&cur.Visibility)
+	c.speed(&cur.WindSpeed, &cur.WindGust)
+	cur.Rain.normalizeUnits(c)
+	cur.Snow.normalizeUnits(c)
+
+	for i := range w.Minutely {
+		c.precipitation(&w.Minutely[i].Precipitation)
+	}
+	for i := range w.Hourly {
+		h := &w.Hourly[i]
+		c.temperature(&h.Temp, &h.FeelsLike, &h.DewPoint)
+		c.pressure(&h.Pressure)
+		c.distance(&h.Visibility)
+		c.speed(&h.WindSpeed, &h.WindGust)
+		h.Rain.normalizeUnits(c)
+		h.Snow.normalizeUnits(c)
+	}
+	for i := range w.Daily {
+		d := &w.Daily[i]
+		d.Temp.normalizeUnits(c)
+		c.temperature(&d.FeelsLike.Day, &d.FeelsLike.Night, &d.FeelsLike.Eve, &d.FeelsLike.Morn, &d.DewPoint)
+		c.pressure(&d.Pressure)
+		c.speed(&d.WindSpeed, &d.WindGust)
+		c.precipitation(&d.Rain, &d.Snow)
+	}
+	for i := range w.Data {
+		d := &w.Data[i]
+		c.temperature(&d.Temp, &d.FeelsLike, &d.DewPoint)
+		c.pressure(&d.Pressure)
+		c.distance(&d.Visibility)
+		c.speed(&d.WindSpeed, &d.WindGust)
+		d.Rain.normalizeUnits(c)
+		d.Snow.normalizeUnits(c)
+	}
+	w.AppliedUnits = &c.to
+}
+
+func (s *OneCallDaySummary) normalizeUnits(c unitConverter) {
+	t := &s.Temperature
+	c.temperature(&t.Min, &t.Max, &t.Afternoon, &t.Night, &t.Evening, &t.Morning)
+	c.pressure(&s.Pressure.Afternoon)
+	c.speed(&s.Wind.Max.Speed)
+	c.precipitation(&s.Precipitation.Total)
+	s.AppliedUnits = &c.to
+}
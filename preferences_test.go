@@ -0,0 +1,319 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/briandowns/openweathermap/units"
+)
+
+// aviation is the set of preferences used by the tests.
+var aviation = UnitPreferences{
+	Temperature:   units.Celsius,
+	Speed:         units.Knots,
+	Pressure:      units.InchesOfMercury,
+	Distance:      units.Miles,
+	Precipitation: units.Inches,
+}
+
+// approx reports whether the values are within a small tolerance.
+func approx(a, b float64) bool { return math.Abs(a-b) < 1e-3 }
+
+// unitsHandler serves the body after checking metric data was requested.
+func unitsHandler(t *testing.T, body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if u := r.URL.Query().Get("units"); u != "metric" {
+			t.Errorf("expected metric units to be requested, got %q", u)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, body)
+	})
+}
+
+// TestWithUnitPreferences will verify that invalid preferences are
+// rejected
+func TestWithUnitPreferences(t *testing.T) {
+	t.Parallel()
+
+	p := aviation
+	p.Speed = "furlongs/fortnight"
+	if _, err := NewCurrent("F", "EN", "", WithUnitPreferences(p)); err == nil {
+		t.Error("expected error for invalid speed unit")
+	}
+
+	s := NewSettings()
+	if u := s.requestUnit("imperial"); u != "imperial" {
+		t.Errorf("expected the unit to be kept without preferences, got %q", u)
+	}
+}
+
+// TestCurrentUnitPreferences will verify that current weather is converted
+// into the preferred units and that they are recorded
+func TestCurrentUnitPreferences(t *testing.T) {
+	t.Parallel()
+
+	srv, opt := newTestServer(t, unitsHandler(t, `{
+		"main": {"temp": 20, "feels_like": 18, "pressure": 1013.25},
+		"wind": {"speed": 10, "gust": 15},
+		"visibility": 1609.344,
+		"rain": {"1h": 25.4}
+	}`))
+	defer srv.Close()
+
+	w, err := NewCurrent("F", "EN", "", opt, WithUnitPreferences(aviation))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.CurrentByName("Denver"); err != nil {
+		t.Fatal(err)
+	}
+
+	if w.Main.Temp != 20 {
+		t.Errorf("expected 20°C, got %v", w.Main.Temp)
+	}
+	if !approx(w.Main.Pressure, 29.921) {
+		t.Errorf("expected 29.921 inHg, got %v", w.Main.Pressure)
+	}
+	if !approx(w.Wind.Speed, 19.438) || !approx(w.Wind.Gust, 29.158) {
+		t.Errorf("unexpected wind in knots %+v", w.Wind)
+	}
+	if !approx(w.Visibility, 1) || !approx(w.Rain.OneH, 1) {
+		t.Errorf("unexpected visibility %v or rain %v", w.Visibility, w.Rain.OneH)
+	}
+
+	if w.AppliedUnits == nil || *w.AppliedUnits != aviation {
+		t.Fatalf("expected applied units to be recorded, got %v", w.AppliedUnits)
+	}
+	q := w.Quantities()
+	if q.WindSpeed.Unit != units.Knots || q.Pressure.Unit != units.InchesOfMercury {
+		t.Errorf("unexpected quantity units %v, %v", q.WindSpeed, q.Pressure)
+	}
+}
+
+// TestOneCallUnitPreferences will verify that every part of a one call
+// result is converted
+func TestOneCallUnitPreferences(t *testing.T) {
+	t.Parallel()
+
+	srv, opt := newTestServer(t, unitsHandler(t, `{
+		"current": {"temp": 10, "pressure": 1000, "visibility": 3218.688, "wind_speed": 5},
+		"minutely": [{"dt": 1, "precipitation": 2.54}],
+		"hourly": [{"temp": 11, "wind_speed": 1852, "rain": {"1h": 50.8}}],
+		"daily": [{"temp": {"day": 12, "min": 8, "max": 14}, "feels_like": {"day": 11}, "wind_gust": 10}]
+	}`))
+	defer srv.Close()
+
+	w, err := NewOneCall("K", "EN", "", nil, opt, WithUnitPreferences(aviation))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.OneCallByCoordinates(&Coordinates{Latitude: 39.7, Longitude: -104.9}); err != nil {
+		t.Fatal(err)
+	}
+
+	if w.Current.Temp != 10 || !approx(w.Current.Visibility, 2) || !approx(w.Current.Pressure, 29.530) {
+		t.Errorf("unexpected current %+v", w.Current)
+	}
+	if !approx(w.Minutely[0].Precipitation, 0.1) {
+		t.Errorf("expected 0.1 in, got %v", w.Minutely[0].Precipitation)
+	}
+	if !approx(w.Hourly[0].WindSpeed, 3600) || !approx(w.Hourly[0].Rain.OneH, 2) {
+		t.Errorf("unexpected hourly %+v", w.Hourly[0])
+	}
+	if w.Daily[0].Temp.Max != 14 || !approx(w.Daily[0].WindGust, 19.438) {
+		t.Errorf("unexpected daily %+v", w.Daily[0])
+	}
+	if w.Units() != aviation {
+		t.Errorf("expected aviation units, got %+v", w.Units())
+	}
+}
+
+// TestHistoryUnitPreferences will verify that historical data is converted
+func TestHistoryUnitPreferences(t *testing.T) {
+	t.Parallel()
+
+	srv, opt := newTestServer(t, unitsHandler(t, `{
+		"list": [{"main": {"temp": 0, "pressure": 1013.25}, "wind": {"speed": 0.514444}}]
+	}`))
+	defer srv.Close()
+
+	p := aviation
+	p.Temperature = units.Fahrenheit
+	h, err := NewHistorical("C", "", opt, WithUnitPreferences(p))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.HistoryByName("Denver"); err != nil {
+		t.Fatal(err)
+	}
+
+	l := h.List[0]
+	if !approx(l.Main.Temp, 32) || !approx(l.Wind.Speed, 1) {
+		t.Errorf("unexpected history %+v", l)
+	}
+	if h.AppliedUnits == nil || h.AppliedUnits.Temperature != units.Fahrenheit {
+		t.Errorf("expected applied units to be recorded, got %v", h.AppliedUnits)
+	}
+}
+
+// TestForecastUnitPreferences will verify that both forecast types are
+// converted
+func TestForecastUnitPreferences(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		typ  string
+		body string
+	}{
+		{"5", `{"list": [{"main": {"temp": 5}, "wind": {"speed": 1852}, "visibility": 1852}]}`},
+		{"16", `{"list": [{"temp": {"day": 5}, "speed": 1852, "rain": 254}]}`},
+	} {
+		srv, opt := newTestServer(t, unitsHandler(t, tt.body))
+
+		f, err := NewForecast(tt.typ, "F", "EN", "", opt, WithUnitPreferences(aviation))
+		if err != nil {
+			srv.Close()
+			t.Fatal(err)
+		}
+		err = f.DailyByName("Denver", 1)
+		srv.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		switch d := f.ForecastWeatherJson.(type) {
+		case *Forecast5WeatherData:
+			l := d.List[0]
+			if l.Main.Temp != 5 || !approx(l.Wind.Speed, 3600) || !approx(l.Visibility, 1.15078) {
+				t.Errorf("unexpected forecast %+v", l)
+			}
+			if d.AppliedUnits == nil {
+				t.Error("expected applied units to be recorded")
+			}
+		case *Forecast16WeatherData:
+			l := d.List[0]
+			if l.Temp.Day != 5 || !approx(l.Speed, 3600) || !approx(l.Rain, 10) {
+				t.Errorf("unexpected forecast %+v", l)
+			}
+			if d.AppliedUnits == nil {
+				t.Error("expected applied units to be recorded")
+			}
+		}
+	}
+}
+
+// TestGroupUnitPreferences will verify that every city of a group is
+// converted
+func TestGroupUnitPreferences(t *testing.T) {
+	t.Parallel()
+
+	srv, opt := newTestServer(t, unitsHandler(t, `{
+		"cnt": 2,
+		"list": [{"main": {"temp": 1}, "wind": {"speed": 1852}}, {"main": {"temp": 2}, "wind": {"speed": 3704}}]
+	}`))
+	defer srv.Close()
+
+	g, err := NewCurrentGroup("F", "EN", "", opt, WithUnitPreferences(aviation))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.CurrentByIDs(1, 2); err != nil {
+		t.Fatal(err)
+	}
+	for i, w := range g.List {
+		if !approx(w.Wind.Speed, float64(3600*(i+1))) || w.AppliedUnits == nil {
+			t.Errorf("unexpected city %d: %+v", i, w)
+		}
+	}
+}
+
+// TestTimeMachineUnitPreferences will verify that time machine data is converted
+func TestTimeMachineUnitPreferences(t *testing.T) {
+	t.Parallel()
+
+	srv, opt := newTestServer(t, unitsHandler(t, `{"data": [{"temp": 3, "visibility": 1609.344}]}`))
+	defer srv.Close()
+
+	w, err := NewOneCall("C", "EN", "", nil, opt, WithUnitPreferences(aviation))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.OneCallTimeMachine(&Coordinates{}, time.Unix(1600000000, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if w.Data[0].Temp != 3 || !approx(w.Data[0].Visibility, 1) {
+		t.Errorf("unexpected data %+v", w.Data[0])
+	}
+	if q := w.DataQuantities()[0]; q.Visibility.Unit != units.Miles || !approx(q.Visibility.Value, 1) {
+		t.Errorf("unexpected data quantities %v", q.Visibility)
+	}
+}
+
+// TestUnitPreferencesRepeatedCalls will verify that a value missing from
+// a later response isn't kept and converted again
+func TestUnitPreferencesRepeatedCalls(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	srv, opt := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if atomic.AddInt32(&calls, 1) == 1 {
+			fmt.Fprint(w, `{"main": {"temp": 10, "sea_level": 1013}, "wind": {"speed": 1, "gust": 20}, "rain": {"1h": 2},
+				"current": {"temp": 10, "wind_gust": 20}, "hourly": [{"temp": 11}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"main": {"temp": 10}, "wind": {"speed": 1}, "current": {"temp": 10}}`)
+	}))
+	defer srv.Close()
+
+	w, err := NewCurrent("C", "EN", "", opt, WithUnitPreferences(units.Imperial))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := w.CurrentByName("Denver"); err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 && !approx(w.Wind.Gust, 44.739) {
+			t.Errorf("expected the gust in mph, got %v", w.Wind.Gust)
+		}
+	}
+	if w.Wind.Gust != 0 || w.Rain.OneH != 0 || w.Main.SeaLevel != 0 || w.Key != "" || w.Settings == nil {
+		t.Errorf("expected only the last response, got %+v", w)
+	}
+	if !approx(w.Main.Temp, 50) || w.AppliedUnits == nil {
+		t.Errorf("expected the last response converted, got %+v", w.Main)
+	}
+
+	atomic.StoreInt32(&calls, 0)
+	o, err := NewOneCall("C", "EN", "", nil, opt, WithUnitPreferences(units.Imperial))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := o.OneCallByCoordinates(&Coordinates{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if o.Current.WindGust != 0 || len(o.Hourly) != 0 || !approx(o.Current.Temp, 50) {
+		t.Errorf("expected only the last response, got %+v", o)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// entry is a cached response.
+type entry struct {
+	key     string
+	res     *response
+	expires time.Time
+}
+
+// cache holds responses until they expire, evicting the least recently
+// used ones past max entries.
+type cache struct {
+	mu      sync.Mutex
+	max     int
+	order   *list.List // of *entry, most recently used first
+	entries map[string]*list.Element
+}
+
+// newCache returns a new cache pointer holding up to max entries.
+func newCache(max int) *cache {
+	return &cache{
+		max:     max,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// get returns the response of the key when it hasn't expired.
+func (c *cache) get(key string, now time.Time) (*response, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*entry)
+	if !now.Before(e.expires) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return e.res, true
+}
+
+// set caches the response of the key until it expires.
+func (c *cache) set(key string, res *response, expires time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		el.Value = &entry{key: key, res: res, expires: expires}
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&entry{key: key, res: res, expires: expires})
+	for c.order.Len() > c.max {
+		el := c.order.Back()
+		c.order.Remove(el)
+		delete(c.entries, el.Value.(*entry).key)
+	}
+}
+
+// len returns the number of entries, including the expired ones not
+// evicted yet.
+func (c *cache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// call is an upstream request in flight.
+type call struct {
+	done chan struct{}
+	res  *response
+	err  error
+}
+
+// group makes a single call per key at a time, the callers arriving
+// while it's in flight sharing its result.
+type group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// do calls fn unless a call of the key is in flight, and returns the
+// result and whether it was shared.
+func (g *group) do(key string, fn func() (*response, error)) (*response, error, bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		<-c.done
+		return c.res, c.err, true
+	}
+	c := &call{done: make(chan struct{})}
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(c.done)
+	}()
+	c.res, c.err = fn()
+	return c.res, c.err, false
+}
@@ -0,0 +1,102 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestCacheEviction will verify that entries expire and that the least
+// recently used are evicted past the max entries
+func TestCacheEviction(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	c := newCache(2)
+	c.set("a", &response{status: 200}, now.Add(time.Minute))
+	c.set("b", &response{status: 200}, now.Add(time.Second))
+	if _, ok := c.get("a", now); !ok {
+		t.Fatal("expected a")
+	}
+	c.set("c", &response{status: 200}, now.Add(time.Minute))
+
+	if _, ok := c.get("b", now); ok {
+		t.Error("expected b to be evicted")
+	}
+	if _, ok := c.get("a", now); !ok {
+		t.Error("expected a to be kept")
+	}
+	if _, ok := c.get("c", now.Add(time.Minute)); ok {
+		t.Error("expected c to expire")
+	}
+	if n := c.len(); n != 1 {
+		t.Errorf("expected 1 entry, got %d", n)
+	}
+}
+
+// TestGroup will verify that concurrent calls of a key share the result
+// of the first
+func TestGroup(t *testing.T) {
+	t.Parallel()
+
+	var g group
+	release := make(chan struct{})
+	started := make(chan struct{})
+	var calls int32
+	var once sync.Once
+	fn := func() (*response, error) {
+		atomic.AddInt32(&calls, 1)
+		once.Do(func() { close(started) })
+		<-release
+		return &response{status: 200}, errors.New("shared")
+	}
+
+	var wg sync.WaitGroup
+	results := make([]bool, 5)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, _, results[0] = g.do("k", fn)
+	}()
+	<-started
+	for i := 1; i < len(results); i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			res, err, shared := g.do("k", fn)
+			if res == nil || err == nil {
+				t.Error("expected the result of the first call")
+			}
+			results[i] = shared
+		}(i)
+	}
+	time.Sleep(50 * time.Millisecond) // for the others to wait on the first
+	close(release)
+	wg.Wait()
+
+	if calls := atomic.LoadInt32(&calls); calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+	if results[0] || !results[1] || !results[4] {
+		t.Errorf("unexpected shared results %v", results)
+	}
+	if _, _, shared := g.do("k", func() (*response, error) { return nil, nil }); shared {
+		t.Error("expected a new call once done")
+	}
+}
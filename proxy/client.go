@@ -0,0 +1,59 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"net/http"
+)
+
+// proxyTransport sends the requests of the library to a proxy, under
+// the path the proxy is mounted at.
+type proxyTransport struct {
+	scheme, host, path string
+	base               http.RoundTripper
+}
+
+func (t *proxyTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.URL.Scheme = t.scheme
+	r.URL.Host = t.host
+	r.URL.Path = t.path + r.URL.Path
+	r.URL.RawPath = ""
+	r.Host = t.host
+	return t.base.RoundTrip(r)
+}
+
+// NewClient returns an HTTP client sending the requests of the library
+// to the proxy at the URL, e.g. with owm.WithHttpClient. The path of the
+// URL is kept for proxies mounted under a prefix. The client token is
+// then used as the API key of the library.
+//
+//	c, err := proxy.NewClient("http://owm-proxy:8080")
+//	w, err := owm.NewCurrent("C", "EN", token, owm.WithHttpClient(c))
+func NewClient(proxyURL string) (*http.Client, error) {
+	u, err := parseUpstream(proxyURL)
+	if err != nil {
+		return nil, errInvalidProxyURL
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return nil, errInvalidProxyURL
+	}
+	return &http.Client{Transport: &proxyTransport{
+		scheme: u.Scheme,
+		host:   u.Host,
+		path:   u.Path,
+		base:   http.DefaultTransport,
+	}}, nil
+}
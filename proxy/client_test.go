@@ -0,0 +1,64 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	owm "github.com/briandowns/openweathermap"
+	"github.com/briandowns/openweathermap/owmtest"
+)
+
+// TestNewClient will verify that the client reaches a proxy mounted under
+// a prefix and that invalid URLs are rejected
+func TestNewClient(t *testing.T) {
+	t.Parallel()
+
+	srv := owmtest.NewServer()
+	defer srv.Close()
+	srv.RequireKey(testKey)
+
+	p, err := New(testKey, WithUpstream(srv.URL), WithTokens(map[string]string{"web": "tok-w"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/owm/", http.StripPrefix("/owm", p))
+	ps := httptest.NewServer(mux)
+	defer ps.Close()
+
+	c, err := NewClient(ps.URL + "/owm/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := owm.NewCurrent("C", "EN", "tok-w", owm.WithHttpClient(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.CurrentByName("Berlin"); err != nil {
+		t.Fatal(err)
+	}
+	if w.Name != "Berlin" {
+		t.Errorf("expected Berlin, got %s", w.Name)
+	}
+
+	for _, u := range []string{"owm-proxy:8080", "ftp://owm-proxy", "http://owm-proxy:8080/?a=b"} {
+		if _, err := NewClient(u); err != errInvalidProxyURL {
+			t.Errorf("%s: expected %v, got %v", u, errInvalidProxyURL, err)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Stats are the counters of a proxy since it started.
+type Stats struct {
+	Requests       map[string]int64 // by client name, "-" when the proxy is open
+	Unauthorized   int64            // requests without a valid token
+	CacheHits      int64
+	CacheMisses    int64
+	Coalesced      int64         // cache misses sharing the upstream request of another
+	RateLimited    int64         // requests answered with a 429 by the proxy
+	Upstream       map[int]int64 // upstream responses by status code
+	UpstreamErrors int64         // upstream requests without a response
+	CacheEntries   int
+}
+
+// metrics counts the requests of a proxy.
+type metrics struct {
+	mu             sync.Mutex
+	requests       map[string]int64
+	upstreams      map[int]int64
+	unauthorized   int64
+	hits           int64
+	misses         int64
+	coalesced      int64
+	rateLimited    int64
+	upstreamErrors int64
+}
+
+func (m *metrics) init() {
+	m.requests = make(map[string]int64)
+	m.upstreams = make(map[int]int64)
+}
+
+// add adds n to the counter.
+func (m *metrics) add(counter *int64, n int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	*counter += n
+}
+
+// request counts a request of the client.
+func (m *metrics) request(client string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[client]++
+}
+
+// upstream counts an upstream response.
+func (m *metrics) upstream(status int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.upstreams[status]++
+}
+
+// stats returns a copy of the counters.
+func (m *metrics) stats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := Stats{
+		Requests:       make(map[string]int64, len(m.requests)),
+		Unauthorized:   m.unauthorized,
+		CacheHits:      m.hits,
+		CacheMisses:    m.misses,
+		Coalesced:      m.coalesced,
+		RateLimited:    m.rateLimited,
+		Upstream:       make(map[int]int64, len(m.upstreams)),
+		UpstreamErrors: m.upstreamErrors,
+	}
+	for k, v := range m.requests {
+		s.Requests[k] = v
+	}
+	for k, v := range m.upstreams {
+		s.Upstream[k] = v
+	}
+	return s
+}
+
+// writeMetrics writes the stats in the Prometheus text format.
+func (p *Proxy) writeMetrics(w http.ResponseWriter) {
+	s := p.Stats()
+	var b strings.Builder
+	metric := func(name, typ, help string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+	}
+
+	metric("owm_proxy_requests_total", "counter", "Requests by client.")
+	clients := make([]string, 0, len(s.Requests))
+	for c := range s.Requests {
+		clients = append(clients, c)
+	}
+	sort.Strings(clients)
+	for _, c := range clients {
+		fmt.Fprintf(&b, "owm_proxy_requests_total{client=%s} %d\n", strconv.Quote(c), s.Requests[c])
+	}
+
+	for _, c := range []struct {
+		name, help string
+		value      int64
+	}{
+		{"owm_proxy_unauthorized_total", "Requests without a valid token.", s.Unauthorized},
+		{"owm_proxy_cache_hits_total", "Responses served from the cache.", s.CacheHits},
+		{"owm_proxy_cache_misses_total", "Cacheable requests not in the cache.", s.CacheMisses},
+		{"owm_proxy_coalesced_total", "Cache misses sharing the upstream request of another.", s.Coalesced},
+		{"owm_proxy_rate_limited_total", "Requests rejected by the rate limiter.", s.RateLimited},
+		{"owm_proxy_upstream_errors_total", "Upstream requests without a response.", s.UpstreamErrors},
+	} {
+		metric(c.name, "counter", c.help)
+		fmt.Fprintf(&b, "%s %d\n", c.name, c.value)
+	}
+
+	metric("owm_proxy_upstream_responses_total", "counter", "Upstream responses by status code.")
+	codes := make([]int, 0, len(s.Upstream))
+	for c := range s.Upstream {
+		codes = append(codes, c)
+	}
+	sort.Ints(codes)
+	for _, c := range codes {
+		fmt.Fprintf(&b, "owm_proxy_upstream_responses_total{code=\"%d\"} %d\n", c, s.Upstream[c])
+	}
+
+	metric("owm_proxy_cache_entries", "gauge", "Responses in the cache.")
+	fmt.Fprintf(&b, "owm_proxy_cache_entries %d\n", s.CacheEntries)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write([]byte(b.String()))
+}
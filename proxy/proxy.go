@@ -0,0 +1,516 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package proxy is a caching HTTP proxy of the OpenWeatherMap API, so
+// several services can share one API key and its quota. It serves the
+// same paths as the API, e.g. /data/2.5/weather?q=Berlin, and:
+//
+//   - replaces the appid of the clients, or their bearer token, with the
+//     key after checking it against the client tokens
+//   - caches successful GET responses for a TTL per endpoint
+//   - makes a single upstream request for identical concurrent requests
+//   - paces the upstream requests with a shared rate limiter
+//   - counts requests per client, cache hits and upstream responses,
+//     served as Prometheus text on /metrics, which needs a client token
+//     like the API paths when tokens are set
+//
+// The library can be pointed at a proxy with NewClient.
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	owm "github.com/briandowns/openweathermap"
+)
+
+// Defaults of the proxy.
+const (
+	DefaultUpstream     = "https://api.openweathermap.org"
+	DefaultIconUpstream = "https://openweathermap.org"
+	DefaultTTL          = 10 * time.Minute
+	DefaultMaxWait      = 10 * time.Second
+	DefaultMaxEntries   = 10000
+
+	maxBody = 10 << 20 // of requests and responses
+)
+
+var (
+	errInvalidKey        = errors.New("invalid API key")
+	errInvalidUpstream   = errors.New("invalid upstream URL")
+	errInvalidToken      = errors.New("client names and tokens should be unique and not empty")
+	errInvalidTTL        = errors.New("invalid TTL")
+	errInvalidLimiter    = errors.New("invalid rate limiter")
+	errInvalidMaxWait    = errors.New("invalid max wait")
+	errInvalidMaxEntries = errors.New("invalid max entries")
+	errInvalidHttpClient = errors.New("invalid http client")
+	errRateLimited       = errors.New("rate limited")
+	errBodyTooLarge      = errors.New("body too large")
+	errNoTokens          = errors.New("no client tokens, set them or allow open access")
+	errInvalidProxyURL   = errors.New("invalid proxy URL")
+)
+
+// DefaultTTLs are how long responses are cached per endpoint, the path
+// without the API version. Endpoints without a TTL use the TTL of their
+// first segment, e.g. "forecast" for "forecast/daily", then DefaultTTL.
+// A TTL of 0 disables caching.
+var DefaultTTLs = map[string]time.Duration{
+	"weather":        10 * time.Minute,
+	"group":          10 * time.Minute,
+	"find":           10 * time.Minute,
+	"forecast":       30 * time.Minute,
+	"forecast/daily": time.Hour,
+	"onecall":        10 * time.Minute,
+	"air_pollution":  30 * time.Minute,
+	"uvi":            time.Hour,
+	"history":        24 * time.Hour,
+	"direct":         24 * time.Hour,
+	"zip":            24 * time.Hour,
+	"reverse":        24 * time.Hour,
+	"img":            24 * time.Hour,
+	"measurements":   0,
+	"stations":       0,
+}
+
+// apiPrefixes are the versioned paths of the API.
+var apiPrefixes = []string{"/data/2.5/", "/data/3.0/", "/geo/1.0/"}
+
+// Option sets an option of the proxy.
+type Option func(p *Proxy) error
+
+// Proxy is a caching proxy of the API. It's an http.Handler.
+type Proxy struct {
+	key          string
+	upstream     *url.URL
+	iconUpstream *url.URL
+	client       *http.Client
+	tokens       map[string]string // token by client name
+	open         bool              // serves any client without tokens
+	ttls         map[string]time.Duration
+	limiter      owm.RateLimiter
+	maxWait      time.Duration
+	maxEntries   int
+	now          func() time.Time
+
+	cache   *cache
+	group   group
+	metrics metrics
+}
+
+// New returns a new Proxy pointer using the API key. The clients need
+// one of the tokens given with WithTokens, or the proxy must be opened to
+// any client with WithOpenAccess.
+func New(key string, options ...Option) (*Proxy, error) {
+	if key == "" || owm.ValidAPIKey(key) != nil {
+		return nil, errInvalidKey
+	}
+	p := &Proxy{
+		key:        key,
+		client:     http.DefaultClient,
+		ttls:       make(map[string]time.Duration, len(DefaultTTLs)),
+		maxWait:    DefaultMaxWait,
+		maxEntries: DefaultMaxEntries,
+		now:        time.Now,
+	}
+	p.upstream, _ = url.Parse(DefaultUpstream)
+	p.iconUpstream, _ = url.Parse(DefaultIconUpstream)
+	for e, ttl := range DefaultTTLs {
+		p.ttls[e] = ttl
+	}
+	for _, option := range options {
+		if err := option(p); err != nil {
+			return nil, err
+		}
+	}
+	if len(p.tokens) == 0 && !p.open {
+		return nil, errNoTokens
+	}
+	p.cache = newCache(p.maxEntries)
+	p.metrics.init()
+	return p, nil
+}
+
+// Open reports whether the proxy serves any client, without a token.
+func (p *Proxy) Open() bool { return len(p.tokens) == 0 }
+
+// parseUpstream parses an upstream URL, without a trailing slash.
+func parseUpstream(s string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimRight(s, "/"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errInvalidUpstream
+	}
+	return u, nil
+}
+
+// WithUpstream sets the URL of the API and of the icons, e.g. a stand-in
+// of the API in tests. It's DefaultUpstream by default.
+func WithUpstream(u string) Option {
+	return func(p *Proxy) error {
+		up, err := parseUpstream(u)
+		if err != nil {
+			return err
+		}
+		p.upstream, p.iconUpstream = up, up
+		return nil
+	}
+}
+
+// WithIconUpstream sets the URL the icons are fetched from. It's
+// DefaultIconUpstream by default.
+func WithIconUpstream(u string) Option {
+	return func(p *Proxy) error {
+		up, err := parseUpstream(u)
+		if err != nil {
+			return err
+		}
+		p.iconUpstream = up
+		return nil
+	}
+}
+
+// WithHttpClient sets the HTTP client of the upstream requests.
+func WithHttpClient(c *http.Client) Option {
+	return func(p *Proxy) error {
+		if c == nil {
+			return errInvalidHttpClient
+		}
+		p.client = c
+		return nil
+	}
+}
+
+// WithTokens sets the API tokens of the clients, by client name. Clients
+// send them as the appid or as a bearer token.
+func WithTokens(tokens map[string]string) Option {
+	return func(p *Proxy) error {
+		seen := make(map[string]bool, len(tokens))
+		for name, token := range tokens {
+			if name == "" || token == "" || seen[token] {
+				return errInvalidToken
+			}
+			seen[token] = true
+		}
+		p.tokens = make(map[string]string, len(tokens))
+		for name, token := range tokens {
+			p.tokens[name] = token
+		}
+		return nil
+	}
+}
+
+// WithOpenAccess lets any client use the proxy, and so the API key,
+// without a token. It's ignored when tokens are set.
+func WithOpenAccess() Option {
+	return func(p *Proxy) error {
+		p.open = true
+		return nil
+	}
+}
+
+// WithTTL sets how long the responses of the endpoint are cached, e.g.
+// "weather", "forecast/daily" or "img". 0 disables caching.
+func WithTTL(endpoint string, ttl time.Duration) Option {
+	return func(p *Proxy) error {
+		if ttl < 0 {
+			return errInvalidTTL
+		}
+		p.ttls[strings.Trim(endpoint, "/")] = ttl
+		return nil
+	}
+}
+
+// WithRateLimiter sets the rate limiter shared by the upstream requests,
+// e.g. owm.NewTokenBucket(60, time.Minute). Requests aren't limited by
+// default. Cache hits don't count.
+func WithRateLimiter(l owm.RateLimiter) Option {
+	return func(p *Proxy) error {
+		if l == nil {
+			return errInvalidLimiter
+		}
+		p.limiter = l
+		return nil
+	}
+}
+
+// WithMaxWait sets how long a request waits for the rate limiter before
+// it's answered with a 429. It's DefaultMaxWait by default.
+func WithMaxWait(d time.Duration) Option {
+	return func(p *Proxy) error {
+		if d <= 0 {
+			return errInvalidMaxWait
+		}
+		p.maxWait = d
+		return nil
+	}
+}
+
+// WithMaxEntries sets the number of responses cached, the least recently
+// used being evicted first. It's DefaultMaxEntries by default.
+func WithMaxEntries(n int) Option {
+	return func(p *Proxy) error {
+		if n < 1 {
+			return errInvalidMaxEntries
+		}
+		p.maxEntries = n
+		return nil
+	}
+}
+
+// route returns the endpoint of the path, e.g. "forecast/daily", and the
+// upstream serving it, or nil when the path isn't one of the API.
+func (p *Proxy) route(path string) (string, *url.URL) {
+	if strings.HasPrefix(path, "/img/") {
+		return "img", p.iconUpstream
+	}
+	for _, prefix := range apiPrefixes {
+		if strings.HasPrefix(path, prefix) && len(path) > len(prefix) {
+			return strings.Trim(strings.TrimPrefix(path, prefix), "/"), p.upstream
+		}
+	}
+	return "", nil
+}
+
+// ttl returns how long the responses of the endpoint are cached.
+func (p *Proxy) ttl(endpoint string) time.Duration {
+	if ttl, ok := p.ttls[endpoint]; ok {
+		return ttl
+	}
+	if i := strings.Index(endpoint, "/"); i > 0 {
+		if ttl, ok := p.ttls[endpoint[:i]]; ok {
+			return ttl
+		}
+	}
+	return DefaultTTL
+}
+
+// authenticate returns the name of the client of the request, "-" when
+// the proxy is open. The token is a bearer token or the appid.
+func (p *Proxy) authenticate(r *http.Request) (string, bool) {
+	if p.Open() {
+		return "-", true
+	}
+	token := r.URL.Query().Get("appid")
+	if h := r.Header.Get("Authorization"); strings.HasPrefix(h, "Bearer ") {
+		token = strings.TrimPrefix(h, "Bearer ")
+	}
+	if token == "" {
+		return "", false
+	}
+	client := ""
+	for name, t := range p.tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			client = name
+		}
+	}
+	return client, client != ""
+}
+
+// response is an upstream response.
+type response struct {
+	status      int
+	contentType string
+	body        []byte
+}
+
+// ServeHTTP serves the paths of the API, /metrics and /healthz.
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/metrics":
+		if _, ok := p.authenticate(r); !ok {
+			p.metrics.add(&p.metrics.unauthorized, 1)
+			writeError(w, http.StatusUnauthorized, "Invalid API key. Please see the proxy's client tokens.")
+			return
+		}
+		p.writeMetrics(w)
+		return
+	case "/healthz":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		io.WriteString(w, "ok\n")
+		return
+	}
+
+	endpoint, upstream := p.route(r.URL.Path)
+	if upstream == nil {
+		writeError(w, http.StatusNotFound, "Internal error: path not found")
+		return
+	}
+	client, ok := p.authenticate(r)
+	if !ok {
+		p.metrics.add(&p.metrics.unauthorized, 1)
+		writeError(w, http.StatusUnauthorized, "Invalid API key. Please see the proxy's client tokens.")
+		return
+	}
+	p.metrics.request(client)
+
+	q := r.URL.Query()
+	q.Del("appid")
+	target := *upstream
+	target.Path = r.URL.Path
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodPost:
+		body, err := readBody(r.Body)
+		if errors.Is(err, errBodyTooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, "Request body too large")
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		target.RawQuery = p.withKey(q)
+		res, err := p.fetch(http.MethodPost, &target, body, r.Header.Get("Content-Type"))
+		p.write(w, r, res, err, "")
+		return
+	default:
+		w.Header().Set("Allow", "GET, HEAD, POST")
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	target.RawQuery = p.withKey(q)
+	ttl := p.ttl(endpoint)
+	if ttl == 0 {
+		res, err := p.fetch(http.MethodGet, &target, nil, "")
+		p.write(w, r, res, err, "")
+		return
+	}
+
+	key := r.URL.Path + "?" + q.Encode()
+	if res, ok := p.cache.get(key, p.now()); ok {
+		p.metrics.add(&p.metrics.hits, 1)
+		p.write(w, r, res, nil, "HIT")
+		return
+	}
+	p.metrics.add(&p.metrics.misses, 1)
+	res, err, shared := p.group.do(key, func() (*response, error) {
+		res, err := p.fetch(http.MethodGet, &target, nil, "")
+		if err == nil && res.status == http.StatusOK {
+			p.cache.set(key, res, p.now().Add(ttl))
+		}
+		return res, err
+	})
+	if shared {
+		p.metrics.add(&p.metrics.coalesced, 1)
+	}
+	p.write(w, r, res, err, "MISS")
+}
+
+// withKey returns the query with the API key.
+func (p *Proxy) withKey(q url.Values) string {
+	q.Set("appid", p.key)
+	s := q.Encode()
+	q.Del("appid")
+	return s
+}
+
+// fetch makes an upstream request once the rate limiter allows it. It's
+// detached from the request of the client, whose response may be shared.
+func (p *Proxy) fetch(method string, u *url.URL, body []byte, contentType string) (*response, error) {
+	if p.limiter != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), p.maxWait)
+		err := p.limiter.Wait(ctx)
+		cancel()
+		if err != nil {
+			p.metrics.add(&p.metrics.rateLimited, 1)
+			return nil, errRateLimited
+		}
+	}
+
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		p.metrics.add(&p.metrics.upstreamErrors, 1)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := readBody(resp.Body)
+	if err != nil {
+		p.metrics.add(&p.metrics.upstreamErrors, 1)
+		return nil, err
+	}
+	p.metrics.upstream(resp.StatusCode)
+	return &response{status: resp.StatusCode, contentType: resp.Header.Get("Content-Type"), body: b}, nil
+}
+
+// readBody reads a request or response body, failing rather than
+// truncating bodies over maxBody.
+func readBody(r io.Reader) ([]byte, error) {
+	b, err := ioutil.ReadAll(io.LimitReader(r, maxBody+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > maxBody {
+		return nil, errBodyTooLarge
+	}
+	return b, nil
+}
+
+// write writes the response, or the error of the upstream request. The
+// errors aren't passed on as they may have the URL with the key.
+func (p *Proxy) write(w http.ResponseWriter, r *http.Request, res *response, err error, cache string) {
+	switch {
+	case errors.Is(err, errRateLimited):
+		w.Header().Set("Retry-After", strconv.Itoa(int(p.maxWait.Seconds()+0.5)))
+		writeError(w, http.StatusTooManyRequests, "Rate limit of the proxy exceeded")
+		return
+	case err != nil:
+		writeError(w, http.StatusBadGateway, "Upstream request failed")
+		return
+	}
+
+	if res.contentType != "" {
+		w.Header().Set("Content-Type", res.contentType)
+	}
+	if cache != "" {
+		w.Header().Set("X-Cache", cache)
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(res.body)))
+	w.WriteHeader(res.status)
+	if r.Method != http.MethodHead {
+		w.Write(res.body)
+	}
+}
+
+// writeError writes an error body like the ones of the API.
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"cod": status, "message": message})
+}
+
+// Stats returns the counters of the proxy.
+func (p *Proxy) Stats() Stats {
+	s := p.metrics.stats()
+	s.CacheEntries = p.cache.len()
+	return s
+}
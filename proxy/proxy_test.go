@@ -0,0 +1,428 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	owm "github.com/briandowns/openweathermap"
+	"github.com/briandowns/openweathermap/owmtest"
+)
+
+const testKey = "upstream-key"
+
+// newTestProxy starts a proxy of a fake API requiring the test key.
+func newTestProxy(t *testing.T, options ...Option) (*Proxy, *httptest.Server, *owmtest.Server) {
+	t.Helper()
+
+	srv := owmtest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.RequireKey(testKey)
+
+	p, err := New(testKey, append([]Option{WithUpstream(srv.URL)}, options...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps := httptest.NewServer(p)
+	t.Cleanup(ps.Close)
+	return p, ps, srv
+}
+
+// get makes a request to the proxy and returns the response and its body.
+func get(t *testing.T, method, url string, header http.Header) (*http.Response, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header != nil {
+		req.Header = header
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, string(b)
+}
+
+// TestNew will verify that invalid options are rejected
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		key     string
+		options []Option
+		want    error
+	}{
+		{"", nil, errInvalidKey},
+		{strings.Repeat("k", 65), nil, errInvalidKey},
+		{testKey, []Option{WithUpstream("api.openweathermap.org")}, errInvalidUpstream},
+		{testKey, []Option{WithIconUpstream("ftp://openweathermap.org")}, errInvalidUpstream},
+		{testKey, []Option{WithHttpClient(nil)}, errInvalidHttpClient},
+		{testKey, []Option{WithTokens(map[string]string{"a": "t", "b": "t"})}, errInvalidToken},
+		{testKey, []Option{WithTokens(map[string]string{"a": ""})}, errInvalidToken},
+		{testKey, []Option{WithTTL("weather", -time.Second)}, errInvalidTTL},
+		{testKey, []Option{WithRateLimiter(nil)}, errInvalidLimiter},
+		{testKey, []Option{WithMaxWait(0)}, errInvalidMaxWait},
+		{testKey, []Option{WithMaxEntries(0)}, errInvalidMaxEntries},
+		{testKey, nil, errNoTokens},
+		{testKey, []Option{WithTokens(map[string]string{})}, errNoTokens},
+	} {
+		if _, err := New(tc.key, tc.options...); err != tc.want {
+			t.Errorf("expected %v, got %v", tc.want, err)
+		}
+	}
+}
+
+// TestTTL will verify the TTLs of the endpoints
+func TestTTL(t *testing.T) {
+	t.Parallel()
+
+	p, err := New(testKey, WithOpenAccess(), WithTTL("/weather/", time.Minute), WithTTL("uvi", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		path string
+		want time.Duration
+	}{
+		{"/data/2.5/weather", time.Minute},
+		{"/data/2.5/forecast", 30 * time.Minute},
+		{"/data/2.5/forecast/daily", time.Hour},
+		{"/data/2.5/forecast/hourly", 30 * time.Minute},
+		{"/data/3.0/onecall/timemachine", 10 * time.Minute},
+		{"/data/2.5/uvi/forecast", 0},
+		{"/data/3.0/measurements", 0},
+		{"/geo/1.0/direct", 24 * time.Hour},
+		{"/img/wn/10d@2x.png", 24 * time.Hour},
+		{"/data/2.5/unknown", DefaultTTL},
+	} {
+		e, upstream := p.route(tc.path)
+		if upstream == nil {
+			t.Fatalf("%s: expected a route", tc.path)
+		}
+		if got := p.ttl(e); got != tc.want {
+			t.Errorf("%s: expected %s, got %s", tc.path, tc.want, got)
+		}
+	}
+	for _, path := range []string{"/", "/data/2.5/", "/weather", "/data/4.0/weather"} {
+		if _, upstream := p.route(path); upstream != nil {
+			t.Errorf("%s: expected no route", path)
+		}
+	}
+}
+
+// TestTokens will verify that the clients are authenticated by their
+// token and that the key is sent upstream instead
+func TestTokens(t *testing.T) {
+	t.Parallel()
+
+	p, ps, srv := newTestProxy(t, WithTokens(map[string]string{"billing": "tok-b", "web": "tok-w"}))
+	c, err := NewClient(ps.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := owm.NewCurrent("C", "EN", "tok-b", owm.WithHttpClient(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.CurrentByName("Berlin"); err != nil {
+		t.Fatal(err)
+	}
+	if w.Name != "Berlin" {
+		t.Errorf("expected Berlin, got %s", w.Name)
+	}
+
+	w, _ = owm.NewCurrent("C", "EN", "nope", owm.WithHttpClient(c))
+	if err := w.CurrentByName("Berlin"); !owm.IsInvalidKey(err) {
+		t.Errorf("expected an invalid key, got %v", err)
+	}
+
+	resp, _ := get(t, http.MethodGet, ps.URL+"/data/2.5/weather?q=Boston", http.Header{"Authorization": {"Bearer tok-w"}})
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200 with a bearer token, got %d", resp.StatusCode)
+	}
+	resp, _ = get(t, http.MethodGet, ps.URL+"/data/2.5/weather?q=Boston", nil)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 without a token, got %d", resp.StatusCode)
+	}
+
+	for _, r := range srv.Requests() {
+		if key := r.Query.Get("appid"); key != testKey {
+			t.Errorf("expected the key upstream, got %q", key)
+		}
+	}
+	s := p.Stats()
+	if s.Requests["billing"] != 1 || s.Requests["web"] != 1 || s.Unauthorized != 2 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
+
+// TestCache will verify that successful responses are cached until their
+// TTL, whoever asks for them
+func TestCache(t *testing.T) {
+	t.Parallel()
+
+	p, ps, srv := newTestProxy(t, WithOpenAccess(), WithTTL("weather", time.Minute))
+	var mu sync.Mutex
+	now := time.Now()
+	p.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+
+	resp, body := get(t, http.MethodGet, ps.URL+"/data/2.5/weather?q=Berlin&units=metric&appid=a", nil)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("X-Cache") != "MISS" {
+		t.Fatalf("expected a miss, got %d %q", resp.StatusCode, resp.Header.Get("X-Cache"))
+	}
+	resp, cached := get(t, http.MethodGet, ps.URL+"/data/2.5/weather?units=metric&q=Berlin&appid=b", nil)
+	if resp.Header.Get("X-Cache") != "HIT" || cached != body {
+		t.Errorf("expected the same response from the cache, got %q", resp.Header.Get("X-Cache"))
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("expected JSON, got %q", ct)
+	}
+	if resp, _ = get(t, http.MethodHead, ps.URL+"/data/2.5/weather?q=Berlin&units=metric", nil); resp.Header.Get("X-Cache") != "HIT" {
+		t.Errorf("expected a hit for HEAD, got %q", resp.Header.Get("X-Cache"))
+	}
+	if n := srv.Count("/weather"); n != 1 {
+		t.Errorf("expected 1 upstream request, got %d", n)
+	}
+
+	mu.Lock()
+	now = now.Add(time.Minute)
+	mu.Unlock()
+	get(t, http.MethodGet, ps.URL+"/data/2.5/weather?q=Berlin&units=metric", nil)
+	if n := srv.Count("/weather"); n != 2 {
+		t.Errorf("expected the entry to expire, got %d upstream requests", n)
+	}
+
+	srv.Inject("/forecast", owmtest.Fault{Status: http.StatusServiceUnavailable, Times: 1})
+	resp, _ = get(t, http.MethodGet, ps.URL+"/data/2.5/forecast?q=Berlin", nil)
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected the upstream 503, got %d", resp.StatusCode)
+	}
+	resp, _ = get(t, http.MethodGet, ps.URL+"/data/2.5/forecast?q=Berlin", nil)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("X-Cache") != "MISS" {
+		t.Errorf("expected errors not to be cached, got %d %q", resp.StatusCode, resp.Header.Get("X-Cache"))
+	}
+
+	s := p.Stats()
+	if s.CacheHits != 2 || s.CacheMisses != 4 || s.CacheEntries != 2 || s.Upstream[http.StatusServiceUnavailable] != 1 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
+
+// TestCoalescing will verify that identical concurrent requests share an
+// upstream request
+func TestCoalescing(t *testing.T) {
+	t.Parallel()
+
+	p, ps, srv := newTestProxy(t, WithOpenAccess())
+	srv.Inject("/weather", owmtest.Slow(200*time.Millisecond))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, _ := get(t, http.MethodGet, ps.URL+"/data/2.5/weather?q=Berlin", nil)
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("expected 200, got %d", resp.StatusCode)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := srv.Count("/weather"); n != 1 {
+		t.Errorf("expected 1 upstream request, got %d", n)
+	}
+	if s := p.Stats(); s.Coalesced+s.CacheHits != 9 || s.Coalesced == 0 {
+		t.Errorf("expected the requests to be coalesced, got %+v", s)
+	}
+}
+
+// TestRateLimit will verify that upstream requests are limited while
+// cache hits aren't
+func TestRateLimit(t *testing.T) {
+	t.Parallel()
+
+	p, ps, srv := newTestProxy(t, WithOpenAccess(), WithRateLimiter(owm.NewTokenBucket(1, time.Hour)), WithMaxWait(10*time.Millisecond))
+
+	if resp, _ := get(t, http.MethodGet, ps.URL+"/data/2.5/weather?q=Berlin", nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if resp, _ := get(t, http.MethodGet, ps.URL+"/data/2.5/weather?q=Berlin", nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("expected a cache hit, got %d", resp.StatusCode)
+	}
+	resp, body := get(t, http.MethodGet, ps.URL+"/data/2.5/weather?q=Boston", nil)
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") == "" || !strings.Contains(body, `"cod":429`) {
+		t.Errorf("expected a 429, got %d %s", resp.StatusCode, body)
+	}
+
+	if n := srv.Count("/weather"); n != 1 {
+		t.Errorf("expected 1 upstream request, got %d", n)
+	}
+	if s := p.Stats(); s.RateLimited != 1 {
+		t.Errorf("expected 1 rate limited request, got %d", s.RateLimited)
+	}
+}
+
+// TestPassThrough will verify the icons, the requests that aren't cached
+// and the errors of the proxy
+func TestPassThrough(t *testing.T) {
+	t.Parallel()
+
+	p, ps, srv := newTestProxy(t, WithOpenAccess())
+
+	resp, body := get(t, http.MethodGet, ps.URL+"/img/wn/10d@2x.png", nil)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "image/png" || !strings.HasPrefix(body, "\x89PNG") {
+		t.Errorf("expected the icon, got %d %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	resp, err := http.Post(ps.URL+"/data/3.0/measurements", "application/json",
+		strings.NewReader(`[{"station_id":"s1","dt":1715601600,"temperature":18.5}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", resp.StatusCode)
+	}
+	for i := 0; i < 2; i++ {
+		resp, _ = get(t, http.MethodGet, ps.URL+"/data/3.0/measurements?station_id=s1&type=h", nil)
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("X-Cache") != "" {
+			t.Errorf("expected measurements not to be cached, got %d %q", resp.StatusCode, resp.Header.Get("X-Cache"))
+		}
+	}
+	if n := srv.Count("/measurements"); n != 3 {
+		t.Errorf("expected 3 upstream requests, got %d", n)
+	}
+
+	for _, tc := range []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodDelete, "/data/2.5/weather?q=Berlin", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/weather?q=Berlin", http.StatusNotFound},
+		{http.MethodGet, "/healthz", http.StatusOK},
+	} {
+		if resp, _ := get(t, tc.method, ps.URL+tc.path, nil); resp.StatusCode != tc.want {
+			t.Errorf("%s %s: expected %d, got %d", tc.method, tc.path, tc.want, resp.StatusCode)
+		}
+	}
+
+	srv.Close()
+	resp, body = get(t, http.MethodGet, ps.URL+"/data/2.5/weather?q=Berlin", nil)
+	if resp.StatusCode != http.StatusBadGateway || strings.Contains(body, testKey) {
+		t.Errorf("expected a 502 without the key, got %d %s", resp.StatusCode, body)
+	}
+	if s := p.Stats(); s.UpstreamErrors != 1 {
+		t.Errorf("expected 1 upstream error, got %d", s.UpstreamErrors)
+	}
+}
+
+// TestMetrics will verify the Prometheus text of the counters
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+
+	_, ps, _ := newTestProxy(t, WithTokens(map[string]string{"billing": "tok-b"}))
+	get(t, http.MethodGet, ps.URL+"/data/2.5/weather?q=Berlin&appid=tok-b", nil)
+	get(t, http.MethodGet, ps.URL+"/data/2.5/weather?q=Berlin&appid=tok-b", nil)
+	get(t, http.MethodGet, ps.URL+"/data/2.5/weather?q=Berlin&appid=nope", nil)
+
+	if resp, _ := get(t, http.MethodGet, ps.URL+"/metrics", nil); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 without a token, got %d", resp.StatusCode)
+	}
+	resp, body := get(t, http.MethodGet, ps.URL+"/metrics", http.Header{"Authorization": {"Bearer tok-b"}})
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain") {
+		t.Errorf("expected text, got %q", resp.Header.Get("Content-Type"))
+	}
+	for _, want := range []string{
+		"# TYPE owm_proxy_requests_total counter\n",
+		`owm_proxy_requests_total{client="billing"} 2` + "\n",
+		"owm_proxy_unauthorized_total 2\n",
+		"owm_proxy_cache_hits_total 1\n",
+		"owm_proxy_cache_misses_total 1\n",
+		`owm_proxy_upstream_responses_total{code="200"} 1` + "\n",
+		"# TYPE owm_proxy_cache_entries gauge\nowm_proxy_cache_entries 1\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in\n%s", want, body)
+		}
+	}
+}
+
+// TestBodyTooLarge will verify that bodies over the limit are rejected
+// rather than truncated and cached
+func TestBodyTooLarge(t *testing.T) {
+	t.Parallel()
+
+	var hits int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(bytes.Repeat([]byte(" "), maxBody+1))
+	}))
+	defer upstream.Close()
+
+	p, err := New(testKey, WithOpenAccess(), WithUpstream(upstream.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps := httptest.NewServer(p)
+	defer ps.Close()
+
+	for i := 0; i < 2; i++ {
+		resp, body := get(t, http.MethodGet, ps.URL+"/data/2.5/weather?q=Berlin", nil)
+		if resp.StatusCode != http.StatusBadGateway || len(body) > maxBody {
+			t.Errorf("expected a 502, got %d with %d bytes", resp.StatusCode, len(body))
+		}
+	}
+	if n := atomic.LoadInt32(&hits); n != 2 {
+		t.Errorf("expected the response not to be cached, got %d upstream requests", n)
+	}
+	if s := p.Stats(); s.UpstreamErrors != 2 {
+		t.Errorf("expected 2 upstream errors, got %d", s.UpstreamErrors)
+	}
+
+	resp, err := http.Post(ps.URL+"/data/3.0/measurements", "application/json", bytes.NewReader(make([]byte, maxBody+1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413, got %d", resp.StatusCode)
+	}
+	if n := atomic.LoadInt32(&hits); n != 2 {
+		t.Errorf("expected no upstream request, got %d", n)
+	}
+}
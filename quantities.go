@@ -0,0 +1,294 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"github.com/briandowns/openweathermap/meteo"
+	"github.com/briandowns/openweathermap/units"
+)
+
+// Quantities holds the measured values of a result along with their
+// units. Values the endpoint doesn't return are left at zero, except for
+// DewPoint which is computed from the temperature and humidity when
+// missing. Rain and Snow hold the volume of the result's period: the last
+// hour for current weather, 3 hours for the 5 day forecast and the whole
+// day for daily forecasts.
+type Quantities struct {
+	Temperature units.Temperature
+	FeelsLike   units.Temperature
+	TempMin     units.Temperature
+	TempMax     units.Temperature
+	DewPoint    units.Temperature
+
+	Pressure   units.Pressure
+	WindSpeed  units.Speed
+	WindGust   units.Speed
+	Visibility units.Distance
+
+	Rain units.Precipitation
+	Snow units.Precipitation
+}
+
+// newQuantities returns Quantities with every value set to zero in the
+// units of the system.
+func newQuantities(s units.System) Quantities {
+	t := units.Temperature{Unit: s.Temperature}
+	return Quantities{
+		Temperature: t,
+		FeelsLike:   t,
+		TempMin:     t,
+		TempMax:     t,
+		DewPoint:    t,
+		Pressure:    units.Pressure{Unit: s.Pressure},
+		WindSpeed:   units.Speed{Unit: s.Speed},
+		WindGust:    units.Speed{Unit: s.Speed},
+		Visibility:  units.Distance{Unit: s.Distance},
+		Rain:        units.Precipitation{Unit: s.Precipitation},
+		Snow:        units.Precipitation{Unit: s.Precipitation},
+	}
+}
+
+// In returns the quantities converted to the units of the given system.
+func (q Quantities) In(s units.System) Quantities {
+	return Quantities{
+		Temperature: q.Temperature.In(s.Temperature),
+		FeelsLike:   q.FeelsLike.In(s.Temperature),
+		TempMin:     q.TempMin.In(s.Temperature),
+		TempMax:     q.TempMax.In(s.Temperature),
+		DewPoint:    q.DewPoint.In(s.Temperature),
+		Pressure:    q.Pressure.In(s.Pressure),
+		WindSpeed:   q.WindSpeed.In(s.Speed),
+		WindGust:    q.WindGust.In(s.Speed),
+		Visibility:  q.Visibility.In(s.Distance),
+		Rain:        q.Rain.In(s.Precipitation),
+		Snow:        q.Snow.In(s.Precipitation),
+	}
+}
+
+// volume returns the hourly volume, or the 3 hour one when that's the
+// only one given.
+func volume(oneH, threeH float64) float64 {
+	if oneH != 0 {
+		return oneH
+	}
+	return threeH
+}
+
+// mainQuantities fills the quantities shared by the results using Main
+// and Wind.
+func mainQuantities(s units.System, m Main, w Wind, rain Rain, snow Snow) Quantities {
+	q := newQuantities(s)
+	q.Temperature.Value = m.Temp
+	q.FeelsLike.Value = m.FeelsLike
+	q.TempMin.Value = m.TempMin
+	q.TempMax.Value = m.TempMax
+	q.Pressure.Value = m.Pressure
+	q.WindSpeed.Value = w.Speed
+	q.WindGust.Value = w.Gust
+	if m.Humidity > 0 {
+		q.DewPoint = meteo.DewPoint(q.Temperature, float64(m.Humidity))
+	}
+	q.Rain.Value = volume(rain.OneH, rain.ThreeH)
+	q.Snow.Value = volume(snow.OneH, snow.ThreeH)
+	return q
+}
+
+// unitsOf returns the applied units when set, otherwise the system of
+// the unit name.
+func unitsOf(applied *UnitPreferences, unit string) units.System {
+	if applied != nil {
+		return *applied
+	}
+	return units.SystemFor(unit)
+}
+
+// Units returns the units the data is in: the applied unit preferences
+// when set, otherwise the ones of the Unit field.
+func (w *CurrentWeatherData) Units() units.System { return unitsOf(w.AppliedUnits, w.Unit) }
+
+// Quantities returns the measured values with their units.
+func (w *CurrentWeatherData) Quantities() Quantities {
+	q := mainQuantities(w.Units(), w.Main, w.Wind, w.Rain, w.Snow)
+	q.Visibility.Value = w.Visibility
+	return q
+}
+
+// Quantities returns the measured values of the entry in the units of
+// the given system.
+func (f Forecast5WeatherList) Quantities(s units.System) Quantities {
+	q := mainQuantities(s, f.Main, f.Wind, f.Rain, f.Snow)
+	q.Visibility.Value = f.Visibility
+	return q
+}
+
+// Units returns the units the data is in: the applied unit preferences
+// when set, otherwise the ones of the Unit field.
+func (f *Forecast5WeatherData) Units() units.System { return unitsOf(f.AppliedUnits, f.Unit) }
+
+// Quantities returns the measured values of every entry of the list.
+func (f *Forecast5WeatherData) Quantities() []Quantities {
+	qs := make([]Quantities, len(f.List))
+	for i := range f.List {
+		qs[i] = f.List[i].Quantities(f.Units())
+	}
+	return qs
+}
+
+// Quantities returns the forecasted values of the day in the units of the
+// given system. Temperature holds the day temperature, from which
+// DewPoint is computed.
+func (f Forecast16WeatherList) Quantities(s units.System) Quantities {
+	q := newQuantities(s)
+	q.Temperature.Value = f.Temp.Day
+	q.TempMin.Value = f.Temp.Min
+	q.TempMax.Value = f.Temp.Max
+	q.Pressure.Value = f.Pressure
+	q.WindSpeed.Value = f.Speed
+	q.WindGust.Value = f.Gust
+	if f.Humidity > 0 {
+		q.DewPoint = meteo.DewPoint(q.Temperature, float64(f.Humidity))
+	}
+	q.Rain.Value = f.Rain
+	q.Snow.Value = f.Snow
+	return q
+}
+
+// Units returns the units the data is in: the applied unit preferences
+// when set, otherwise the ones of the Unit field.
+func (f *Forecast16WeatherData) Units() units.System { return unitsOf(f.AppliedUnits, f.Unit) }
+
+// Quantities returns the forecasted values of every day of the list.
+func (f *Forecast16WeatherData) Quantities() []Quantities {
+	qs := make([]Quantities, len(f.List))
+	for i := range f.List {
+		qs[i] = f.List[i].Quantities(f.Units())
+	}
+	return qs
+}
+
+// Units returns the units the data is in: the applied unit preferences
+// when set, otherwise the ones of the Unit field.
+func (h *HistoricalWeatherData) Units() units.System { return unitsOf(h.AppliedUnits, h.Unit) }
+
+// Quantities returns the measured values of every entry of the list.
+func (h *HistoricalWeatherData) Quantities() []Quantities {
+	qs := make([]Quantities, len(h.List))
+	for i, l := range h.List {
+		qs[i] = mainQuantities(h.Units(), l.Main, l.Wind, l.Rain, Snow{})
+	}
+	return qs
+}
+
+// Quantities returns the measured values in the units of the given
+// system.
+func (c OneCallCurrentData) Quantities(s units.System) Quantities {
+	q := newQuantities(s)
+	q.Temperature.Value = c.Temp
+	q.FeelsLike.Value = c.FeelsLike
+	q.DewPoint.Value = c.DewPoint
+	q.Pressure.Value = c.Pressure
+	q.WindSpeed.Value = c.WindSpeed
+	q.WindGust.Value = c.WindGust
+	q.Visibility.Value = c.Visibility
+	q.Rain.Value = volume(c.Rain.OneH, c.Rain.ThreeH)
+	q.Snow.Value = volume(c.Snow.OneH, c.Snow.ThreeH)
+	return q
+}
+
+// Quantities returns the measured values in the units of the given
+// system.
+func (d OneCallTimeMachineData) Quantities(s units.System) Quantities {
+	q := newQuantities(s)
+	q.Temperature.Value = d.Temp
+	q.FeelsLike.Value = d.FeelsLike
+	q.DewPoint.Value = d.DewPoint
+	q.Pressure.Value = d.Pressure
+	q.WindSpeed.Value = d.WindSpeed
+	q.WindGust.Value = d.WindGust
+	q.Visibility.Value = d.Visibility
+	q.Rain.Value = volume(d.Rain.OneH, d.Rain.ThreeH)
+	q.Snow.Value = volume(d.Snow.OneH, d.Snow.ThreeH)
+	return q
+}
+
+// Quantities returns the forecasted values in the units of the given
+// system.
+func (h OneCallHourlyData) Quantities(s units.System) Quantities {
+	q := newQuantities(s)
+	q.Temperature.Value = h.Temp
+	q.FeelsLike.Value = h.FeelsLike
+	q.DewPoint.Value = h.DewPoint
+	q.Pressure.Value = h.Pressure
+	q.WindSpeed.Value = h.WindSpeed
+	q.WindGust.Value = h.WindGust
+	q.Visibility.Value = h.Visibility
+	q.Rain.Value = volume(h.Rain.OneH, h.Rain.ThreeH)
+	q.Snow.Value = volume(h.Snow.OneH, h.Snow.ThreeH)
+	return q
+}
+
+// Quantities returns the forecasted values of the day in the units of the
+// given system. Temperature and FeelsLike hold the day values.
+func (d OneCallDailyData) Quantities(s units.System) Quantities {
+	q := newQuantities(s)
+	q.Temperature.Value = d.Temp.Day
+	q.FeelsLike.Value = d.FeelsLike.Day
+	q.TempMin.Value = d.Temp.Min
+	q.TempMax.Value = d.Temp.Max
+	q.DewPoint.Value = d.DewPoint
+	q.Pressure.Value = d.Pressure
+	q.WindSpeed.Value = d.WindSpeed
+	q.WindGust.Value = d.WindGust
+	q.Rain.Value = d.Rain
+	q.Snow.Value = d.Snow
+	return q
+}
+
+// Units returns the units the data is in: the applied unit preferences
+// when set, otherwise the ones of the Unit field.
+func (w *OneCallData) Units() units.System { return unitsOf(w.AppliedUnits, w.Unit) }
+
+// CurrentQuantities returns the current measured values.
+func (w *OneCallData) CurrentQuantities() Quantities {
+	return w.Current.Quantities(w.Units())
+}
+
+// HourlyQuantities returns the forecasted values of every hour.
+func (w *OneCallData) HourlyQuantities() []Quantities {
+	qs := make([]Quantities, len(w.Hourly))
+	for i := range w.Hourly {
+		qs[i] = w.Hourly[i].Quantities(w.Units())
+	}
+	return qs
+}
+
+// DailyQuantities returns the forecasted values of every day.
+func (w *OneCallData) DailyQuantities() []Quantities {
+	qs := make([]Quantities, len(w.Daily))
+	for i := range w.Daily {
+		qs[i] = w.Daily[i].Quantities(w.Units())
+	}
+	return qs
+}
+
+// DataQuantities returns the measured values of every entry of a time
+// machine call.
+func (w *OneCallData) DataQuantities() []Quantities {
+	qs := make([]Quantities, len(w.Data))
+	for i := range w.Data {
+		qs[i] = w.Data[i].Quantities(w.Units())
+	}
+	return qs
+}
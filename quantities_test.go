@@ -0,0 +1,107 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"math"
+	"testing"
+
+	"github.com/briandowns/openweathermap/units"
+)
+
+// TestCurrentQuantities will verify that current weather values carry the
+// units they were requested in
+func TestCurrentQuantities(t *testing.T) {
+	t.Parallel()
+
+	w := &CurrentWeatherData{
+		Main:       Main{Temp: 68, FeelsLike: 66, Pressure: 1013.25},
+		Wind:       Wind{Speed: 10, Gust: 20},
+		Visibility: 10000,
+		Rain:       Rain{OneH: 0.5},
+		Unit:       DataUnits["F"],
+	}
+
+	q := w.Quantities()
+	if q.Temperature.Unit != units.Fahrenheit || q.WindSpeed.Unit != units.MilesPerHour {
+		t.Errorf("unexpected units %v, %v", q.Temperature, q.WindSpeed)
+	}
+	if c := q.Temperature.Celsius(); math.Abs(c-20) > 1e-9 {
+		t.Errorf("expected 20°C, got %v", c)
+	}
+	if q.Rain.Value != 0.5 || q.Visibility.Value != 10000 || q.WindGust.Value != 20 {
+		t.Errorf("unexpected quantities %+v", q)
+	}
+
+	m := q.In(units.Metric)
+	if math.Abs(m.WindSpeed.Value-4.4704) > 1e-9 || m.Temperature.Unit != units.Celsius {
+		t.Errorf("unexpected metric quantities %+v", m)
+	}
+}
+
+// TestForecastQuantities will verify that forecast entries use the unit of
+// their forecast
+func TestForecastQuantities(t *testing.T) {
+	t.Parallel()
+
+	f, err := NewForecast("5", "K", "EN", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f5 := f.ForecastWeatherJson.(*Forecast5WeatherData)
+	f5.List = []Forecast5WeatherList{{Main: Main{Temp: 273.15}, Rain: Rain{ThreeH: 3}}}
+
+	qs := f5.Quantities()
+	if len(qs) != 1 || qs[0].Temperature.Celsius() != 0 || qs[0].Rain.Value != 3 {
+		t.Errorf("unexpected quantities %+v", qs)
+	}
+
+	f16 := &Forecast16WeatherData{
+		Unit: DataUnits["C"],
+		List: []Forecast16WeatherList{{Temp: Temperature{Day: 10, Min: 5, Max: 15}, Speed: 3}},
+	}
+	qs = f16.Quantities()
+	if qs[0].TempMax.Fahrenheit() != 59 || qs[0].WindSpeed.Unit != units.MetersPerSecond {
+		t.Errorf("unexpected quantities %+v", qs)
+	}
+}
+
+// TestOneCallQuantities will verify the quantities of every onecall
+// series
+func TestOneCallQuantities(t *testing.T) {
+	t.Parallel()
+
+	o := &OneCallData{
+		Unit:    DataUnits["C"],
+		Current: OneCallCurrentData{Temp: 20, DewPoint: 10, Pressure: 1000},
+		Hourly:  []OneCallHourlyData{{Temp: 21}, {Temp: 22}},
+		Daily:   []OneCallDailyData{{Temp: Temperature{Day: 25, Min: 15, Max: 27}, Rain: 4}},
+	}
+
+	if q := o.CurrentQuantities(); q.DewPoint.Value != 10 || q.Pressure.In(units.Kilopascal).Value != 100 {
+		t.Errorf("unexpected current quantities %+v", q)
+	}
+	if qs := o.HourlyQuantities(); len(qs) != 2 || qs[1].Temperature.Value != 22 {
+		t.Errorf("unexpected hourly quantities %+v", qs)
+	}
+	if qs := o.DailyQuantities(); qs[0].TempMin.Value != 15 || qs[0].Rain.In(units.Centimeters).Value != 0.4 {
+		t.Errorf("unexpected daily quantities %+v", qs)
+	}
+
+	h := &HistoricalWeatherData{Unit: DataUnits["K"], List: []WeatherHistory{{Main: Main{Temp: 300}}}}
+	if qs := h.Quantities(); qs[0].Temperature.Unit != units.Kelvin {
+		t.Errorf("unexpected history quantities %+v", qs)
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var errInvalidRateLimiter = errors.New("invalid rate limiter")
+
+// RateLimiter paces the requests of the calls that poll, e.g. Watch.
+// Wait blocks until a request may be made or the context is done.
+type RateLimiter interface {
+	Wait(ctx context.Context) error
+}
+
+// WithRateLimiter sets the rate limiter shared by the calls that poll.
+// Requests aren't limited by default.
+func WithRateLimiter(l RateLimiter) Option {
+	return func(s *Settings) error {
+		if l == nil {
+			return errInvalidRateLimiter
+		}
+		s.limiter = l
+		return nil
+	}
+}
+
+// wait waits for the rate limiter when one is set.
+func (s *Settings) wait(ctx context.Context) error {
+	if s.limiter == nil {
+		return ctx.Err()
+	}
+	return s.limiter.Wait(ctx)
+}
+
+// TokenBucket is a RateLimiter allowing bursts of up to n requests and n
+// requests per period on average, e.g. the 60 calls a minute of the free
+// plan.
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewTokenBucket returns a new TokenBucket pointer allowing n requests
+// per period. It starts full.
+func NewTokenBucket(n int, per time.Duration) *TokenBucket {
+	if n < 1 {
+		n = 1
+	}
+	return &TokenBucket{
+		rate:   float64(n) / per.Seconds(),
+		burst:  float64(n),
+		tokens: float64(n),
+		now:    time.Now,
+	}
+}
+
+// reserve takes a token and returns how long to wait before using it.
+func (b *TokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel gives back a token that wasn't used.
+func (b *TokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens++
+}
+
+// Allow takes a token when one is available without waiting.
+func (b *TokenBucket) Allow() bool {
+	if b.reserve() > 0 {
+		b.cancel()
+		return false
+	}
+	return true
+}
+
+// Wait blocks until a token is available or the context is done.
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	d := b.reserve()
+	if d == 0 {
+		return nil
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		b.cancel()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestTokenBucket will verify that bursts are allowed and tokens refill
+// at the rate
+func TestTokenBucket(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+	b := NewTokenBucket(3, time.Minute)
+	b.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if !b.Allow() {
+			t.Fatalf("expected request %d of the burst to be allowed", i)
+		}
+	}
+	if b.Allow() {
+		t.Error("expected the bucket to be empty")
+	}
+	if d := b.reserve(); d != 20*time.Second {
+		t.Errorf("expected to wait 20s, got %v", d)
+	}
+	b.cancel()
+
+	now = now.Add(20 * time.Second)
+	if !b.Allow() || b.Allow() {
+		t.Error("expected one token after 20s")
+	}
+
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		b.Allow()
+	}
+	if b.Allow() {
+		t.Error("expected the bucket to hold at most the burst")
+	}
+}
+
+// TestTokenBucketWait will verify that Wait blocks until a token is
+// available and gives it back when the context is done
+func TestTokenBucketWait(t *testing.T) {
+	t.Parallel()
+
+	b := NewTokenBucket(1, 50*time.Millisecond)
+	ctx := context.Background()
+	if err := b.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if err := b.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < 30*time.Millisecond {
+		t.Errorf("expected to wait for a token, waited %v", d)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := b.Wait(cancelled); err != context.Canceled {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+
+	if _, err := NewCurrent("C", "EN", "key", WithRateLimiter(nil)); err != errInvalidRateLimiter {
+		t.Errorf("expected %v, got %v", errInvalidRateLimiter, err)
+	}
+}
@@ -0,0 +1,286 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rules evaluates threshold rules, e.g. "temp < 0 for 3h" or
+// "wind_gust > 20 within 24h", over the series of weather results and
+// returns the intervals where they hold. Rules can be written in JSON or
+// YAML.
+package rules
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	errMissingField      = errors.New("rule field is required")
+	errInvalidComparator = errors.New("comparator should be one of <, <=, >, >=, == or !=")
+	errNegativeDuration  = errors.New("rule durations should not be negative")
+	errUnknownFormat     = errors.New("rules file should be .json, .yaml or .yml")
+)
+
+// Comparator compares the value of a field with the threshold.
+type Comparator string
+
+// Comparators.
+const (
+	Less         Comparator = "<"
+	LessEqual    Comparator = "<="
+	Greater      Comparator = ">"
+	GreaterEqual Comparator = ">="
+	Equal        Comparator = "=="
+	NotEqual     Comparator = "!="
+)
+
+// compare reports whether the value satisfies the comparator. It's false
+// for unknown comparators.
+func (c Comparator) compare(v, threshold float64) bool {
+	switch c {
+	case Less:
+		return v < threshold
+	case LessEqual:
+		return v <= threshold
+	case Greater:
+		return v > threshold
+	case GreaterEqual:
+		return v >= threshold
+	case Equal:
+		return v == threshold
+	case NotEqual:
+		return v != threshold
+	}
+	return false
+}
+
+// valid reports whether the comparator is known.
+func (c Comparator) valid() bool {
+	switch c {
+	case Less, LessEqual, Greater, GreaterEqual, Equal, NotEqual:
+		return true
+	}
+	return false
+}
+
+// Duration is a time.Duration written as a string, e.g. "3h" or "90m",
+// in JSON and YAML.
+type Duration time.Duration
+
+// String satisfies the fmt.Stringer interface.
+func (d Duration) String() string { return time.Duration(d).String() }
+
+func (d *Duration) parse(s string) error {
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalJSON satisfies the json.Marshaler interface.
+func (d Duration) MarshalJSON() ([]byte, error) { return json.Marshal(d.String()) }
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface.
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	return d.parse(s)
+}
+
+// MarshalYAML satisfies the yaml.Marshaler interface.
+func (d Duration) MarshalYAML() (interface{}, error) { return d.String(), nil }
+
+// UnmarshalYAML satisfies the yaml.Unmarshaler interface.
+func (d *Duration) UnmarshalYAML(n *yaml.Node) error {
+	var s string
+	if err := n.Decode(&s); err != nil {
+		return err
+	}
+	return d.parse(s)
+}
+
+// Rule is a condition on a field of the samples of a series.
+type Rule struct {
+	Name string `json:"name" yaml:"name"`
+
+	// Field is the path of the value in the samples, made of the JSON
+	// names of the fields separated by dots, e.g. "main.temp" or
+	// "weather.0.id". Slices are indexed by number.
+	Field      string     `json:"field" yaml:"field"`
+	Comparator Comparator `json:"op" yaml:"op"`
+	Threshold  float64    `json:"threshold" yaml:"threshold"`
+
+	// For is how long the condition has to hold without interruption,
+	// zero being any single sample.
+	For Duration `json:"for,omitempty" yaml:"for,omitempty"`
+
+	// Within limits the samples to the ones before the first sample plus
+	// Within, zero being the whole series.
+	Within Duration `json:"within,omitempty" yaml:"within,omitempty"`
+}
+
+// String satisfies the fmt.Stringer interface.
+func (r Rule) String() string {
+	s := fmt.Sprintf("%s %s %v", r.Field, r.Comparator, r.Threshold)
+	if r.For > 0 {
+		s += " for " + r.For.String()
+	}
+	if r.Within > 0 {
+		s += " within " + r.Within.String()
+	}
+	return s
+}
+
+// Validate makes sure the rule can be evaluated. Whether the field exists
+// is only known once the rule is evaluated.
+func (r Rule) Validate() error {
+	if r.Field == "" {
+		return errMissingField
+	}
+	if !r.Comparator.valid() {
+		return errInvalidComparator
+	}
+	if r.For < 0 || r.Within < 0 {
+		return errNegativeDuration
+	}
+	return nil
+}
+
+// validate validates every rule.
+func validate(rules []Rule) error {
+	for i, r := range rules {
+		if err := r.Validate(); err != nil {
+			return fmt.Errorf("rule %d (%s): %w", i, r.Name, err)
+		}
+	}
+	return nil
+}
+
+// ParseJSON parses a JSON list of rules.
+func ParseJSON(b []byte) ([]Rule, error) {
+	var rules []Rule
+	if err := json.Unmarshal(b, &rules); err != nil {
+		return nil, err
+	}
+	return rules, validate(rules)
+}
+
+// ParseYAML parses a YAML list of rules.
+func ParseYAML(b []byte) ([]Rule, error) {
+	var rules []Rule
+	if err := yaml.Unmarshal(b, &rules); err != nil {
+		return nil, err
+	}
+	return rules, validate(rules)
+}
+
+// LoadFile reads the rules of a file, parsed as JSON or YAML depending on
+// its extension.
+func LoadFile(path string) ([]Rule, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return ParseJSON(b)
+	case ".yaml", ".yml":
+		return ParseYAML(b)
+	}
+	return nil, errUnknownFormat
+}
+
+// Match is an interval where a rule holds.
+type Match struct {
+	Rule  Rule
+	Start time.Time
+	End   time.Time // end of the last matching sample
+
+	// Peak is the value the furthest past the threshold, e.g. the lowest
+	// temperature of a "temp < 0" rule.
+	Peak float64
+}
+
+// Duration returns how long the rule holds.
+func (m Match) Duration() time.Duration { return m.End.Sub(m.Start) }
+
+// Evaluate returns the intervals of the series where the rule holds.
+func (r Rule) Evaluate(s Series) ([]Match, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+
+	var limit time.Time
+	if r.Within > 0 && len(s) > 0 {
+		limit = s[0].Time.Add(time.Duration(r.Within))
+	}
+
+	var matches []Match
+	var current *Match
+	flush := func() {
+		if current != nil && current.Duration() >= time.Duration(r.For) {
+			matches = append(matches, *current)
+		}
+		current = nil
+	}
+
+	for i, sample := range s {
+		if !limit.IsZero() && !sample.Time.Before(limit) {
+			break
+		}
+		v, err := Value(sample.Data, r.Field)
+		if err != nil {
+			return nil, err
+		}
+		if !r.Comparator.compare(v, r.Threshold) {
+			flush()
+			continue
+		}
+
+		end := sample.Time.Add(s.step(i))
+		if current == nil {
+			current = &Match{Rule: r, Start: sample.Time, Peak: v}
+		}
+		current.End = end
+		if r.Comparator.compare(v, current.Peak) {
+			current.Peak = v
+		}
+	}
+	flush()
+
+	return matches, nil
+}
+
+// Evaluate evaluates every rule over the series and returns the matches
+// in the order of the rules.
+func Evaluate(rules []Rule, s Series) ([]Match, error) {
+	var matches []Match
+	for _, r := range rules {
+		m, err := r.Evaluate(s)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", r.Name, err)
+		}
+		matches = append(matches, m...)
+	}
+	return matches, nil
+}
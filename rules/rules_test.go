@@ -0,0 +1,185 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	owm "github.com/briandowns/openweathermap"
+)
+
+const start = 1700000000
+
+// hourly returns one call data with an hour of data per temperature and
+// gust.
+func hourly(temps, gusts []float64) *owm.OneCallData {
+	w := &owm.OneCallData{}
+	for i := range temps {
+		w.Hourly = append(w.Hourly, owm.OneCallHourlyData{Dt: start + i*3600, Temp: temps[i], WindGust: gusts[i]})
+	}
+	return w
+}
+
+// TestLoadFile will verify that the same rules are loaded from YAML and
+// JSON
+func TestLoadFile(t *testing.T) {
+	t.Parallel()
+
+	for _, path := range []string{"testdata/rules.yaml", "testdata/rules.json"} {
+		rules, err := LoadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rules) != 2 {
+			t.Fatalf("%s: expected 2 rules, got %d", path, len(rules))
+		}
+		if r := rules[0]; r.Name != "frost" || r.Field != "temp" || r.Comparator != Less || r.Threshold != 0 || r.For != Duration(3*time.Hour) {
+			t.Errorf("%s: unexpected rule %+v", path, r)
+		}
+		if r := rules[1]; r.String() != "wind_gust > 20 within 24h0m0s" {
+			t.Errorf("%s: unexpected rule %s", path, r)
+		}
+	}
+
+	if _, err := LoadFile("rules.go"); err != errUnknownFormat {
+		t.Errorf("expected %v, got %v", errUnknownFormat, err)
+	}
+}
+
+// TestParseInvalid will verify that invalid rules are rejected
+func TestParseInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		doc  string
+		want error
+	}{
+		{`[{"op": "<", "threshold": 0}]`, errMissingField},
+		{`[{"field": "temp", "op": "~", "threshold": 0}]`, errInvalidComparator},
+		{`[{"field": "temp", "op": "<", "for": "-1h"}]`, errNegativeDuration},
+	}
+	for _, tt := range tests {
+		if _, err := ParseJSON([]byte(tt.doc)); !errors.Is(err, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.doc, tt.want, err)
+		}
+		if _, err := ParseYAML([]byte(tt.doc)); !errors.Is(err, tt.want) {
+			t.Errorf("%s: expected %v from YAML, got %v", tt.doc, tt.want, err)
+		}
+	}
+	if _, err := ParseYAML([]byte("- field: temp\n  op: <\n  for: soon\n")); err == nil {
+		t.Error("expected an invalid duration to fail")
+	}
+}
+
+// TestEvaluateHourly will verify the intervals matched over the hourly
+// forecast
+func TestEvaluateHourly(t *testing.T) {
+	t.Parallel()
+
+	temps := []float64{1, -1, -2, 0.5, -1, -3, -2, -1, 2}
+	gusts := []float64{5, 25, 10, 10, 10, 10, 10, 10, 30}
+	rules, err := LoadFile("testdata/rules.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the gust of the last hour is past the 8h window
+	rules[1].Within = Duration(8 * time.Hour)
+
+	matches, err := Evaluate(rules, Hourly(hourly(temps, gusts)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("expected 2 matches, got %+v", matches)
+	}
+
+	frost := matches[0]
+	if frost.Rule.Name != "frost" || frost.Start.Unix() != start+4*3600 || frost.Duration() != 4*time.Hour || frost.Peak != -3 {
+		t.Errorf("unexpected frost match %+v", frost)
+	}
+	gust := matches[1]
+	if gust.Rule.Name != "gusts" || gust.Start.Unix() != start+3600 || gust.Duration() != time.Hour || gust.Peak != 25 {
+		t.Errorf("unexpected gust match %+v", gust)
+	}
+}
+
+// TestEvaluateSeries will verify rules over the current weather, the
+// 5 day forecast and the daily forecast
+func TestEvaluateSeries(t *testing.T) {
+	t.Parallel()
+
+	current := &owm.CurrentWeatherData{Dt: start, Main: owm.Main{Temp: 31}, Weather: []owm.Weather{{ID: 800}}}
+	m, err := Rule{Field: "main.temp", Comparator: GreaterEqual, Threshold: 30}.Evaluate(Current(current))
+	if err != nil || len(m) != 1 || m[0].Peak != 31 || m[0].Duration() != 0 {
+		t.Errorf("unexpected current match %+v (%v)", m, err)
+	}
+	m, err = Rule{Field: "weather.0.id", Comparator: Equal, Threshold: 800}.Evaluate(Current(current))
+	if err != nil || len(m) != 1 {
+		t.Errorf("unexpected condition match %+v (%v)", m, err)
+	}
+
+	f := &owm.Forecast5WeatherData{List: []owm.Forecast5WeatherList{
+		{Dt: start, Rain: owm.Rain{ThreeH: 0}},
+		{Dt: start + 3*3600, Rain: owm.Rain{ThreeH: 4}},
+		{Dt: start + 6*3600, Rain: owm.Rain{ThreeH: 6}},
+		{Dt: start + 9*3600},
+	}}
+	m, err = Rule{Field: "rain.3h", Comparator: Greater, Threshold: 1, For: Duration(6 * time.Hour)}.Evaluate(Forecast5(f))
+	if err != nil || len(m) != 1 || m[0].Duration() != 6*time.Hour || m[0].Peak != 6 {
+		t.Errorf("unexpected forecast match %+v (%v)", m, err)
+	}
+
+	daily := &owm.OneCallData{Daily: []owm.OneCallDailyData{
+		{Dt: start, Temp: owm.Temperature{Max: 25}},
+		{Dt: start + 86400, Temp: owm.Temperature{Max: 36}},
+	}}
+	m, err = Rule{Field: "temp.max", Comparator: Greater, Threshold: 35}.Evaluate(Daily(daily))
+	if err != nil || len(m) != 1 || m[0].Start.Unix() != start+86400 || m[0].Duration() != 24*time.Hour {
+		t.Errorf("unexpected daily match %+v (%v)", m, err)
+	}
+}
+
+// TestValue will verify the lookup of field paths
+func TestValue(t *testing.T) {
+	t.Parallel()
+
+	h := &owm.OneCallHourlyData{Temp: 3, Humidity: 80, Rain: owm.Rain{OneH: 1.5}}
+	tests := []struct {
+		path string
+		want float64
+		err  error
+	}{
+		{"temp", 3, nil},
+		{"Temp", 3, nil},
+		{"humidity", 80, nil},
+		{"rain.1h", 1.5, nil},
+		{"rain.2h", 0, errUnknownField},
+		{"weather.0.id", 0, errUnknownField},
+		{"rain", 0, errNotNumeric},
+	}
+	for _, tt := range tests {
+		v, err := Value(h, tt.path)
+		if !errors.Is(err, tt.err) || v != tt.want {
+			t.Errorf("%s: expected %v (%v), got %v (%v)", tt.path, tt.want, tt.err, v, err)
+		}
+	}
+
+	if _, err := Evaluate([]Rule{{Name: "x", Field: "nope", Comparator: Less}}, Hourly(hourly([]float64{1}, []float64{1}))); !errors.Is(err, errUnknownField) {
+		t.Errorf("expected %v, got %v", errUnknownField, err)
+	}
+}
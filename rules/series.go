@@ -0,0 +1,152 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	owm "github.com/briandowns/openweathermap"
+)
+
+var (
+	errUnknownField = errors.New("unknown field")
+	errNotNumeric   = errors.New("field isn't a number")
+)
+
+// Sample is a weather result at a point in time.
+type Sample struct {
+	Time time.Time
+	Data interface{}
+}
+
+// Series is a time ordered list of samples. Each sample is taken to last
+// until the next one, and the last one as long as the one before it.
+type Series []Sample
+
+// step returns how long the sample at i lasts.
+func (s Series) step(i int) time.Duration {
+	switch {
+	case i+1 < len(s):
+		return s[i+1].Time.Sub(s[i].Time)
+	case i > 0:
+		return s[i].Time.Sub(s[i-1].Time)
+	}
+	return 0
+}
+
+func unix(dt int) time.Time { return time.Unix(int64(dt), 0).UTC() }
+
+// Current returns a series of the single current weather result.
+func Current(w *owm.CurrentWeatherData) Series {
+	return Series{{Time: unix(w.Dt), Data: w}}
+}
+
+// Forecast5 returns the series of the 3 hour forecast steps.
+func Forecast5(f *owm.Forecast5WeatherData) Series {
+	s := make(Series, len(f.List))
+	for i := range f.List {
+		s[i] = Sample{Time: unix(f.List[i].Dt), Data: &f.List[i]}
+	}
+	return s
+}
+
+// Hourly returns the series of the hourly forecast of one call.
+func Hourly(w *owm.OneCallData) Series {
+	s := make(Series, len(w.Hourly))
+	for i := range w.Hourly {
+		s[i] = Sample{Time: unix(w.Hourly[i].Dt), Data: &w.Hourly[i]}
+	}
+	return s
+}
+
+// Daily returns the series of the daily forecast of one call.
+func Daily(w *owm.OneCallData) Series {
+	s := make(Series, len(w.Daily))
+	for i := range w.Daily {
+		s[i] = Sample{Time: unix(w.Daily[i].Dt), Data: &w.Daily[i]}
+	}
+	return s
+}
+
+// Value returns the number at the path of the data, e.g. "main.temp".
+// Struct fields are looked up by their JSON name, then by their Go name,
+// and slices by index.
+func Value(data interface{}, path string) (float64, error) {
+	v := reflect.ValueOf(data)
+	for _, part := range strings.Split(path, ".") {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return 0, fmt.Errorf("%w: %s", errUnknownField, path)
+			}
+			v = v.Elem()
+		}
+
+		switch v.Kind() {
+		case reflect.Struct:
+			f, ok := field(v, part)
+			if !ok {
+				return 0, fmt.Errorf("%w: %s", errUnknownField, path)
+			}
+			v = f
+		case reflect.Slice, reflect.Array:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= v.Len() {
+				return 0, fmt.Errorf("%w: %s", errUnknownField, path)
+			}
+			v = v.Index(i)
+		default:
+			return 0, fmt.Errorf("%w: %s", errUnknownField, path)
+		}
+	}
+
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), nil
+	}
+	return 0, fmt.Errorf("%w: %s", errNotNumeric, path)
+}
+
+// field returns the exported field of the struct with the given JSON or
+// Go name.
+func field(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		if tag := strings.Split(f.Tag.Get("json"), ",")[0]; tag == name {
+			return v.Field(i), true
+		}
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.PkgPath == "" && strings.EqualFold(f.Name, name) {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
@@ -0,0 +1,34 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"time"
+
+	"github.com/briandowns/openweathermap/astro"
+)
+
+// SunTimes returns the sunrise, sunset and twilight times at the
+// coordinates for the calendar day of date in its location. They're
+// computed offline, see the astro package.
+func (c *Coordinates) SunTimes(date time.Time) astro.Sun {
+	return astro.SunTimes(date, c.Latitude, c.Longitude)
+}
+
+// SunPosition returns the position of the sun in the sky at the
+// coordinates at the given time.
+func (c *Coordinates) SunPosition(t time.Time) astro.Position {
+	return astro.SunPosition(t, c.Latitude, c.Longitude)
+}
@@ -0,0 +1,52 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"testing"
+	"time"
+)
+
+// TestCoordinatesSunTimes will verify that the sun times of coordinates
+// agree with the ones returned with current weather
+func TestCoordinatesSunTimes(t *testing.T) {
+	t.Parallel()
+
+	// values of the current weather documentation example
+	w := CurrentWeatherData{
+		GeoPos:   Coordinates{Latitude: 44.34, Longitude: 10.99},
+		Dt:       1661870592,
+		Timezone: 7200,
+		Sys:      Sys{Sunrise: 1661834187, Sunset: 1661882248},
+	}
+
+	day := time.Unix(int64(w.Dt), 0).In(time.FixedZone("", w.Timezone))
+	s := w.GeoPos.SunTimes(day)
+	for _, c := range []struct {
+		got  time.Time
+		want int
+	}{
+		{s.Sunrise.Rise, w.Sys.Sunrise},
+		{s.Sunrise.Set, w.Sys.Sunset},
+	} {
+		if d := c.got.Sub(time.Unix(int64(c.want), 0)); d > 2*time.Minute || d < -2*time.Minute {
+			t.Errorf("expected %v, got %v", time.Unix(int64(c.want), 0), c.got)
+		}
+	}
+
+	if p := w.GeoPos.SunPosition(s.SolarNoon); p.Elevation < 50 {
+		t.Errorf("expected the sun high at noon, got %+v", p)
+	}
+}
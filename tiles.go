@@ -0,0 +1,393 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+var (
+	errInvalidTile    = errors.New("tile coordinates out of range for zoom level")
+	errInvalidZoom    = errors.New("zoom level should be between 0 and 20")
+	errInvalidOpacity = errors.New("opacity should be between 0 and 1")
+	errNotPNG         = errors.New("response is not a png image")
+)
+
+var (
+	tileURL  = "https://tile.openweathermap.org/map/%s/%d/%d/%d.png?%s"
+	tile2URL = "https://maps.openweathermap.org/maps/2.0/weather/%s/%d/%d/%d?%s"
+)
+
+// maxZoom is the highest zoom level served by the tile servers.
+const maxZoom = 20
+
+// TileLayer is the name of a weather map layer.
+type TileLayer string
+
+// Weather map 1.0 layers.
+const (
+	LayerClouds        TileLayer = "clouds_new"
+	LayerPrecipitation TileLayer = "precipitation_new"
+	LayerPressure      TileLayer = "pressure_new"
+	LayerWind          TileLayer = "wind_new"
+	LayerTemp          TileLayer = "temp_new"
+)
+
+// Weather map 2.0 layers.
+const (
+	Layer2Convective          TileLayer = "PAC0"
+	Layer2Precipitation       TileLayer = "PR0"
+	Layer2PrecipitationAccum  TileLayer = "PA0"
+	Layer2RainAccum           TileLayer = "PAR0"
+	Layer2SnowAccum           TileLayer = "PAS0"
+	Layer2SnowDepth           TileLayer = "SD0"
+	Layer2WindSpeed           TileLayer = "WS10"
+	Layer2WindArrows          TileLayer = "WND"
+	Layer2Pressure            TileLayer = "APM"
+	Layer2Temperature         TileLayer = "TA2"
+	Layer2DewPoint            TileLayer = "TD2"
+	Layer2SoilTemperature     TileLayer = "TS0"
+	Layer2SoilTemperatureDeep TileLayer = "TS10"
+	Layer2Humidity            TileLayer = "HRD0"
+	Layer2Clouds              TileLayer = "CL"
+)
+
+// TileLayers holds the layers of the 1.0 weather maps.
+var TileLayers = []TileLayer{
+	LayerClouds,
+	LayerPrecipitation,
+	LayerPressure,
+	LayerWind,
+	LayerTemp,
+}
+
+// Tile2Layers holds the layers of the 2.0 weather maps.
+var Tile2Layers = []TileLayer{
+	Layer2Convective,
+	Layer2Precipitation,
+	Layer2PrecipitationAccum,
+	Layer2RainAccum,
+	Layer2SnowAccum,
+	Layer2SnowDepth,
+	Layer2WindSpeed,
+	Layer2WindArrows,
+	Layer2Pressure,
+	Layer2Temperature,
+	Layer2DewPoint,
+	Layer2SoilTemperature,
+	Layer2SoilTemperatureDeep,
+	Layer2Humidity,
+	Layer2Clouds,
+}
+
+// IsV2 reports whether the layer is served by the 2.0 weather maps.
+func (l TileLayer) IsV2() bool {
+	for _, v := range Tile2Layers {
+		if l == v {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidTileLayer makes sure the given layer is one served by either
+// version of the weather maps.
+func ValidTileLayer(l TileLayer) bool {
+	if l.IsV2() {
+		return true
+	}
+	for _, v := range TileLayers {
+		if l == v {
+			return true
+		}
+	}
+	return false
+}
+
+// TileOptions holds the optional parameters of the 2.0 weather maps. They
+// are ignored for 1.0 layers.
+type TileOptions struct {
+	Date      time.Time // Date of the data, the current data when zero
+	Opacity   float64   // Opacity between 0 and 1, the server default when zero
+	FillBound bool      // Fill values outside of the palette bounds
+	Palette   string    // Custom palette, "value:hexcolor;value:hexcolor"
+}
+
+// LatLonToTile returns the x and y of the tile covering the given
+// coordinates at zoom level z, using the Web Mercator projection.
+func LatLonToTile(lat, lon float64, z int) (int, int) {
+	// the projection is undefined at the poles
+	lat = math.Max(math.Min(lat, 85.05112878), -85.05112878)
+
+	n := math.Exp2(float64(z))
+	latRad := lat * math.Pi / 180
+
+	x := int(math.Floor((lon + 180) / 360 * n))
+	y := int(math.Floor((1 - math.Log(math.Tan(latRad)+1/math.Cos(latRad))/math.Pi) / 2 * n))
+
+	return clampTile(x, n), clampTile(y, n)
+}
+
+// TileToLatLon returns the coordinates of the north west corner of
+// the given tile.
+func TileToLatLon(x, y, z int) (float64, float64) {
+	n := math.Exp2(float64(z))
+	lon := float64(x)/n*360 - 180
+	lat := math.Atan(math.Sinh(math.Pi*(1-2*float64(y)/n))) * 180 / math.Pi
+	return lat, lon
+}
+
+// clampTile keeps a tile index within the tiles of the zoom level.
+func clampTile(v int, n float64) int {
+	if v < 0 {
+		return 0
+	}
+	if max := int(n) - 1; v > max {
+		return max
+	}
+	return v
+}
+
+// validTile makes sure the tile exists at the given zoom level.
+func validTile(z, x, y int) error {
+	if z < 0 || z > maxZoom {
+		return errInvalidZoom
+	}
+	n := 1 << uint(z)
+	if x < 0 || x >= n || y < 0 || y >= n {
+		return errInvalidTile
+	}
+	return nil
+}
+
+// DefaultTileMaxAge is how long NewTileCache keeps tiles, about how often
+// the maps are updated.
+const DefaultTileMaxAge = 10 * time.Minute
+
+// TileCache stores fetched tiles on disk as Dir/layer/z/x/y.png, where
+// the layer of 2.0 tiles requested with options carries a hash of them.
+type TileCache struct {
+	Dir    string
+	MaxAge time.Duration // age past which tiles are fetched again, never when zero
+}
+
+// NewTileCache returns a new TileCache pointer storing tiles under the
+// given directory, creating it when needed, for DefaultTileMaxAge.
+func NewTileCache(dir string) (*TileCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &TileCache{Dir: dir, MaxAge: DefaultTileMaxAge}, nil
+}
+
+// path returns where the tile is stored.
+func (c *TileCache) path(layer string, z, x, y int) string {
+	return filepath.Join(c.Dir, layer, strconv.Itoa(z), strconv.Itoa(x), strconv.Itoa(y)+".png")
+}
+
+// Get returns the cached tile. The bool is false when the tile hasn't
+// been cached or is older than MaxAge.
+func (c *TileCache) Get(layer string, z, x, y int) ([]byte, bool) {
+	p := c.path(layer, z, x, y)
+	if c.MaxAge > 0 {
+		fi, err := os.Stat(p)
+		if err != nil || time.Since(fi.ModTime()) > c.MaxAge {
+			return nil, false
+		}
+	}
+	b, err := ioutil.ReadFile(p)
+	if err != nil {
+		return nil, false
+	}
+	return b, true
+}
+
+// Put stores the tile. The file is written to a temporary file first and
+// renamed so readers never see a partial tile.
+func (c *TileCache) Put(layer string, z, x, y int, b []byte) error {
+	p := c.path(layer, z, x, y)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(p, b)
+}
+
+// writeFileAtomic writes the data to a temporary file in the same
+// directory and renames it to the given path.
+func writeFileAtomic(path string, b []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Tiles is used to fetch weather map tiles.
+type Tiles struct {
+	Key   string
+	Cache *TileCache // optional, tiles are always fetched when nil
+	*Settings
+}
+
+// NewTiles creates a new reference to Tiles.
+func NewTiles(key string, options ...Option) (*Tiles, error) {
+	k, err := setKey(key)
+	if err != nil {
+		return nil, err
+	}
+	t := &Tiles{
+		Key:      k,
+		Settings: NewSettings(),
+	}
+
+	if err := setOptions(t.Settings, options); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// tileQuery returns the query parameters set by the options of a 2.0
+// tile, without the key.
+func tileQuery(opts *TileOptions) (url.Values, error) {
+	v := url.Values{}
+	if opts == nil {
+		return v, nil
+	}
+	if opts.Opacity < 0 || opts.Opacity > 1 {
+		return nil, errInvalidOpacity
+	}
+	if !opts.Date.IsZero() {
+		v.Set("date", strconv.FormatInt(opts.Date.Unix(), 10))
+	}
+	if opts.Opacity > 0 {
+		v.Set("opacity", strconv.FormatFloat(opts.Opacity, 'f', -1, 64))
+	}
+	if opts.FillBound {
+		v.Set("fill_bound", "true")
+	}
+	if opts.Palette != "" {
+		v.Set("palette", opts.Palette)
+	}
+	return v, nil
+}
+
+// cacheKey returns the name the tile is cached under. 2.0 tiles requested
+// with options are kept apart by a hash of their query, so tiles of
+// different dates, opacities or palettes never share an entry.
+func cacheKey(layer TileLayer, opts *TileOptions) (string, error) {
+	if !layer.IsV2() {
+		return string(layer), nil
+	}
+	v, err := tileQuery(opts)
+	if err != nil {
+		return "", err
+	}
+	if len(v) == 0 {
+		return string(layer), nil
+	}
+	sum := sha256.Sum256([]byte(v.Encode()))
+	return string(layer) + "@" + hex.EncodeToString(sum[:8]), nil
+}
+
+// tileURLFor builds the URL of the given tile.
+func (t *Tiles) tileURLFor(layer TileLayer, z, x, y int, opts *TileOptions) (string, error) {
+	if !layer.IsV2() {
+		v := url.Values{}
+		v.Set("appid", t.Key)
+		return fmt.Sprintf(tileURL, layer, z, x, y, v.Encode()), nil
+	}
+
+	v, err := tileQuery(opts)
+	if err != nil {
+		return "", err
+	}
+	v.Set("appid", t.Key)
+	return fmt.Sprintf(tile2URL, layer, z, x, y, v.Encode()), nil
+}
+
+// Tile returns the PNG of the given layer at z/x/y. The options are only
+// used for 2.0 layers and can be nil. When a cache is set it is checked
+// first and filled with fetched tiles.
+func (t *Tiles) Tile(layer TileLayer, z, x, y int, opts *TileOptions) ([]byte, error) {
+	if !ValidTileLayer(layer) {
+		return nil, fmt.Errorf("tile layer %q unavailable", layer)
+	}
+	if err := validTile(z, x, y); err != nil {
+		return nil, err
+	}
+
+	key, err := cacheKey(layer, opts)
+	if err != nil {
+		return nil, err
+	}
+	if t.Cache != nil {
+		if b, ok := t.Cache.Get(key, z, x, y); ok {
+			return b, nil
+		}
+	}
+
+	u, err := t.tileURLFor(layer, z, x, y, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := t.client.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if err := checkPNGResponse(response); err != nil {
+		return nil, err
+	}
+
+	b, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Cache != nil {
+		if err := t.Cache.Put(key, z, x, y, b); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// TileAt returns the PNG of the given layer covering the coordinates at
+// zoom level z.
+func (t *Tiles) TileAt(layer TileLayer, location *Coordinates, z int, opts *TileOptions) ([]byte, error) {
+	x, y := LatLonToTile(location.Latitude, location.Longitude, z)
+	return t.Tile(layer, z, x, y, opts)
+}
@@ -0,0 +1,356 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// pngTile returns a 256x256 png filled with the given color.
+func pngTile(t *testing.T, c color.Color) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 256, 256))
+	for y := 0; y < 256; y++ {
+		for x := 0; x < 256; x++ {
+			img.Set(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// TestLatLonToTile will verify the conversion between coordinates and tiles
+func TestLatLonToTile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		lat, lon float64
+		z, x, y  int
+	}{
+		{0, 0, 0, 0, 0},
+		{0, 0, 1, 1, 1},
+		{51.5074, -0.1278, 10, 511, 340},
+		{39.9526, -75.1652, 12, 1192, 1551},
+		{-33.8688, 151.2093, 8, 235, 153},
+		{89.9, 179.9, 3, 7, 0},
+		{90, 0, 3, 4, 0},
+		{-89.9, 0, 3, 4, 7},
+		{-90, 0, 3, 4, 7},
+	}
+
+	for _, tt := range tests {
+		x, y := LatLonToTile(tt.lat, tt.lon, tt.z)
+		if x != tt.x || y != tt.y {
+			t.Errorf("LatLonToTile(%v, %v, %d) = %d/%d, expected %d/%d", tt.lat, tt.lon, tt.z, x, y, tt.x, tt.y)
+		}
+
+		// the projection doesn't go past ~85.0511 degrees
+		lat, lon := TileToLatLon(x, y, tt.z)
+		if (lat < tt.lat && tt.lat < 85) || lon > tt.lon {
+			t.Errorf("north west corner %v/%v should be above and left of %v/%v", lat, lon, tt.lat, tt.lon)
+		}
+	}
+
+	lat, lon := TileToLatLon(0, 0, 0)
+	if math.Abs(lat-85.0511) > 0.001 || lon != -180 {
+		t.Errorf("unexpected corner %v/%v", lat, lon)
+	}
+}
+
+// TestTile will verify that 1.0 and 2.0 tiles are fetched from the right
+// servers with the right parameters
+func TestTile(t *testing.T) {
+	t.Parallel()
+
+	tile := pngTile(t, color.RGBA{R: 255, A: 128})
+	date := time.Unix(1552861800, 0)
+
+	srv, opt := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/map/clouds_new/3/4/2.png":
+			if q.Get("appid") != "key" {
+				t.Errorf("unexpected request %s", r.URL)
+			}
+		case "/maps/2.0/weather/TA2/3/4/2":
+			if q.Get("date") != "1552861800" || q.Get("opacity") != "0.6" || q.Get("fill_bound") != "true" {
+				t.Errorf("unexpected query %s", r.URL.RawQuery)
+			}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(tile)
+	}))
+	defer srv.Close()
+
+	tl, err := NewTiles("key", opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := tl.Tile(LayerClouds, 3, 4, 2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, tile) {
+		t.Error("unexpected tile content")
+	}
+
+	if _, err := tl.Tile(Layer2Temperature, 3, 4, 2, &TileOptions{Date: date, Opacity: 0.6, FillBound: true}); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := tl.Tile(LayerWind, 3, 4, 2, nil); err == nil {
+		t.Error("expected an error for a missing tile")
+	}
+	if _, err := tl.Tile("nope", 3, 4, 2, nil); err == nil {
+		t.Error("expected an error for an unknown layer")
+	}
+	if _, err := tl.Tile(LayerClouds, 3, 8, 2, nil); err != errInvalidTile {
+		t.Errorf("expected %v, got %v", errInvalidTile, err)
+	}
+	if _, err := tl.Tile(Layer2Temperature, 3, 4, 2, &TileOptions{Opacity: 2}); err != errInvalidOpacity {
+		t.Errorf("expected %v, got %v", errInvalidOpacity, err)
+	}
+}
+
+// TestTileNotPNG will verify that non png responses are rejected
+func TestTileNotPNG(t *testing.T) {
+	t.Parallel()
+
+	srv, opt := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html></html>"))
+	}))
+	defer srv.Close()
+
+	tl, err := NewTiles("key", opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tl.Tile(LayerTemp, 0, 0, 0, nil); err != errNotPNG {
+		t.Errorf("expected %v, got %v", errNotPNG, err)
+	}
+}
+
+// TestTileNoContentType will verify that responses without a Content-Type
+// are accepted
+func TestTileNoContentType(t *testing.T) {
+	t.Parallel()
+
+	tile := pngTile(t, color.RGBA{G: 255, A: 255})
+	srv, opt := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header()["Content-Type"] = nil // no sniffing
+		w.Write(tile)
+	}))
+	defer srv.Close()
+
+	tl, err := NewTiles("key", opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := tl.Tile(LayerTemp, 0, 0, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, tile) {
+		t.Error("unexpected tile content")
+	}
+}
+
+// TestTileCache will verify that cached tiles aren't fetched again
+func TestTileCache(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "owm-tiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tile := pngTile(t, color.RGBA{B: 255, A: 255})
+	var hits int32
+	srv, opt := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(tile)
+	}))
+	defer srv.Close()
+
+	tl, err := NewTiles("key", opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tl.Cache, err = NewTileCache(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		b, err := tl.TileAt(LayerPressure, &Coordinates{Latitude: 39.95, Longitude: -75.16}, 5, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b, tile) {
+			t.Error("unexpected tile content")
+		}
+	}
+	if n := atomic.LoadInt32(&hits); n != 1 {
+		t.Errorf("expected 1 request, got %d", n)
+	}
+
+	if _, err := os.Stat(tl.Cache.path(string(LayerPressure), 5, 9, 12)); err != nil {
+		t.Error(err)
+	}
+
+	date := time.Unix(1552861800, 0)
+	if _, err := tl.Tile(Layer2Pressure, 5, 9, 12, &TileOptions{Date: date}); err != nil {
+		t.Fatal(err)
+	}
+	key, err := cacheKey(Layer2Pressure, &TileOptions{Date: date})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := tl.Cache.Get(key, 5, 9, 12); !ok {
+		t.Error("expected dated tile to be cached")
+	}
+}
+
+// TestTileCacheMaxAge will verify that tiles older than the max age are
+// fetched again
+func TestTileCacheMaxAge(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "tiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tile := pngTile(t, color.RGBA{R: 255, A: 255})
+	var hits int32
+	srv, opt := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(tile)
+	}))
+	defer srv.Close()
+
+	tl, err := NewTiles("key", opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tl.Cache, err = NewTileCache(dir); err != nil {
+		t.Fatal(err)
+	}
+	if tl.Cache.MaxAge != DefaultTileMaxAge {
+		t.Errorf("expected the default max age, got %v", tl.Cache.MaxAge)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := tl.Tile(LayerTemp, 5, 9, 12, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := atomic.LoadInt32(&hits); n != 1 {
+		t.Fatalf("expected 1 request, got %d", n)
+	}
+
+	stale := time.Now().Add(-DefaultTileMaxAge - time.Minute)
+	if err := os.Chtimes(tl.Cache.path(string(LayerTemp), 5, 9, 12), stale, stale); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tl.Tile(LayerTemp, 5, 9, 12, nil); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&hits); n != 2 {
+		t.Errorf("expected the stale tile to be fetched again, got %d requests", n)
+	}
+
+	if err := os.Chtimes(tl.Cache.path(string(LayerTemp), 5, 9, 12), stale, stale); err != nil {
+		t.Fatal(err)
+	}
+	tl.Cache.MaxAge = 0
+	if _, ok := tl.Cache.Get(string(LayerTemp), 5, 9, 12); !ok {
+		t.Error("expected tiles to be kept without a max age")
+	}
+}
+
+// TestTileCacheOptions will verify that 2.0 tiles requested with
+// different palettes are cached separately
+func TestTileCacheOptions(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "tiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tiles := map[string][]byte{
+		"0:ff0000": pngTile(t, color.RGBA{R: 255, A: 255}),
+		"0:0000ff": pngTile(t, color.RGBA{B: 255, A: 255}),
+	}
+	var hits int32
+	srv, opt := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(tiles[r.URL.Query().Get("palette")])
+	}))
+	defer srv.Close()
+
+	tl, err := NewTiles("key", opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tl.Cache, err = NewTileCache(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		for palette, tile := range tiles {
+			b, err := tl.Tile(Layer2Temperature, 5, 9, 12, &TileOptions{Palette: palette})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(b, tile) {
+				t.Errorf("unexpected tile for palette %s", palette)
+			}
+		}
+	}
+	if n := atomic.LoadInt32(&hits); n != 2 {
+		t.Errorf("expected 2 requests, got %d", n)
+	}
+
+	if _, err := tl.Tile(Layer2Temperature, 5, 9, 12, &TileOptions{Opacity: 2}); err != errInvalidOpacity {
+		t.Errorf("expected %v, got %v", errInvalidOpacity, err)
+	}
+}
@@ -0,0 +1,292 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package units holds quantities that carry their unit of measure along
+// with conversions between the common units of each.
+package units
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// TemperatureUnit is a unit of temperature.
+type TemperatureUnit string
+
+// Temperature units.
+const (
+	Celsius    TemperatureUnit = "°C"
+	Fahrenheit TemperatureUnit = "°F"
+	Kelvin     TemperatureUnit = "K"
+)
+
+// SpeedUnit is a unit of speed.
+type SpeedUnit string
+
+// Speed units.
+const (
+	MetersPerSecond   SpeedUnit = "m/s"
+	KilometersPerHour SpeedUnit = "km/h"
+	MilesPerHour      SpeedUnit = "mph"
+	Knots             SpeedUnit = "kn"
+	FeetPerSecond     SpeedUnit = "ft/s"
+)
+
+// PressureUnit is a unit of pressure.
+type PressureUnit string
+
+// Pressure units.
+const (
+	Hectopascal          PressureUnit = "hPa"
+	Millibar             PressureUnit = "mbar"
+	Kilopascal           PressureUnit = "kPa"
+	InchesOfMercury      PressureUnit = "inHg"
+	MillimetersOfMercury PressureUnit = "mmHg"
+	PoundsPerSquareInch  PressureUnit = "psi"
+)
+
+// DistanceUnit is a unit of length.
+type DistanceUnit string
+
+// Distance units.
+const (
+	Meters        DistanceUnit = "m"
+	Kilometers    DistanceUnit = "km"
+	Feet          DistanceUnit = "ft"
+	Miles         DistanceUnit = "mi"
+	NauticalMiles DistanceUnit = "nmi"
+)
+
+// PrecipitationUnit is a unit of precipitation depth.
+type PrecipitationUnit string
+
+// Precipitation units.
+const (
+	Millimeters PrecipitationUnit = "mm"
+	Centimeters PrecipitationUnit = "cm"
+	Inches      PrecipitationUnit = "in"
+)
+
+// factors to convert from each unit to the base unit of its quantity.
+var (
+	speedFactors = map[SpeedUnit]float64{
+		MetersPerSecond:   1,
+		KilometersPerHour: 1 / 3.6,
+		MilesPerHour:      0.44704,
+		Knots:             1852.0 / 3600,
+		FeetPerSecond:     0.3048,
+	}
+	pressureFactors = map[PressureUnit]float64{
+		Hectopascal:          1,
+		Millibar:             1,
+		Kilopascal:           10,
+		InchesOfMercury:      33.8638866667,
+		MillimetersOfMercury: 1.33322387415,
+		PoundsPerSquareInch:  68.9475729318,
+	}
+	distanceFactors = map[DistanceUnit]float64{
+		Meters:        1,
+		Kilometers:    1000,
+		Feet:          0.3048,
+		Miles:         1609.344,
+		NauticalMiles: 1852,
+	}
+	precipitationFactors = map[PrecipitationUnit]float64{
+		Millimeters: 1,
+		Centimeters: 10,
+		Inches:      25.4,
+	}
+)
+
+// Valid reports whether the unit is known.
+func (u TemperatureUnit) Valid() bool { return u == Celsius || u == Fahrenheit || u == Kelvin }
+
+// Valid reports whether the unit is known.
+func (u SpeedUnit) Valid() bool { _, ok := speedFactors[u]; return ok }
+
+// Valid reports whether the unit is known.
+func (u PressureUnit) Valid() bool { _, ok := pressureFactors[u]; return ok }
+
+// Valid reports whether the unit is known.
+func (u DistanceUnit) Valid() bool { _, ok := distanceFactors[u]; return ok }
+
+// Valid reports whether the unit is known.
+func (u PrecipitationUnit) Valid() bool { _, ok := precipitationFactors[u]; return ok }
+
+// format renders a value followed by its unit.
+func format(v float64, unit string) string {
+	return strconv.FormatFloat(v, 'f', -1, 64) + " " + unit
+}
+
+// Temperature is a temperature in a given unit.
+type Temperature struct {
+	Value float64
+	Unit  TemperatureUnit
+}
+
+// In returns the temperature converted to the given unit.
+func (t Temperature) In(u TemperatureUnit) Temperature {
+	if t.Unit == u {
+		return t
+	}
+
+	// convert through Kelvin
+	k := t.Value
+	switch t.Unit {
+	case Celsius:
+		k = t.Value + 273.15
+	case Fahrenheit:
+		k = (t.Value-32)*5/9 + 273.15
+	}
+
+	switch u {
+	case Celsius:
+		return Temperature{k - 273.15, u}
+	case Fahrenheit:
+		return Temperature{(k-273.15)*9/5 + 32, u}
+	}
+	return Temperature{k, Kelvin}
+}
+
+// Celsius returns the value in degrees Celsius.
+func (t Temperature) Celsius() float64 { return t.In(Celsius).Value }
+
+// Fahrenheit returns the value in degrees Fahrenheit.
+func (t Temperature) Fahrenheit() float64 { return t.In(Fahrenheit).Value }
+
+// Kelvin returns the value in Kelvin.
+func (t Temperature) Kelvin() float64 { return t.In(Kelvin).Value }
+
+// String satisfies the fmt.Stringer interface.
+func (t Temperature) String() string {
+	if t.Unit == Kelvin {
+		return format(t.Value, string(t.Unit))
+	}
+	return strconv.FormatFloat(t.Value, 'f', -1, 64) + string(t.Unit)
+}
+
+// Speed is a speed in a given unit.
+type Speed struct {
+	Value float64
+	Unit  SpeedUnit
+}
+
+// In returns the speed converted to the given unit.
+func (s Speed) In(u SpeedUnit) Speed {
+	if s.Unit == u {
+		return s
+	}
+	return Speed{s.Value * speedFactors[s.Unit] / speedFactors[u], u}
+}
+
+// String satisfies the fmt.Stringer interface.
+func (s Speed) String() string { return format(s.Value, string(s.Unit)) }
+
+// Pressure is a pressure in a given unit.
+type Pressure struct {
+	Value float64
+	Unit  PressureUnit
+}
+
+// In returns the pressure converted to the given unit.
+func (p Pressure) In(u PressureUnit) Pressure {
+	if p.Unit == u {
+		return p
+	}
+	return Pressure{p.Value * pressureFactors[p.Unit] / pressureFactors[u], u}
+}
+
+// String satisfies the fmt.Stringer interface.
+func (p Pressure) String() string { return format(p.Value, string(p.Unit)) }
+
+// Distance is a length in a given unit.
+type Distance struct {
+	Value float64
+	Unit  DistanceUnit
+}
+
+// In returns the distance converted to the given unit.
+func (d Distance) In(u DistanceUnit) Distance {
+	if d.Unit == u {
+		return d
+	}
+	return Distance{d.Value * distanceFactors[d.Unit] / distanceFactors[u], u}
+}
+
+// String satisfies the fmt.Stringer interface.
+func (d Distance) String() string { return format(d.Value, string(d.Unit)) }
+
+// Precipitation is a precipitation depth in a given unit.
+type Precipitation struct {
+	Value float64
+	Unit  PrecipitationUnit
+}
+
+// In returns the precipitation converted to the given unit.
+func (p Precipitation) In(u PrecipitationUnit) Precipitation {
+	if p.Unit == u {
+		return p
+	}
+	return Precipitation{p.Value * precipitationFactors[p.Unit] / precipitationFactors[u], u}
+}
+
+// String satisfies the fmt.Stringer interface.
+func (p Precipitation) String() string { return format(p.Value, string(p.Unit)) }
+
+// System holds the unit used for each quantity.
+type System struct {
+	Temperature   TemperatureUnit
+	Speed         SpeedUnit
+	Pressure      PressureUnit
+	Distance      DistanceUnit
+	Precipitation PrecipitationUnit
+}
+
+// The unit systems of the API. Pressure is always in hPa, visibility in
+// meters and precipitation in millimeters.
+var (
+	Metric   = System{Celsius, MetersPerSecond, Hectopascal, Meters, Millimeters}
+	Imperial = System{Fahrenheit, MilesPerHour, Hectopascal, Meters, Millimeters}
+	Standard = System{Kelvin, MetersPerSecond, Hectopascal, Meters, Millimeters}
+)
+
+// SystemFor returns the System of the API's units parameter, "metric",
+// "imperial" or "internal"/"standard". Unknown names give Standard,
+// which is what the API defaults to.
+func SystemFor(name string) System {
+	switch name {
+	case "metric":
+		return Metric
+	case "imperial":
+		return Imperial
+	}
+	return Standard
+}
+
+// Validate makes sure every unit of the system is known.
+func (s System) Validate() error {
+	switch {
+	case !s.Temperature.Valid():
+		return fmt.Errorf("temperature unit %q unavailable", s.Temperature)
+	case !s.Speed.Valid():
+		return fmt.Errorf("speed unit %q unavailable", s.Speed)
+	case !s.Pressure.Valid():
+		return fmt.Errorf("pressure unit %q unavailable", s.Pressure)
+	case !s.Distance.Valid():
+		return fmt.Errorf("distance unit %q unavailable", s.Distance)
+	case !s.Precipitation.Valid():
+		return fmt.Errorf("precipitation unit %q unavailable", s.Precipitation)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package units
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a, b, tolerance float64) bool { return math.Abs(a-b) <= tolerance }
+
+// TestTemperature will verify the conversions between temperature units
+func TestTemperature(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		from Temperature
+		to   TemperatureUnit
+		want float64
+	}{
+		{Temperature{0, Celsius}, Fahrenheit, 32},
+		{Temperature{100, Celsius}, Kelvin, 373.15},
+		{Temperature{-40, Fahrenheit}, Celsius, -40},
+		{Temperature{98.6, Fahrenheit}, Celsius, 37},
+		{Temperature{0, Kelvin}, Fahrenheit, -459.67},
+		{Temperature{21.5, Celsius}, Celsius, 21.5},
+	}
+	for _, tt := range tests {
+		got := tt.from.In(tt.to)
+		if !near(got.Value, tt.want, 1e-9) || got.Unit != tt.to {
+			t.Errorf("%v in %s: expected %v, got %v", tt.from, tt.to, tt.want, got)
+		}
+	}
+
+	if c := (Temperature{50, Fahrenheit}).Celsius(); !near(c, 10, 1e-9) {
+		t.Errorf("expected 10, got %v", c)
+	}
+	if s := (Temperature{21.5, Celsius}).String(); s != "21.5°C" {
+		t.Errorf("unexpected string %q", s)
+	}
+}
+
+// TestSpeed will verify the conversions between speed units
+func TestSpeed(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		from Speed
+		to   SpeedUnit
+		want float64
+	}{
+		{Speed{10, MetersPerSecond}, KilometersPerHour, 36},
+		{Speed{10, MetersPerSecond}, Knots, 19.438445},
+		{Speed{60, MilesPerHour}, KilometersPerHour, 96.56064},
+		{Speed{1, Knots}, MilesPerHour, 1.150779},
+		{Speed{1, FeetPerSecond}, MetersPerSecond, 0.3048},
+	}
+	for _, tt := range tests {
+		got := tt.from.In(tt.to)
+		if !near(got.Value, tt.want, 1e-6) {
+			t.Errorf("%v in %s: expected %v, got %v", tt.from, tt.to, tt.want, got)
+		}
+	}
+}
+
+// TestPressure will verify the conversions between pressure units
+func TestPressure(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		from Pressure
+		to   PressureUnit
+		want float64
+	}{
+		{Pressure{1013.25, Hectopascal}, InchesOfMercury, 29.9213},
+		{Pressure{1013.25, Hectopascal}, MillimetersOfMercury, 760},
+		{Pressure{1013.25, Millibar}, PoundsPerSquareInch, 14.6959},
+		{Pressure{101.325, Kilopascal}, Hectopascal, 1013.25},
+	}
+	for _, tt := range tests {
+		got := tt.from.In(tt.to)
+		if !near(got.Value, tt.want, 1e-3) {
+			t.Errorf("%v in %s: expected %v, got %v", tt.from, tt.to, tt.want, got)
+		}
+	}
+}
+
+// TestDistanceAndPrecipitation will verify the conversions between
+// lengths and precipitation depths
+func TestDistanceAndPrecipitation(t *testing.T) {
+	t.Parallel()
+
+	if d := (Distance{10000, Meters}).In(Miles); !near(d.Value, 6.213712, 1e-6) {
+		t.Errorf("unexpected distance %v", d)
+	}
+	if d := (Distance{1, NauticalMiles}).In(Feet); !near(d.Value, 6076.115, 1e-3) {
+		t.Errorf("unexpected distance %v", d)
+	}
+	if p := (Precipitation{25.4, Millimeters}).In(Inches); !near(p.Value, 1, 1e-9) {
+		t.Errorf("unexpected precipitation %v", p)
+	}
+	if p := (Precipitation{1, Centimeters}).In(Millimeters); !near(p.Value, 10, 1e-9) {
+		t.Errorf("unexpected precipitation %v", p)
+	}
+	if s := (Distance{3, Kilometers}).String(); s != "3 km" {
+		t.Errorf("unexpected string %q", s)
+	}
+}
+
+// TestSystem will verify the systems of the API's units parameter
+func TestSystem(t *testing.T) {
+	t.Parallel()
+
+	if SystemFor("metric") != Metric || SystemFor("imperial") != Imperial || SystemFor("internal") != Standard {
+		t.Error("unexpected system")
+	}
+	for _, s := range []System{Metric, Imperial, Standard} {
+		if err := s.Validate(); err != nil {
+			t.Error(err)
+		}
+	}
+	if err := (System{Celsius, "furlongs/fortnight", Hectopascal, Meters, Millimeters}).Validate(); err == nil {
+		t.Error("expected an error for an unknown unit")
+	}
+}
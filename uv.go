@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"net/http"
 	"time"
 )
 
@@ -26,7 +25,7 @@ type UV struct {
 	} `json:"data,omitempty"`*/
 	DT    int64   `json:"dt,omitempty"`
 	Value float64 `json:"value,omitempty"`
-	Key   string
+	Key   string  `json:"-"`
 	*Settings
 }
 
@@ -56,8 +55,8 @@ func (u *UV) Current(coord *Coordinates) error {
 
 	defer response.Body.Close()
 
-	if response.StatusCode == http.StatusUnauthorized {
-		return errInvalidKey
+	if err := checkResponse(response); err != nil {
+		return err
 	}
 
 	if err = json.NewDecoder(response.Body).Decode(&u); err != nil {
@@ -76,8 +75,8 @@ func (u *UV) Historical(coord *Coordinates, start, end time.Time) error {
 
 	defer response.Body.Close()
 
-	if response.StatusCode == http.StatusUnauthorized {
-		return errInvalidKey
+	if err := checkResponse(response); err != nil {
+		return err
 	}
 
 	if err = json.NewDecoder(response.Body).Decode(&u); err != nil {
@@ -2,7 +2,6 @@ package openweathermap
 
 import (
 	"net/http"
-	"os"
 	"reflect"
 	"testing"
 	"time"
@@ -16,7 +15,7 @@ var coords = &Coordinates{
 // TestNewUV
 func TestNewUV(t *testing.T) {
 
-	uv, err := NewUV(os.Getenv("OWM_API_KEY"))
+	uv, err := NewUV("key")
 	if err != nil {
 		t.Error(err)
 	}
@@ -31,7 +30,7 @@ func TestNewUVWithCustomHttpClient(t *testing.T) {
 
 	hc := http.DefaultClient
 	hc.Timeout = time.Duration(1) * time.Second
-	uv, err := NewUV(os.Getenv("OWM_API_KEY"), WithHttpClient(hc))
+	uv, err := NewUV("key", WithHttpClient(hc))
 	if err != nil {
 		t.Error(err)
 	}
@@ -58,7 +57,7 @@ func TestNewUVWithInvalidOptions(t *testing.T) {
 	}
 
 	for _, options := range optionsPattern {
-		c, err := NewUV(os.Getenv("OWM_API_KEY"), options...)
+		c, err := NewUV("key", options...)
 		if err == errInvalidOption {
 			t.Logf("Received expected invalid option error. message: %s", err.Error())
 		} else if err != nil {
@@ -74,7 +73,7 @@ func TestNewUVWithInvalidOptions(t *testing.T) {
 // invalid http client
 func TestNewUVWithInvalidHttpClient(t *testing.T) {
 
-	uv, err := NewUV(os.Getenv("OWM_API_KEY"), WithHttpClient(nil))
+	uv, err := NewUV("key", WithHttpClient(nil))
 	if err == errInvalidHttpClient {
 		t.Logf("Received expected bad client error. message: %s", err.Error())
 	} else if err != nil {
@@ -89,14 +88,20 @@ func TestNewUVWithInvalidHttpClient(t *testing.T) {
 func TestCurrentUV(t *testing.T) {
 	t.Parallel()
 
-	uv, err := NewUV(os.Getenv("OWM_API_KEY"))
+	srv, opt := newFakeServer()
+	defer srv.Close()
+
+	uv, err := NewUV("key", opt)
 	if err != nil {
-		t.Error(err)
+		t.Fatal(err)
 	}
 
 	if err := uv.Current(coords); err != nil {
 		t.Error(err)
 	}
+	if uv.Value <= 0 {
+		t.Errorf("expected a UV index, got %f", uv.Value)
+	}
 
 	if reflect.TypeOf(uv).String() != "*openweathermap.UV" {
 		t.Error("incorrect data type returned")
@@ -107,7 +112,7 @@ func TestCurrentUV(t *testing.T) {
 func TestHistoricalUV(t *testing.T) {
 	t.Parallel()
 
-	/*	uv := NewUV(os.Getenv("OWM_API_KEY"))
+	/*	uv := NewUV("key")
 
 		end := time.Now().UTC()
 		start := time.Now().UTC().Add(-time.Hour * time.Duration(24))
@@ -124,9 +129,12 @@ func TestHistoricalUV(t *testing.T) {
 func TestUVInformation(t *testing.T) {
 	t.Parallel()
 
-	uv, err := NewUV(os.Getenv("OWM_API_KEY"))
+	srv, opt := newFakeServer()
+	defer srv.Close()
+
+	uv, err := NewUV("key", opt)
 	if err != nil {
-		t.Error(err)
+		t.Fatal(err)
 	}
 
 	if err := uv.Current(coords); err != nil {
@@ -0,0 +1,237 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Location selects the place of a current weather request.
+type Location struct {
+	query url.Values
+}
+
+// String returns the query selecting the location.
+func (l Location) String() string { return l.query.Encode() }
+
+// LocationID selects a location by its city ID.
+func LocationID(id int) Location {
+	return Location{query: url.Values{"id": {strconv.Itoa(id)}}}
+}
+
+// LocationName selects a location by its name, e.g. "Berlin,DE".
+func LocationName(name string) Location {
+	return Location{query: url.Values{"q": {name}}}
+}
+
+// LocationCoordinates selects a location by its coordinates.
+func LocationCoordinates(c Coordinates) Location {
+	return Location{query: url.Values{
+		"lat": {strconv.FormatFloat(c.Latitude, 'f', -1, 64)},
+		"lon": {strconv.FormatFloat(c.Longitude, 'f', -1, 64)},
+	}}
+}
+
+// LocationZipcode selects a location by its zip code and country code.
+func LocationZipcode(zip, countryCode string) Location {
+	return Location{query: url.Values{"zip": {zip + "," + countryCode}}}
+}
+
+// FieldChange is a field that changed between two observations. Field is
+// the path of the JSON names, e.g. "main.temp".
+type FieldChange struct {
+	Field string
+	Old   interface{}
+	New   interface{}
+}
+
+// WeatherUpdate is sent by Watch for every new observation, or with Err
+// set when a poll fails.
+type WeatherUpdate struct {
+	Location Location
+	Current  *CurrentWeatherData
+	Previous *CurrentWeatherData // nil for the first observation
+	Changes  []FieldChange       // changes since the previous observation
+	Err      error
+}
+
+// watchMaxBackoff caps the backoff after failed polls, in intervals.
+const watchMaxBackoff = 8
+
+// current fetches the current weather of the location into a new
+// CurrentWeatherData sharing the settings of the receiver.
+func (w *CurrentWeatherData) current(location Location) (*CurrentWeatherData, error) {
+	v := url.Values{}
+	for k, vs := range location.query {
+		v[k] = vs
+	}
+	v.Set("appid", w.Key)
+	v.Set("units", w.requestUnit(w.Unit))
+	v.Set("lang", w.Lang)
+
+	response, err := w.client.Get(fmt.Sprintf(baseURL, v.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if err := checkResponse(response); err != nil {
+		return nil, err
+	}
+
+	c := &CurrentWeatherData{Unit: w.Unit, Lang: w.Lang, Key: w.Key, Settings: w.Settings}
+	if err := json.NewDecoder(response.Body).Decode(c); err != nil {
+		return nil, err
+	}
+	w.applyUnitPreferences(c)
+
+	return c, nil
+}
+
+// jitter spreads d by up to a tenth either way so that many watches
+// don't poll at once.
+func jitter(d time.Duration) time.Duration {
+	spread := int64(d / 10)
+	if spread <= 0 {
+		return d
+	}
+	return d - time.Duration(spread) + time.Duration(rand.Int63n(2*spread))
+}
+
+// Watch polls the current weather of the location on the interval and
+// sends an update whenever the observation time changes. Polls are
+// jittered, wait for the rate limiter set with WithRateLimiter and back
+// off after errors, which are sent as updates with Err set. The channel
+// is closed once the context is done.
+func (w *CurrentWeatherData) Watch(ctx context.Context, location Location, interval time.Duration) (<-chan WeatherUpdate, error) {
+	if interval <= 0 {
+		return nil, errInvalidInterval
+	}
+
+	ch := make(chan WeatherUpdate)
+	go func() {
+		defer close(ch)
+
+		send := func(u WeatherUpdate) bool {
+			select {
+			case ch <- u:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		var previous *CurrentWeatherData
+		failures := 0
+		delay := time.Duration(rand.Int63n(int64(interval/10) + 1))
+		for {
+			t := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return
+			case <-t.C:
+			}
+
+			if err := w.wait(ctx); err != nil {
+				return
+			}
+
+			c, err := w.current(location)
+			if err != nil {
+				if !send(WeatherUpdate{Location: location, Previous: previous, Err: err}) {
+					return
+				}
+				if failures < watchMaxBackoff {
+					failures++
+				}
+				backoff := interval << uint(failures)
+				if max := interval * watchMaxBackoff; backoff > max {
+					backoff = max
+				}
+				delay = jitter(backoff)
+				continue
+			}
+			failures = 0
+			delay = jitter(interval)
+
+			if previous != nil && c.Dt == previous.Dt {
+				continue
+			}
+			u := WeatherUpdate{Location: location, Current: c, Previous: previous}
+			if previous != nil {
+				u.Changes = previous.Diff(c)
+			}
+			if !send(u) {
+				return
+			}
+			previous = c
+		}
+	}()
+	return ch, nil
+}
+
+// Diff returns the fields that differ between the observations. Only
+// the fields decoded from the API are compared.
+func (w *CurrentWeatherData) Diff(other *CurrentWeatherData) []FieldChange {
+	return diffFields("", reflect.ValueOf(*w), reflect.ValueOf(*other))
+}
+
+// diffFields compares the fields with a JSON name of two structs, going
+// into nested structs and slices of the same length.
+func diffFields(prefix string, a, b reflect.Value) []FieldChange {
+	var changes []FieldChange
+	switch a.Kind() {
+	case reflect.Struct:
+		t := a.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name := strings.Split(f.Tag.Get("json"), ",")[0]
+			if f.PkgPath != "" || name == "" || name == "-" {
+				continue
+			}
+			changes = append(changes, diffFields(joinPath(prefix, name), a.Field(i), b.Field(i))...)
+		}
+		return changes
+	case reflect.Slice:
+		if a.Len() == b.Len() {
+			for i := 0; i < a.Len(); i++ {
+				changes = append(changes, diffFields(joinPath(prefix, strconv.Itoa(i)), a.Index(i), b.Index(i))...)
+			}
+			return changes
+		}
+	}
+
+	if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+		changes = append(changes, FieldChange{Field: prefix, Old: a.Interface(), New: b.Interface()})
+	}
+	return changes
+}
+
+// joinPath appends the name to the path of a field.
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
@@ -0,0 +1,142 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// observations serves the current weather bodies in turn, repeating the
+// last one.
+type observations struct {
+	mu     sync.Mutex
+	bodies []string
+	polls  int
+}
+
+func (o *observations) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	i := o.polls
+	if i >= len(o.bodies) {
+		i = len(o.bodies) - 1
+	}
+	o.polls++
+	if o.bodies[i] == "" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprint(w, o.bodies[i])
+}
+
+// TestWatch will verify that only new observations are sent, along with
+// the fields that changed
+func TestWatch(t *testing.T) {
+	t.Parallel()
+
+	o := &observations{bodies: []string{
+		`{"dt": 100, "id": 2950159, "main": {"temp": 10, "humidity": 80}, "weather": [{"id": 800}]}`,
+		`{"dt": 100, "id": 2950159, "main": {"temp": 10, "humidity": 80}, "weather": [{"id": 800}]}`,
+		``,
+		`{"dt": 200, "id": 2950159, "main": {"temp": 11.5, "humidity": 80}, "weather": [{"id": 801}]}`,
+	}}
+	srv, opt := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := r.URL.Query().Get("id"); id != "2950159" {
+			t.Errorf("expected the city ID, got %q", id)
+		}
+		o.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	w, err := NewCurrent("C", "EN", "key", opt, WithRateLimiter(NewTokenBucket(100, time.Second)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := w.Watch(ctx, LocationID(2950159), time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := <-ch
+	if first.Err != nil || first.Current.Dt != 100 || first.Previous != nil || first.Changes != nil {
+		t.Fatalf("unexpected first update %+v", first)
+	}
+
+	failed := <-ch
+	if _, ok := failed.Err.(*APIError); !ok {
+		t.Fatalf("expected an API error, got %+v", failed)
+	}
+
+	second := <-ch
+	if second.Err != nil || second.Current.Dt != 200 || second.Previous.Dt != 100 {
+		t.Fatalf("unexpected second update %+v", second)
+	}
+	want := map[string][2]interface{}{
+		"weather.0.id": {800, 801},
+		"main.temp":    {10.0, 11.5},
+		"dt":           {100, 200},
+	}
+	if len(second.Changes) != len(want) {
+		t.Errorf("expected %d changes, got %+v", len(want), second.Changes)
+	}
+	for _, c := range second.Changes {
+		if w, ok := want[c.Field]; !ok || c.Old != w[0] || c.New != w[1] {
+			t.Errorf("unexpected change %+v", c)
+		}
+	}
+
+	cancel()
+	for range ch {
+	}
+	if o.polls < 4 {
+		t.Errorf("expected at least 4 polls, got %d", o.polls)
+	}
+}
+
+// TestWatchInvalid will verify that the interval has to be positive
+func TestWatchInvalid(t *testing.T) {
+	t.Parallel()
+
+	w, err := NewCurrent("C", "EN", "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Watch(context.Background(), LocationName("Berlin,DE"), 0); err != errInvalidInterval {
+		t.Errorf("expected %v, got %v", errInvalidInterval, err)
+	}
+}
+
+// TestJitter will verify that jitter stays within a tenth of the duration
+func TestJitter(t *testing.T) {
+	t.Parallel()
+
+	for i := 0; i < 100; i++ {
+		if d := jitter(time.Minute); d < 54*time.Second || d > 66*time.Second {
+			t.Fatalf("expected 54s to 66s, got %v", d)
+		}
+	}
+	if d := jitter(5); d != 5 {
+		t.Errorf("expected tiny durations unchanged, got %v", d)
+	}
+}
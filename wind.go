@@ -0,0 +1,194 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"math"
+
+	"github.com/briandowns/openweathermap/units"
+)
+
+// cardinal16 holds the names of the 16 compass points, starting north
+// and going clockwise.
+var cardinal16 = []string{
+	"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE",
+	"S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW",
+}
+
+// cardinal32 holds the names of the 32 compass points, starting north
+// and going clockwise. "b" stands for "by", e.g. NbE is north by east.
+var cardinal32 = []string{
+	"N", "NbE", "NNE", "NEbN", "NE", "NEbE", "ENE", "EbN",
+	"E", "EbS", "ESE", "SEbE", "SE", "SEbS", "SSE", "SbE",
+	"S", "SbW", "SSW", "SWbS", "SW", "SWbW", "WSW", "WbS",
+	"W", "WbN", "WNW", "NWbW", "NW", "NWbN", "NNW", "NbW",
+}
+
+// compassPoint returns the name of the nearest of the given points.
+func compassPoint(deg float64, points []string) string {
+	deg = math.Mod(deg, 360)
+	if deg < 0 {
+		deg += 360
+	}
+	step := 360 / float64(len(points))
+	return points[int(math.Floor(deg/step+0.5))%len(points)]
+}
+
+// Cardinal16 returns the 16 point compass direction the wind blows
+// from, e.g. "NNE".
+func (w Wind) Cardinal16() string { return compassPoint(w.Deg, cardinal16) }
+
+// Cardinal32 returns the 32 point compass direction the wind blows
+// from, e.g. "NbE".
+func (w Wind) Cardinal32() string { return compassPoint(w.Deg, cardinal32) }
+
+// BeaufortForce is a wind force on the Beaufort scale, from 0 to 12.
+type BeaufortForce int
+
+// beaufortLimits holds the upper speed bound, in m/s, of forces 0 to 11.
+var beaufortLimits = []float64{0.5, 1.6, 3.4, 5.5, 8.0, 10.8, 13.9, 17.2, 20.8, 24.5, 28.5, 32.7}
+
+// beaufortDescriptions holds the description of each force. They match
+// the AdditionalConditions meanings, which have no entry for light air.
+var beaufortDescriptions = []string{
+	"calm",
+	"light air",
+	"light breeze",
+	"gentle breeze",
+	"moderate breeze",
+	"fresh breeze",
+	"strong breeze",
+	"high wind, near gale",
+	"gale",
+	"severe gale",
+	"storm",
+	"violent storm",
+	"hurricane",
+}
+
+// Beaufort returns the force of the given wind speed.
+func Beaufort(speed units.Speed) BeaufortForce {
+	v := speed.In(units.MetersPerSecond).Value
+	for i, limit := range beaufortLimits {
+		if v < limit {
+			return BeaufortForce(i)
+		}
+	}
+	return BeaufortForce(len(beaufortLimits))
+}
+
+// String returns the description of the force, e.g. "gentle breeze".
+func (b BeaufortForce) String() string {
+	if b < 0 || int(b) >= len(beaufortDescriptions) {
+		return "unknown"
+	}
+	return beaufortDescriptions[b]
+}
+
+// Condition returns the condition of the AdditionalConditions matching
+// the force. The bool is false for light air, which has no condition.
+func (b BeaufortForce) Condition() (ConditionData, bool) {
+	switch {
+	case b == 0:
+		return LookupCondition(951)
+	case b >= 2 && b <= 12:
+		return LookupCondition(950 + int(b))
+	}
+	return ConditionData{}, false
+}
+
+// Beaufort returns the force of the wind, whose speed is in the units of
+// the given system.
+func (w Wind) Beaufort(s units.System) BeaufortForce {
+	return Beaufort(units.Speed{Value: w.Speed, Unit: s.Speed})
+}
+
+// Components returns the eastward (u) and northward (v) components of
+// the wind, in the unit of its speed. As Deg is the direction the wind
+// blows from, a north wind has a negative v.
+func (w Wind) Components() (float64, float64) {
+	rad := w.Deg * math.Pi / 180
+	return -w.Speed * math.Sin(rad), -w.Speed * math.Cos(rad)
+}
+
+// calmSpeed is the speed under which a wind has no direction, so that
+// rounding errors don't give one.
+const calmSpeed = 1e-9
+
+// WindFromComponents returns the wind of the given eastward (u) and
+// northward (v) components. Calm wind is returned with a speed and
+// direction of 0.
+func WindFromComponents(u, v float64) Wind {
+	if math.Hypot(u, v) < calmSpeed {
+		return Wind{}
+	}
+	deg := math.Atan2(-u, -v) * 180 / math.Pi
+	if deg < 0 {
+		deg += 360
+	}
+	return Wind{Speed: math.Hypot(u, v), Deg: deg}
+}
+
+// AverageWind returns the vector average of the winds: their components
+// are averaged so that, e.g., 350° and 10° give 0° rather than 180°.
+// Speed is the speed of the mean vector, which is lower than the mean
+// speed when the direction varies, and Gust is the highest gust.
+func AverageWind(winds []Wind) Wind {
+	if len(winds) == 0 {
+		return Wind{}
+	}
+
+	var u, v, gust float64
+	for _, w := range winds {
+		wu, wv := w.Components()
+		u += wu
+		v += wv
+		gust = math.Max(gust, w.Gust)
+	}
+	n := float64(len(winds))
+
+	avg := WindFromComponents(u/n, v/n)
+	avg.Gust = gust
+	return avg
+}
+
+// Wind returns the current wind.
+func (c OneCallCurrentData) Wind() Wind {
+	return Wind{Speed: c.WindSpeed, Deg: c.WindDeg, Gust: c.WindGust}
+}
+
+// Wind returns the wind of the hour.
+func (h OneCallHourlyData) Wind() Wind {
+	return Wind{Speed: h.WindSpeed, Deg: h.WindDeg, Gust: h.WindGust}
+}
+
+// AverageWind returns the vector average of the wind over the forecast.
+func (f *Forecast5WeatherData) AverageWind() Wind {
+	winds := make([]Wind, len(f.List))
+	for i := range f.List {
+		winds[i] = f.List[i].Wind
+	}
+	return AverageWind(winds)
+}
+
+// AverageHourlyWind returns the vector average of the wind over the
+// hourly forecast.
+func (w *OneCallData) AverageHourlyWind() Wind {
+	winds := make([]Wind, len(w.Hourly))
+	for i := range w.Hourly {
+		winds[i] = w.Hourly[i].Wind()
+	}
+	return AverageWind(winds)
+}
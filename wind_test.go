@@ -0,0 +1,169 @@
+// Copyright 2022 Brian J. Downs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openweathermap
+
+import (
+	"math"
+	"testing"
+
+	"github.com/briandowns/openweathermap/units"
+)
+
+// angleDiff returns the smallest difference between two angles.
+func angleDiff(a, b float64) float64 {
+	d := math.Mod(math.Abs(a-b), 360)
+	return math.Min(d, 360-d)
+}
+
+// TestCardinal will verify the compass points of wind directions
+func TestCardinal(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		deg    float64
+		want16 string
+		want32 string
+	}{
+		{0, "N", "N"},
+		{11, "N", "NbE"},
+		{22.5, "NNE", "NNE"},
+		{90, "E", "E"},
+		{191, "S", "SbW"},
+		{350, "N", "NbW"},
+		{359, "N", "N"},
+		{360, "N", "N"},
+		{-90, "W", "W"},
+		{247.5, "WSW", "WSW"},
+	}
+	for _, tt := range tests {
+		w := Wind{Deg: tt.deg}
+		if got := w.Cardinal16(); got != tt.want16 {
+			t.Errorf("%v°: expected %s, got %s", tt.deg, tt.want16, got)
+		}
+		if got := w.Cardinal32(); got != tt.want32 {
+			t.Errorf("%v°: expected %s, got %s", tt.deg, tt.want32, got)
+		}
+	}
+}
+
+// TestBeaufort will verify the Beaufort force of wind speeds and their
+// matching conditions
+func TestBeaufort(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		speed units.Speed
+		want  BeaufortForce
+		desc  string
+		id    int
+	}{
+		{units.Speed{Value: 0.2, Unit: units.MetersPerSecond}, 0, "calm", 951},
+		{units.Speed{Value: 1, Unit: units.MetersPerSecond}, 1, "light air", 0},
+		{units.Speed{Value: 10, Unit: units.Knots}, 3, "gentle breeze", 953},
+		{units.Speed{Value: 40, Unit: units.KilometersPerHour}, 6, "strong breeze", 956},
+		{units.Speed{Value: 17.2, Unit: units.MetersPerSecond}, 8, "gale", 958},
+		{units.Speed{Value: 100, Unit: units.MilesPerHour}, 12, "hurricane", 962},
+	}
+	for _, tt := range tests {
+		got := Beaufort(tt.speed)
+		if got != tt.want || got.String() != tt.desc {
+			t.Errorf("%v: expected force %d (%s), got %d (%s)", tt.speed, tt.want, tt.desc, got, got)
+		}
+		c, ok := got.Condition()
+		if ok != (tt.id != 0) || c.ID != tt.id {
+			t.Errorf("force %d: expected condition %d, got %d", got, tt.id, c.ID)
+		}
+		if ok && c.Meaning != tt.desc {
+			t.Errorf("force %d: expected %q to match the condition, got %q", got, tt.desc, c.Meaning)
+		}
+	}
+
+	if f := (Wind{Speed: 25}).Beaufort(units.Imperial); f != 6 {
+		t.Errorf("expected 25 mph to be force 6, got %d", f)
+	}
+}
+
+// TestComponents will verify the conversion of wind into components and
+// back
+func TestComponents(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		w    Wind
+		u, v float64
+	}{
+		{Wind{Speed: 10, Deg: 0}, 0, -10},
+		{Wind{Speed: 10, Deg: 90}, -10, 0},
+		{Wind{Speed: 5, Deg: 180}, 0, 5},
+		{Wind{Speed: 5, Deg: 270}, 5, 0},
+	}
+	for _, tt := range tests {
+		u, v := tt.w.Components()
+		if math.Abs(u-tt.u) > 1e-9 || math.Abs(v-tt.v) > 1e-9 {
+			t.Errorf("%+v: expected (%v, %v), got (%v, %v)", tt.w, tt.u, tt.v, u, v)
+		}
+		back := WindFromComponents(u, v)
+		if math.Abs(back.Speed-tt.w.Speed) > 1e-9 || angleDiff(back.Deg, tt.w.Deg) > 1e-9 {
+			t.Errorf("%+v: expected round trip, got %+v", tt.w, back)
+		}
+	}
+}
+
+// TestCalmWind will verify that calm wind has no direction
+func TestCalmWind(t *testing.T) {
+	t.Parallel()
+
+	for _, c := range [][2]float64{{0, 0}, {math.Copysign(0, -1), math.Copysign(0, -1)}, {1e-12, -1e-12}} {
+		if w := WindFromComponents(c[0], c[1]); w != (Wind{}) {
+			t.Errorf("%v: expected calm wind, got %+v", c, w)
+		}
+	}
+	if u, v := (Wind{}).Components(); WindFromComponents(u, v) != (Wind{}) {
+		t.Error("expected calm wind to round trip")
+	}
+}
+
+// TestAverageWind will verify that winds are averaged as vectors
+func TestAverageWind(t *testing.T) {
+	t.Parallel()
+
+	avg := AverageWind([]Wind{{Speed: 10, Deg: 350}, {Speed: 10, Deg: 10, Gust: 15}})
+	if angleDiff(avg.Deg, 0) > 1e-9 || math.Abs(avg.Speed-10*math.Cos(10*math.Pi/180)) > 1e-9 || avg.Gust != 15 {
+		t.Errorf("unexpected average %+v", avg)
+	}
+
+	if avg := AverageWind([]Wind{{Speed: 5, Deg: 90}, {Speed: 5, Deg: 270}}); avg.Speed != 0 || avg.Deg != 0 {
+		t.Errorf("expected opposite winds to cancel out, got %+v", avg)
+	}
+	if avg := AverageWind(nil); avg != (Wind{}) {
+		t.Errorf("expected no wind, got %+v", avg)
+	}
+
+	f := &Forecast5WeatherData{List: []Forecast5WeatherList{
+		{Wind: Wind{Speed: 4, Deg: 315}},
+		{Wind: Wind{Speed: 4, Deg: 45}},
+	}}
+	if avg := f.AverageWind(); angleDiff(avg.Deg, 0) > 1e-9 || avg.Cardinal16() != "N" {
+		t.Errorf("unexpected forecast average %+v", avg)
+	}
+
+	o := &OneCallData{Hourly: []OneCallHourlyData{
+		{WindSpeed: 3, WindDeg: 170, WindGust: 6},
+		{WindSpeed: 3, WindDeg: 190, WindGust: 8},
+	}}
+	if avg := o.AverageHourlyWind(); angleDiff(avg.Deg, 180) > 1e-9 || avg.Gust != 8 {
+		t.Errorf("unexpected hourly average %+v", avg)
+	}
+}